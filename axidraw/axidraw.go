@@ -0,0 +1,344 @@
+package axidraw
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/motion"
+	"github.com/libeks/go-plotter-svg/primitives"
+	"github.com/libeks/go-plotter-svg/scenes"
+	"github.com/libeks/go-plotter-svg/units"
+)
+
+const (
+	// the AxiDraw has 2032 steps per inch at 1/16 microstepping
+	defaultStepsPerMM = 2032 / 25.4
+	// the standard AxiDraw servo range, in units of 83.3ns, as used by the Inkscape extension
+	servoMin = 9855
+	servoMax = 27831
+	// the EBB can't step its motors faster than this, in steps per second
+	maxStepRate = 25000
+	// SM moves are planned in slices of this many milliseconds, to approximate acceleration
+	timeSlice = 30
+)
+
+type Config struct {
+	PenUpPosition   float64       // servo position for a raised pen, in percent of the servo range
+	PenDownPosition float64       // servo position for a lowered pen, in percent of the servo range
+	PenUpDelay      time.Duration // time for the servo to raise the pen
+	PenDownDelay    time.Duration // time for the servo to lower the pen
+
+	Drawing motion.Limits // motion limits while the pen is down
+	Travel  motion.Limits // motion limits while the pen is up
+
+	LayerSpeeds        map[string]float64 // drawing speed in mm/s for layers by name, overrides Drawing.Speed
+	PauseBetweenLayers bool               // pause before every layer, not only when the pen has to be swapped
+
+	// WaitForUser is called whenever the plot is paused, e.g. to swap pens. It should return once plotting
+	// can resume. If nil, the driver waits for the button on the EBB to be pressed.
+	WaitForUser func(message string) error
+
+	Tolerance  float64 // maximum deviation in mm when flattening curves into line segments
+	UnitsPerMM float64 // how many internal units fit in a millimeter
+	StepsPerMM float64 // motor steps per mm of carriage movement
+}
+
+func DefaultConfig() Config {
+	model := motion.DefaultModel()
+	return Config{
+		PenUpPosition:   60,
+		PenDownPosition: 30,
+		PenUpDelay:      model.PenUpDelay,
+		PenDownDelay:    model.PenDownDelay,
+		Drawing:         model.Drawing,
+		Travel:          model.Travel,
+		Tolerance:       model.Tolerance,
+		UnitsPerMM:      units.Millimeter,
+		StepsPerMM:      defaultStepsPerMM,
+	}
+}
+
+// Driver streams a document to an AxiDraw over the EiBotBoard serial protocol. The carriage must be in its
+// home position, the upper left corner of the page, when plotting starts.
+type Driver struct {
+	Config
+	port   io.ReadWriter
+	reader *bufio.Reader
+	// position of the carriage in steps along the x and y axes
+	x, y  int
+	penUp bool
+}
+
+func NewDriver(port io.ReadWriter, config Config) *Driver {
+	return &Driver{
+		Config: config,
+		port:   port,
+		reader: bufio.NewReader(port),
+	}
+}
+
+// Command sends a single command to the EBB, and returns the response that preceded the final "OK"
+func (d *Driver) Command(command string) (string, error) {
+	if _, err := d.port.Write([]byte(command + "\r")); err != nil {
+		return "", fmt.Errorf("could not send command %s: %w", command, err)
+	}
+	response := []string{}
+	for {
+		line, err := d.reader.ReadString('\n')
+		if err != nil {
+			return "", fmt.Errorf("no response to command %s: %w", command, err)
+		}
+		line = strings.TrimSpace(line)
+		if line == "OK" {
+			return strings.Join(response, "\n"), nil
+		}
+		if strings.HasPrefix(line, "!") {
+			return "", fmt.Errorf("command %s failed: %s", command, line)
+		}
+		response = append(response, line)
+	}
+}
+
+func servoPosition(percent float64) int {
+	return int(servoMin + (servoMax-servoMin)*percent/100)
+}
+
+// Plot draws every page of the document, pausing between pages to let the paper be replaced
+func (d *Driver) Plot(doc scenes.Document) error {
+	if err := d.setup(); err != nil {
+		return err
+	}
+	for i := range doc.NumPages() {
+		if i > 0 {
+			if err := d.pause(fmt.Sprintf("load paper for page %d", i)); err != nil {
+				return err
+			}
+		}
+		if err := d.plotPage(doc.Page(i)); err != nil {
+			return err
+		}
+	}
+	return d.finish()
+}
+
+// PlotPage draws a single page
+func (d *Driver) PlotPage(page scenes.Page) error {
+	if err := d.setup(); err != nil {
+		return err
+	}
+	if err := d.plotPage(page); err != nil {
+		return err
+	}
+	return d.finish()
+}
+
+func (d *Driver) setup() error {
+	for _, command := range []string{
+		"EM,1,1", // enable both motors at 1/16 microstepping
+		fmt.Sprintf("SC,4,%d", servoPosition(d.PenUpPosition)),
+		fmt.Sprintf("SC,5,%d", servoPosition(d.PenDownPosition)),
+		"QB", // clear any earlier button presses
+	} {
+		if _, err := d.Command(command); err != nil {
+			return err
+		}
+	}
+	d.penUp = false // the pen state is unknown at the start, so make sure it is up
+	return d.raisePen()
+}
+
+func (d *Driver) finish() error {
+	if err := d.raisePen(); err != nil {
+		return err
+	}
+	if err := d.travelTo(primitives.Origin); err != nil {
+		return err
+	}
+	_, err := d.Command("EM,0,0")
+	return err
+}
+
+func (d *Driver) plotPage(page scenes.Page) error {
+	var previous *scenes.Layer
+	for _, layer := range page.GetLayers() {
+		if len(layer.LineLikes()) == 0 {
+			continue
+		}
+		if previous != nil {
+			if needsPenSwap(*previous, layer) {
+				if err := d.pause(fmt.Sprintf("swap the pen for layer '%s' (%s)", layer.Name(), penDescription(layer))); err != nil {
+					return err
+				}
+			} else if d.PauseBetweenLayers {
+				if err := d.pause(fmt.Sprintf("about to draw layer '%s'", layer.Name())); err != nil {
+					return err
+				}
+			}
+		}
+		if err := d.plotLayer(layer); err != nil {
+			return fmt.Errorf("layer '%s': %w", layer.Name(), err)
+		}
+		previous = &layer
+	}
+	return nil
+}
+
+// needsPenSwap reports whether two layers are drawn with different pens or colors
+func needsPenSwap(a, b scenes.Layer) bool {
+	return a.Pen().Name != b.Pen().Name || layerColor(a) != layerColor(b)
+}
+
+func layerColor(layer scenes.Layer) string {
+	if layer.Color() == "" {
+		return "black"
+	}
+	return layer.Color()
+}
+
+func penDescription(layer scenes.Layer) string {
+	if layer.Pen().Name != "" {
+		return fmt.Sprintf("%s %s", layerColor(layer), layer.Pen().Name)
+	}
+	return layerColor(layer)
+}
+
+// pause raises the pen, moves the carriage out of the way and waits for the user
+func (d *Driver) pause(message string) error {
+	if err := d.raisePen(); err != nil {
+		return err
+	}
+	if err := d.travelTo(primitives.Origin); err != nil {
+		return err
+	}
+	fmt.Printf("Plot paused: %s\n", message)
+	if d.WaitForUser != nil {
+		return d.WaitForUser(message)
+	}
+	fmt.Printf("Press the button on the AxiDraw to continue\n")
+	for {
+		response, err := d.Command("QB")
+		if err != nil {
+			return err
+		}
+		if response == "1" {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func (d *Driver) plotLayer(layer scenes.Layer) error {
+	limits := d.Drawing
+	if speed, ok := d.LayerSpeeds[layer.Name()]; ok {
+		limits.Speed = speed
+	}
+	offset := layer.Offset()
+	tolerance := d.Tolerance * d.UnitsPerMM
+	for _, linelike := range layer.LineLikes() {
+		if linelike == nil || linelike.IsEmpty() {
+			continue
+		}
+		points := lines.Flatten(linelike.Translate(offset), tolerance)
+		for i, pt := range points {
+			points[i] = primitives.Point{X: pt.X / d.UnitsPerMM, Y: pt.Y / d.UnitsPerMM}
+		}
+		if points[0].Subtract(d.position()).Len() > d.Tolerance {
+			if err := d.raisePen(); err != nil {
+				return err
+			}
+			if err := d.travelTo(points[0]); err != nil {
+				return err
+			}
+		}
+		if err := d.lowerPen(); err != nil {
+			return err
+		}
+		if err := d.move(append([]primitives.Point{d.position()}, points[1:]...), limits); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// position is the current position of the carriage, in mm
+func (d *Driver) position() primitives.Point {
+	return primitives.Point{X: float64(d.x) / d.StepsPerMM, Y: float64(d.y) / d.StepsPerMM}
+}
+
+func (d *Driver) raisePen() error {
+	if d.penUp {
+		return nil
+	}
+	if _, err := d.Command(fmt.Sprintf("SP,1,%d", d.PenUpDelay.Milliseconds())); err != nil {
+		return err
+	}
+	d.penUp = true
+	return nil
+}
+
+func (d *Driver) lowerPen() error {
+	if !d.penUp {
+		return nil
+	}
+	if _, err := d.Command(fmt.Sprintf("SP,0,%d", d.PenDownDelay.Milliseconds())); err != nil {
+		return err
+	}
+	d.penUp = false
+	return nil
+}
+
+func (d *Driver) travelTo(pt primitives.Point) error {
+	if !d.penUp {
+		return errors.New("can't travel with the pen down")
+	}
+	return d.move([]primitives.Point{d.position(), pt}, d.Travel)
+}
+
+// move plans the acceleration along the polyline, and sends it to the EBB as a series of constant-speed moves
+func (d *Driver) move(points []primitives.Point, limits motion.Limits) error {
+	for _, segment := range motion.Plan(points, limits) {
+		duration := segment.Duration()
+		slices := max(int(math.Ceil(duration*1000/timeSlice)), 1)
+		for i := 1; i <= slices; i++ {
+			t := duration * float64(i) / float64(slices)
+			ms := int(math.Round(duration*1000*float64(i)/float64(slices))) - int(math.Round(duration*1000*float64(i-1)/float64(slices)))
+			if err := d.stepTo(segment.PointAt(t), ms); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// stepTo moves the carriage to pt in a straight line at constant speed, taking at least ms milliseconds
+func (d *Driver) stepTo(pt primitives.Point, ms int) error {
+	x := int(math.Round(pt.X * d.StepsPerMM))
+	y := int(math.Round(pt.Y * d.StepsPerMM))
+	dx, dy := x-d.x, y-d.y
+	if dx == 0 && dy == 0 {
+		return nil
+	}
+	// the AxiDraw uses mixed-axis geometry, where both motors contribute to moves along either axis
+	motor1 := dx + dy
+	motor2 := dx - dy
+	fastest := max(abs(motor1), abs(motor2))
+	ms = max(ms, int(math.Ceil(float64(fastest)*1000/maxStepRate)), 1)
+	if _, err := d.Command(fmt.Sprintf("SM,%d,%d,%d", ms, motor1, motor2)); err != nil {
+		return err
+	}
+	d.x, d.y = x, y
+	return nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
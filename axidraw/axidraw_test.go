@@ -0,0 +1,128 @@
+package axidraw
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/primitives"
+	"github.com/libeks/go-plotter-svg/scenes"
+)
+
+func square(x, y, size float64) lines.LineLike {
+	return lines.NewPath(primitives.Point{X: x, Y: y}).
+		AddPathChunk(lines.LineChunk{Start: primitives.Point{X: x, Y: y}, End: primitives.Point{X: x + size, Y: y}}).
+		AddPathChunk(lines.LineChunk{Start: primitives.Point{X: x + size, Y: y}, End: primitives.Point{X: x + size, Y: y + size}}).
+		AddPathChunk(lines.LineChunk{Start: primitives.Point{X: x + size, Y: y + size}, End: primitives.Point{X: x, Y: y + size}}).
+		AddPathChunk(lines.LineChunk{Start: primitives.Point{X: x, Y: y + size}, End: primitives.Point{X: x, Y: y}})
+}
+
+func TestPlot(t *testing.T) {
+	tests := []struct {
+		name               string
+		layers             []scenes.Layer
+		pauseBetweenLayers bool
+		wantPauses         int
+		wantPenDowns       int
+	}{
+		{
+			name: "single layer",
+			layers: []scenes.Layer{
+				scenes.NewLayer("a").WithLineLike([]lines.LineLike{square(1000, 1000, 1000), square(3000, 1000, 500)}),
+			},
+			wantPauses:   0,
+			wantPenDowns: 2,
+		},
+		{
+			name: "same color doesn't pause",
+			layers: []scenes.Layer{
+				scenes.NewLayer("a").WithLineLike([]lines.LineLike{square(1000, 1000, 1000)}),
+				scenes.NewLayer("b").WithLineLike([]lines.LineLike{square(3000, 1000, 1000)}),
+			},
+			wantPauses:   0,
+			wantPenDowns: 2,
+		},
+		{
+			name: "pause between layers",
+			layers: []scenes.Layer{
+				scenes.NewLayer("a").WithLineLike([]lines.LineLike{square(1000, 1000, 1000)}),
+				scenes.NewLayer("b").WithLineLike([]lines.LineLike{square(3000, 1000, 1000)}),
+			},
+			pauseBetweenLayers: true,
+			wantPauses:         1,
+			wantPenDowns:       2,
+		},
+		{
+			name: "pen swap between colors",
+			layers: []scenes.Layer{
+				scenes.NewLayer("a").WithLineLike([]lines.LineLike{square(1000, 1000, 1000)}).WithColor("red"),
+				scenes.NewLayer("b").WithLineLike([]lines.LineLike{square(3000, 1000, 1000)}).WithColor("blue"),
+				scenes.NewLayer("c").WithLineLike([]lines.LineLike{square(5000, 1000, 1000)}).WithColor("blue"),
+			},
+			wantPauses:   1,
+			wantPenDowns: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := scenes.Document{}
+			for _, layer := range tt.layers {
+				doc = doc.AddLayer(layer)
+			}
+			port := NewSimulatedPort()
+			config := DefaultConfig()
+			config.PauseBetweenLayers = tt.pauseBetweenLayers
+			pauses := 0
+			config.WaitForUser = func(message string) error {
+				pauses += 1
+				return nil
+			}
+			if err := NewDriver(port, config).Plot(doc); err != nil {
+				t.Fatalf("Plot() returned error %v", err)
+			}
+			if pauses != tt.wantPauses {
+				t.Errorf("got %d pauses, want %d", pauses, tt.wantPauses)
+			}
+			penDowns := 0
+			for _, command := range port.Commands {
+				if strings.HasPrefix(command, "SP,0") {
+					penDowns += 1
+				}
+			}
+			if penDowns != tt.wantPenDowns {
+				t.Errorf("got %d pen downs, want %d", penDowns, tt.wantPenDowns)
+			}
+			if port.Motor1 != 0 || port.Motor2 != 0 {
+				t.Errorf("carriage did not return home, motors at %d, %d", port.Motor1, port.Motor2)
+			}
+			if !port.PenUp {
+				t.Errorf("pen was left down")
+			}
+			if last := port.Commands[len(port.Commands)-1]; last != "EM,0,0" {
+				t.Errorf("last command was %s, want the motors to be disabled", last)
+			}
+		})
+	}
+}
+
+func TestMoveRespectsStepRate(t *testing.T) {
+	port := NewSimulatedPort()
+	driver := NewDriver(port, DefaultConfig())
+	driver.penUp = true
+	if err := driver.travelTo(primitives.Point{X: 200, Y: 100}); err != nil {
+		t.Fatalf("travelTo() returned error %v", err)
+	}
+	for _, command := range port.Commands {
+		var ms, m1, m2 int
+		if _, err := fmt.Sscanf(command, "SM,%d,%d,%d", &ms, &m1, &m2); err != nil {
+			t.Fatalf("unexpected command %s", command)
+		}
+		if ms < 1 || abs(m1)*1000 > maxStepRate*ms || abs(m2)*1000 > maxStepRate*ms {
+			t.Errorf("command %s exceeds the step rate", command)
+		}
+	}
+	if x, y := port.Motor1+port.Motor2, port.Motor1-port.Motor2; x != 2*16000 || y != 2*8000 {
+		t.Errorf("carriage ended up at %d, %d steps, want 16000, 8000", x/2, y/2)
+	}
+}
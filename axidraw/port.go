@@ -0,0 +1,106 @@
+package axidraw
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// OpenPort opens the serial device of an EiBotBoard, e.g. /dev/ttyACM0 on Linux or /dev/cu.usbmodem1421
+// on macOS. The EBB is a USB CDC device, so there is no baud rate to configure.
+func OpenPort(name string) (io.ReadWriteCloser, error) {
+	f, err := os.OpenFile(name, os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("could not open serial port %s: %w", name, err)
+	}
+	return f, nil
+}
+
+// SimulatedPort pretends to be an EiBotBoard. It records every command it receives and keeps track of the
+// pen and motor positions, so that plots can be tested without any hardware attached.
+type SimulatedPort struct {
+	Commands []string
+	PenUp    bool
+	Motor1   int // position of the first motor, in steps
+	Motor2   int // position of the second motor, in steps
+	Elapsed  int // total duration of all moves and pen delays, in milliseconds
+
+	mu       sync.Mutex
+	input    bytes.Buffer
+	response bytes.Buffer
+}
+
+func NewSimulatedPort() *SimulatedPort {
+	return &SimulatedPort{PenUp: true}
+}
+
+func (s *SimulatedPort) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.input.Write(p)
+	for {
+		line, err := s.input.ReadString('\r')
+		if err != nil {
+			// incomplete command, put it back until the rest arrives
+			s.input.Reset()
+			s.input.WriteString(line)
+			break
+		}
+		s.handle(strings.TrimSuffix(line, "\r"))
+	}
+	return len(p), nil
+}
+
+func (s *SimulatedPort) Read(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.response.Len() == 0 {
+		return 0, io.EOF
+	}
+	return s.response.Read(p)
+}
+
+func (s *SimulatedPort) handle(command string) {
+	s.Commands = append(s.Commands, command)
+	args := strings.Split(command, ",")
+	ints := make([]int, len(args))
+	for i, arg := range args[1:] {
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			s.response.WriteString(fmt.Sprintf("!8 Err: Unknown parameter '%s'\r\n", arg))
+			return
+		}
+		ints[i+1] = n
+	}
+	switch args[0] {
+	case "SM":
+		if len(args) != 4 {
+			s.response.WriteString("!8 Err: Wrong number of parameters\r\n")
+			return
+		}
+		s.Elapsed += ints[1]
+		s.Motor1 += ints[2]
+		s.Motor2 += ints[3]
+	case "SP":
+		if len(args) < 2 {
+			s.response.WriteString("!8 Err: Wrong number of parameters\r\n")
+			return
+		}
+		s.PenUp = ints[1] == 1
+		if len(args) > 2 {
+			s.Elapsed += ints[2]
+		}
+	case "QB":
+		// pretend that the button was pressed, so that pauses don't block
+		s.response.WriteString("1\r\n")
+	case "EM", "SC", "SR":
+	default:
+		s.response.WriteString(fmt.Sprintf("!8 Err: Unknown command '%s'\r\n", args[0]))
+		return
+	}
+	s.response.WriteString("OK\r\n")
+}
@@ -0,0 +1,194 @@
+package batch
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/libeks/go-plotter-svg/motion"
+	"github.com/libeks/go-plotter-svg/scenes"
+)
+
+type Status string
+
+const (
+	StatusOK       Status = "ok"
+	StatusFailed   Status = "failed"
+	StatusTimedOut Status = "timed out"
+)
+
+// Task renders a document and then writes it, a panic in either is reported as a failure of the task
+type Task struct {
+	Name   string
+	Render func() (scenes.Document, error)
+	Write  func(scenes.Document) ([]string, error) // returns the paths of the files it wrote
+}
+
+// Result is what happened to a task
+type Result struct {
+	Name            string        `json:"name"`
+	Status          Status        `json:"status"`
+	Error           string        `json:"error,omitempty"`
+	Duration        time.Duration `json:"-"`
+	DurationSeconds float64       `json:"duration_seconds"`
+	Seed            int64         `json:"seed,omitempty"`
+	Files           []string      `json:"files,omitempty"`
+	Layers          []LayerStats  `json:"layers,omitempty"`
+	Estimate        time.Duration `json:"-"`
+	EstimateSeconds float64       `json:"estimate_seconds"`
+	StillRunning    bool          `json:"still_running,omitempty"` // the rendering timed out, and hadn't returned when Run did
+}
+
+// LayerStats are the statistics of one layer of the rendered document
+type LayerStats struct {
+	Page            int     `json:"page"`
+	Name            string  `json:"name"`
+	Curves          int     `json:"curves"`
+	Down            float64 `json:"down_meters"`
+	Up              float64 `json:"up_meters"`
+	EstimateSeconds float64 `json:"estimate_seconds"`
+}
+
+// Run runs the tasks on a pool of workers, and returns their results in the order of the tasks. A task whose
+// rendering takes longer than the timeout is reported as timed out, and its files aren't written. Go can't
+// stop a running goroutine, so the rendering carries on in the background until it returns, and its result
+// is dropped. Such a rendering keeps its slot of the pool until it returns, so that no more than workers
+// renderings run at once, and the tasks after it wait for a slot. A timeout of 0 lets every task take as long
+// as it needs.
+func Run(tasks []Task, workers int, timeout time.Duration) []Result {
+	workers = max(workers, 1)
+	results := make([]Result, len(tasks))
+	finished := make([]<-chan struct{}, len(tasks))
+	slots := make(chan struct{}, workers) // a token for each rendering that is running, timed out or not
+	indices := make(chan int)
+	wg := sync.WaitGroup{}
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				results[i], finished[i] = run(tasks[i], timeout, slots)
+			}
+		}()
+	}
+	for i := range tasks {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+	for i := range results {
+		if results[i].Status != StatusTimedOut {
+			continue
+		}
+		select {
+		case <-finished[i]:
+		default:
+			results[i].StillRunning = true
+			results[i].Error += ", and is still running in the background"
+		}
+	}
+	return results
+}
+
+// run runs the task once a slot is free, and returns its result along with a channel that's closed when its
+// rendering returns
+func run(task Task, timeout time.Duration, slots chan struct{}) (Result, <-chan struct{}) {
+	slots <- struct{}{}
+	start := time.Now()
+	result := Result{Name: task.Name, Status: StatusOK}
+	doc, finished, err := render(task, timeout, slots)
+	if err == nil {
+		result.Files, err = write(task, doc)
+	}
+	switch {
+	case errors.Is(err, errTimeout):
+		result.Status = StatusTimedOut
+		result.Error = fmt.Sprintf("rendering took longer than %s", timeout)
+	case err != nil:
+		result.Status = StatusFailed
+		result.Error = err.Error()
+	default:
+		if source, ok := doc.Source(); ok {
+			result.Seed = source.Seed
+		}
+		result.Layers, result.Estimate = stats(doc)
+	}
+	result.Duration = time.Since(start)
+	result.DurationSeconds = result.Duration.Seconds()
+	result.EstimateSeconds = result.Estimate.Seconds()
+	return result, finished
+}
+
+var errTimeout = errors.New("timed out")
+
+// render renders the task in the slot that run took, which is freed when the rendering returns, even if that's
+// after the timeout
+func render(task Task, timeout time.Duration, slots chan struct{}) (scenes.Document, <-chan struct{}, error) {
+	type rendered struct {
+		doc scenes.Document
+		err error
+	}
+	done := make(chan rendered, 1) // buffered, so that a render that timed out can still finish
+	finished := make(chan struct{})
+	go func() {
+		defer func() {
+			<-slots
+			close(finished)
+		}()
+		defer func() {
+			if r := recover(); r != nil {
+				done <- rendered{err: panicError(r)}
+			}
+		}()
+		doc, err := task.Render()
+		done <- rendered{doc, err}
+	}()
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case r := <-done:
+		return r.doc, finished, r.err
+	case <-expired:
+		return scenes.Document{}, finished, errTimeout
+	}
+}
+
+func write(task Task, doc scenes.Document) (files []string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicError(r)
+		}
+	}()
+	return task.Write(doc)
+}
+
+func panicError(r any) error {
+	return fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+}
+
+func stats(doc scenes.Document) ([]LayerStats, time.Duration) {
+	layers := []LayerStats{}
+	total := time.Duration(0)
+	for i := range doc.NumPages() {
+		for _, layer := range doc.Page(i).GetLayers() {
+			stats := layer.Statistics(motion.DefaultModel()).Statistics
+			estimate := stats.Time()
+			total += estimate
+			layers = append(layers, LayerStats{
+				Page:            i,
+				Name:            layer.Name(),
+				Curves:          stats.Curves,
+				Down:            stats.Down,
+				Up:              stats.Up,
+				EstimateSeconds: estimate.Seconds(),
+			})
+		}
+	}
+	return layers, total
+}
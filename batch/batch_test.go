@@ -0,0 +1,135 @@
+package batch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/primitives"
+	"github.com/libeks/go-plotter-svg/scenes"
+)
+
+func TestRun(t *testing.T) {
+	doc := scenes.Document{}.
+		AddLayer(scenes.NewLayer("frame").WithLineLike(lines.LinesFromBBox(primitives.BBox{LowerRight: primitives.Point{X: 100, Y: 100}}))).
+		WithSource(scenes.Source{Scene: "frame", Seed: 42})
+	renderDoc := func() (scenes.Document, error) { return doc, nil }
+	writeDoc := func(scenes.Document) ([]string, error) { return []string{"frame.svg"}, nil }
+	block := make(chan struct{})
+	defer close(block)
+	tasks := []Task{
+		{Name: "ok", Render: renderDoc, Write: writeDoc},
+		{Name: "error", Render: func() (scenes.Document, error) { return scenes.Document{}, errors.New("no luck") }, Write: writeDoc},
+		{Name: "panic", Render: func() (scenes.Document, error) { panic("cell is nil") }, Write: writeDoc},
+		{Name: "slow", Render: func() (scenes.Document, error) { <-block; return doc, nil }, Write: writeDoc},
+		{Name: "write panic", Render: renderDoc, Write: func(scenes.Document) ([]string, error) { panic("disk full") }},
+	}
+	results := Run(tasks, 2, 50*time.Millisecond)
+	type summary struct {
+		Name   string
+		Status Status
+		Error  string
+		Seed   int64
+		Files  []string
+		Curves int
+	}
+	got := []summary{}
+	for _, r := range results {
+		s := summary{Name: r.Name, Status: r.Status, Error: strings.SplitN(r.Error, "\n", 2)[0], Seed: r.Seed, Files: r.Files}
+		for _, layer := range r.Layers {
+			s.Curves += layer.Curves
+		}
+		got = append(got, s)
+	}
+	want := []summary{
+		{Name: "ok", Status: StatusOK, Seed: 42, Files: []string{"frame.svg"}, Curves: 1},
+		{Name: "error", Status: StatusFailed, Error: "no luck"},
+		{Name: "panic", Status: StatusFailed, Error: "panic: cell is nil"},
+		{Name: "slow", Status: StatusTimedOut, Error: "rendering took longer than 50ms, and is still running in the background"},
+		{Name: "write panic", Status: StatusFailed, Error: "panic: disk full"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected diff %v", diff)
+	}
+}
+
+// a rendering that timed out keeps its slot, so the task after it waits until it returns
+func TestRunBounded(t *testing.T) {
+	release := make(chan struct{})
+	var running, most atomic.Int32
+	track := func(wait <-chan struct{}) func() (scenes.Document, error) {
+		return func() (scenes.Document, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			if n > most.Load() {
+				most.Store(n)
+			}
+			if wait != nil {
+				<-wait
+			}
+			return scenes.Document{}, nil
+		}
+	}
+	writeDoc := func(scenes.Document) ([]string, error) { return nil, nil }
+	tasks := []Task{
+		{Name: "slow", Render: track(release), Write: writeDoc},
+		{Name: "next", Render: track(nil), Write: writeDoc},
+	}
+	done := make(chan []Result)
+	go func() { done <- Run(tasks, 1, 10*time.Millisecond) }()
+	select {
+	case <-done:
+		t.Fatalf("Run() returned while the rendering that timed out was holding the only slot")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+	results := <-done
+	if most.Load() > 1 {
+		t.Errorf("%d renderings ran at once with a single worker", most.Load())
+	}
+	if results[0].Status != StatusTimedOut || results[0].StillRunning {
+		t.Errorf("Got status %s, still running %v for the slow task, want it to have timed out and returned", results[0].Status, results[0].StillRunning)
+	}
+	if results[1].Status != StatusOK {
+		t.Errorf("Got status %s for the next task, want %s", results[1].Status, StatusOK)
+	}
+}
+
+func TestReport(t *testing.T) {
+	report := Report{
+		Duration: time.Second,
+		Results: []Result{
+			{Name: "ok", Status: StatusOK, Files: []string{"gallery/debug/ok.svg"}, Layers: []LayerStats{{Name: "frame", Curves: 4}}},
+			{Name: "panic", Status: StatusFailed, Error: "panic: <nil>"},
+			{Name: "slow", Status: StatusTimedOut, Error: "rendering took longer than 1s", StillRunning: true},
+		},
+	}
+	b := &bytes.Buffer{}
+	if err := report.WriteJSON(b); err != nil {
+		t.Fatalf("WriteJSON() returned error %v", err)
+	}
+	got := Report{}
+	if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+		t.Fatalf("Unmarshal() returned error %v", err)
+	}
+	if diff := cmp.Diff(report.Results, got.Results); diff != "" {
+		t.Errorf("Unexpected diff %v", diff)
+	}
+
+	b.Reset()
+	if err := report.WriteHTML(b, "gallery/debug"); err != nil {
+		t.Fatalf("WriteHTML() returned error %v", err)
+	}
+	for _, want := range []string{`<a href="ok.svg">`, "panic: &lt;nil&gt;", "1 ok, 1 failed, 1 timed out (1 still running)"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("HTML report doesn't contain %q", want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package batch
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Report is a summary of a batch of tasks, written as JSON or as an HTML page
+type Report struct {
+	Started         time.Time     `json:"started"`
+	Duration        time.Duration `json:"-"`
+	DurationSeconds float64       `json:"duration_seconds"`
+	Results         []Result      `json:"results"`
+}
+
+func NewReport(started time.Time, results []Result) Report {
+	duration := time.Since(started)
+	return Report{Started: started, Duration: duration, DurationSeconds: duration.Seconds(), Results: results}
+}
+
+// Count returns how many of the tasks ended with the status
+func (r Report) Count(status Status) int {
+	n := 0
+	for _, result := range r.Results {
+		if result.Status == status {
+			n += 1
+		}
+	}
+	return n
+}
+
+// StillRunning returns how many of the tasks timed out with their rendering still running
+func (r Report) StillRunning() int {
+	n := 0
+	for _, result := range r.Results {
+		if result.StillRunning {
+			n += 1
+		}
+	}
+	return n
+}
+
+// Summary is a line per task, followed by the totals
+func (r Report) Summary() string {
+	b := &strings.Builder{}
+	for _, result := range r.Results {
+		fmt.Fprintf(b, "%-40s %-10s %8s", result.Name, result.Status, result.Duration.Round(time.Millisecond))
+		if result.Error != "" {
+			fmt.Fprintf(b, "  %s", strings.SplitN(result.Error, "\n", 2)[0])
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(b, "%d scenes in %s: %d ok, %d failed, %d timed out", len(r.Results), r.Duration.Round(time.Millisecond),
+		r.Count(StatusOK), r.Count(StatusFailed), r.Count(StatusTimedOut))
+	if n := r.StillRunning(); n > 0 {
+		fmt.Fprintf(b, " (%d still running)", n)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// WriteReport writes the report as JSON if the file name ends in .json, and as HTML otherwise
+func (r Report) WriteReport(fname string) error {
+	fmt.Printf("trying to open file %s\n", fname)
+	f, err := os.OpenFile(fname, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if strings.EqualFold(filepath.Ext(fname), ".json") {
+		err = r.WriteJSON(f)
+	} else {
+		err = r.WriteHTML(f, filepath.Dir(fname))
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Finished writing report to file %s\n", fname)
+	return nil
+}
+
+func (r Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteHTML writes the report as a page, with links to the files relative to dir, where the page is
+func (r Report) WriteHTML(w io.Writer, dir string) error {
+	return reportTemplate.Execute(w, struct {
+		Report
+		Dir string
+	}{r, dir})
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"round": func(d time.Duration) time.Duration { return d.Round(time.Millisecond) },
+	"seconds": func(s float64) time.Duration {
+		return time.Duration(s * float64(time.Second)).Round(time.Second)
+	},
+	"link": func(dir, file string) string {
+		if rel, err := filepath.Rel(dir, file); err == nil {
+			return filepath.ToSlash(rel)
+		}
+		return filepath.ToSlash(file)
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Render report</title>
+<style>
+  body { font: 14px sans-serif; }
+  table { border-collapse: collapse; }
+  td, th { text-align: left; vertical-align: top; padding: 4px 8px; border-bottom: 1px solid #ddd; }
+  .ok { color: #080; }
+  .failed, .timed { color: #b00; }
+  pre { margin: 0; max-height: 12em; overflow: auto; font-size: 12px; }
+  .layers td { border: none; padding: 0 8px 0 0; font-size: 12px; }
+</style>
+</head>
+<body>
+<h1>Render report</h1>
+<p>Started {{.Started.Format "2006-01-02 15:04:05"}}, took {{round .Duration}}.
+{{len .Results}} scenes: {{.Count "ok"}} ok, {{.Count "failed"}} failed, {{.Count "timed out"}} timed out{{with .StillRunning}} ({{.}} still running){{end}}.</p>
+<table>
+<tr><th>scene</th><th>status</th><th>duration</th><th>seed</th><th>plot time</th><th>layers</th><th>files</th></tr>
+{{range .Results}}<tr>
+  <td>{{.Name}}</td>
+  <td class="{{.Status}}">{{.Status}}{{if .Error}}<pre>{{.Error}}</pre>{{end}}</td>
+  <td>{{round .Duration}}</td>
+  <td>{{if .Seed}}{{.Seed}}{{end}}</td>
+  <td>{{if .Layers}}{{seconds .EstimateSeconds}}{{end}}</td>
+  <td>{{if .Layers}}<table class="layers">{{range .Layers}}<tr><td>{{.Page}}</td><td>{{.Name}}</td><td>{{.Curves}} curves</td><td>{{printf "%.1f" .Down}}m down</td><td>{{printf "%.1f" .Up}}m up</td></tr>{{end}}</table>{{end}}</td>
+  <td>{{range .Files}}<a href="{{link $.Dir .}}">{{link $.Dir .}}</a><br>{{end}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
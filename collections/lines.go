@@ -212,7 +212,11 @@ func ClipLineSegmentToObject(ls lines.LineSegment, obj objects.Object) []lines.L
 func ClipCircleToObject(c objects.Circle, obj objects.Object) []lines.LineLike {
 	ts := obj.IntersectCircleTs(c)
 	if len(ts) == 0 {
-		return []lines.LineLike{c}
+		// the circle is either all inside or all outside
+		if obj.Inside(c.At(0)) {
+			return []lines.LineLike{c}
+		}
+		return nil
 	}
 	slices.Sort(ts)
 	slices.Reverse(ts)
@@ -267,11 +271,11 @@ func (d SineDensity) Density(a float64) float64 {
 	return d.Min + dRange*(math.Sin(theta)+1)/2
 }
 
-func RandomlyAllocateSegments(segments [][]lines.LineLike, threshold float64) ([]lines.LineLike, []lines.LineLike) {
+func RandomlyAllocateSegments(r *rand.Rand, segments [][]lines.LineLike, threshold float64) ([]lines.LineLike, []lines.LineLike) {
 	layer1 := []lines.LineLike{}
 	layer2 := []lines.LineLike{}
 	for _, segs := range segments {
-		if rand.Float64() > threshold {
+		if r.Float64() > threshold {
 			layer1 = append(layer1, segs...)
 		} else {
 			layer2 = append(layer2, segs...)
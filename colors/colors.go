@@ -0,0 +1,60 @@
+package colors
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// named are the CSS color keywords that scenes are likely to use
+var named = map[string]color.RGBA{
+	"black":   {0, 0, 0, 255},
+	"white":   {255, 255, 255, 255},
+	"red":     {255, 0, 0, 255},
+	"green":   {0, 128, 0, 255},
+	"lime":    {0, 255, 0, 255},
+	"blue":    {0, 0, 255, 255},
+	"yellow":  {255, 255, 0, 255},
+	"cyan":    {0, 255, 255, 255},
+	"aqua":    {0, 255, 255, 255},
+	"magenta": {255, 0, 255, 255},
+	"fuchsia": {255, 0, 255, 255},
+	"orange":  {255, 165, 0, 255},
+	"purple":  {128, 0, 128, 255},
+	"pink":    {255, 192, 203, 255},
+	"brown":   {165, 42, 42, 255},
+	"grey":    {128, 128, 128, 255},
+	"gray":    {128, 128, 128, 255},
+	"silver":  {192, 192, 192, 255},
+	"maroon":  {128, 0, 0, 255},
+	"navy":    {0, 0, 128, 255},
+	"olive":   {128, 128, 0, 255},
+	"teal":    {0, 128, 128, 255},
+	"gold":    {255, 215, 0, 255},
+	"violet":  {238, 130, 238, 255},
+	"indigo":  {75, 0, 130, 255},
+}
+
+// Parse understands CSS color keywords, as well as #rgb and #rrggbb hex colors.
+// An empty string is black, which is what an SVG renders for a Layer without a color.
+func Parse(s string) (color.RGBA, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return named["black"], nil
+	}
+	if c, ok := named[s]; ok {
+		return c, nil
+	}
+	if hex, ok := strings.CutPrefix(s, "#"); ok {
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if len(hex) == 6 {
+			if v, err := strconv.ParseUint(hex, 16, 32); err == nil {
+				return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 255}, nil
+			}
+		}
+	}
+	return color.RGBA{}, fmt.Errorf("unknown color '%s'", s)
+}
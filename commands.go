@@ -0,0 +1,830 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/libeks/go-plotter-svg/axidraw"
+	"github.com/libeks/go-plotter-svg/batch"
+	"github.com/libeks/go-plotter-svg/dxf"
+	"github.com/libeks/go-plotter-svg/gcode"
+	"github.com/libeks/go-plotter-svg/hpgl"
+	"github.com/libeks/go-plotter-svg/motion"
+	"github.com/libeks/go-plotter-svg/paper"
+	"github.com/libeks/go-plotter-svg/pdf"
+	"github.com/libeks/go-plotter-svg/preview"
+	"github.com/libeks/go-plotter-svg/route"
+	"github.com/libeks/go-plotter-svg/scenes"
+	"github.com/libeks/go-plotter-svg/server"
+	"github.com/libeks/go-plotter-svg/split"
+	"github.com/libeks/go-plotter-svg/svg"
+	"github.com/libeks/go-plotter-svg/units"
+)
+
+// the formats that render, preview, export and optimize can write, with the extension of their files
+var formats = map[string]string{
+	"svg":   ".svg",
+	"png":   ".png",
+	"pdf":   ".pdf",
+	"gcode": ".gcode",
+	"hpgl":  ".hpgl",
+	"dxf":   ".dxf",
+	"json":  ".json",
+}
+
+// scenes described in JSON files are loaded from here, unless -scene-dir says otherwise
+const (
+	defaultSceneDir = "descriptions"
+	sceneDirUsage   = "directory of JSON scene descriptions to add to the built-in scenes"
+)
+
+// loadScenes returns the built-in scenes, along with those described in the files of dir
+func loadScenes(dir string) ([]scenes.Scene, error) {
+	if err := checkSceneDir(dir); err != nil {
+		return nil, err
+	}
+	library := scenes.GatherScenes()
+	if err := library.LoadDescriptions(dir); err != nil {
+		return nil, err
+	}
+	return library.Scenes(), nil
+}
+
+// checkSceneDir makes sure that a directory other than the default exists, since it was asked for
+func checkSceneDir(dir string) error {
+	if dir == defaultSceneDir {
+		return nil
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return usageErrorf("-scene-dir: %s", err)
+	}
+	return nil
+}
+
+func findScene(dir, name string) (scenes.Scene, error) {
+	if err := checkSceneDir(dir); err != nil {
+		return scenes.Scene{}, err
+	}
+	library := scenes.GatherScenes()
+	if err := library.LoadDescriptions(dir); err != nil {
+		return scenes.Scene{}, err
+	}
+	scene, err := library.GetScene(name)
+	if err != nil {
+		return scenes.Scene{}, usageError{err.Error()}
+	}
+	return scene, nil
+}
+
+func newFlagSet(name, synopsis string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	fs.Usage = func() {
+		cmd, _ := findCommand(name)
+		fmt.Fprintf(os.Stderr, "Usage: go-plotter-svg %s %s\n\n%s\n\nOptions:\n", name, synopsis, cmd.summary)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse parses the flags of a command, which doesn't take any positional arguments
+func parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return err
+		}
+		return usageError{err.Error()}
+	}
+	if fs.NArg() > 0 {
+		return usageErrorf("unexpected arguments %v", fs.Args())
+	}
+	return nil
+}
+
+// settings collects repeated -set name=value flags
+type settings []string
+
+func (s *settings) String() string {
+	return strings.Join(*s, " ")
+}
+
+func (s *settings) Set(v string) error {
+	if _, _, err := scenes.SplitSetting(v); err != nil {
+		return err
+	}
+	*s = append(*s, v)
+	return nil
+}
+
+// sceneOptions choose the scene to generate and the paper to draw it on
+type sceneOptions struct {
+	scene       string
+	sceneDir    string
+	set         settings
+	params      string
+	input       string
+	from        string
+	seed        int64
+	processing  scenes.Processing
+	paper       string
+	orientation string
+	margin      string
+	units       string
+	progress    io.Writer     // where messages about generating and processing the scene go, stdout if nil
+	flags       *flag.FlagSet // the flags that the options were registered with, to tell which ones were given
+}
+
+func (o *sceneOptions) register(fs *flag.FlagSet) {
+	o.flags = fs
+	fs.StringVar(&o.scene, "scene", "test-density-v2", "name of the scene to generate, see the list command")
+	fs.StringVar(&o.sceneDir, "scene-dir", defaultSceneDir, sceneDirUsage)
+	fs.Var(&o.set, "set", "set a parameter of the scene, e.g. -set spacing=40, can be repeated")
+	fs.StringVar(&o.params, "params", "", "JSON file with the parameters of the scene, e.g. {\"spacing\": 40}, overridden by -set")
+	fs.StringVar(&o.input, "in", "", "read a document saved in the json format, instead of generating a scene")
+	fs.StringVar(&o.from, "from", "", "generate the scene again from the metadata of an SVG that it was rendered to, with the same seed, parameters, paper and processing, except for the processing flags that are given along with it")
+	fs.Int64Var(&o.seed, "seed", 0, "seed for the random numbers of the scene, 0 picks a random seed, which is printed and recorded in the SVG")
+	fs.Float64Var(&o.processing.RemoveOverlaps, "remove-overlaps", 0, "take out the parts of straight lines and arcs that retrace earlier ones in the same layer, within this distance in internal units, so that they're only inked once, 0 to keep them")
+	fs.Float64Var(&o.processing.Chain, "chain", 0, "join the lines of each layer whose ends are no further apart than this, in internal units, into continuous paths, so that the pen doesn't lift between them, 0 to leave them apart")
+	fs.Float64Var(&o.processing.Simplify.Tolerance, "simplify", 0, "drop the points of paths that are within this distance, in internal units, of the line without them (Ramer-Douglas-Peucker), 0 to keep them")
+	fs.Float64Var(&o.processing.Simplify.MinArea, "simplify-area", 0, "drop the points of paths that make a triangle of less than this area, in square internal units, with their neighbors (Visvalingam-Whyatt), 0 to keep them")
+	fs.Float64Var(&o.processing.Simplify.MaxDeviation, "fit-curves", 0, "replace runs of straight chunks in paths with Bezier curves that stay within this distance of their points, in internal units, 0 to keep them straight")
+	fs.BoolVar(&o.processing.Optimize, "optimize", false, "reorder the lines of each layer to cut down on pen-up travel, reversing them and moving the start of closed curves where that helps")
+	fs.DurationVar(&o.processing.OptimizeBudget, "optimize-budget", route.DefaultBudget, "with -optimize, how long to spend on improving the order of each layer, 0 to keep going until nothing improves")
+	fs.StringVar(&o.paper, "paper", paper.Default().Size.Name, fmt.Sprintf("paper size, one of %s", paperNames()))
+	fs.StringVar(&o.orientation, "orientation", "landscape", "landscape or portrait")
+	fs.StringVar(&o.margin, "margin", "", "distance between the edges of the paper and the drawing, e.g. 10mm (default 500 internal units)")
+	fs.StringVar(&o.units, "units", "", "unit of the SVG size, one of mm, in or px (default the unit of the paper size)")
+}
+
+// process cleans up, chains, simplifies and reorders the lines of the document, see scenes.Document.Process, as
+// asked for by -remove-overlaps, -chain, -simplify, -simplify-area, -fit-curves and -optimize, or else as recorded
+// in the SVG of -from
+func (o sceneOptions) process(doc scenes.Document) (scenes.Document, error) {
+	p := o.processing
+	if o.from != "" {
+		m, err := svg.ReadMetadata(o.from)
+		if err != nil {
+			return scenes.Document{}, err
+		}
+		if m.Processing != nil {
+			p = o.overProcessing(*m.Processing)
+		}
+	}
+	return doc.Process(p, o.progressWriter()), nil
+}
+
+// overProcessing is the processing that was recorded, with the processing flags that were given taking precedence
+func (o sceneOptions) overProcessing(saved scenes.Processing) scenes.Processing {
+	p := o.processing
+	if !isSet(o.flags, "remove-overlaps") {
+		p.RemoveOverlaps = saved.RemoveOverlaps
+	}
+	if !isSet(o.flags, "chain") {
+		p.Chain = saved.Chain
+	}
+	if !isSet(o.flags, "simplify") {
+		p.Simplify.Tolerance = saved.Simplify.Tolerance
+	}
+	if !isSet(o.flags, "simplify-area") {
+		p.Simplify.MinArea = saved.Simplify.MinArea
+	}
+	if !isSet(o.flags, "fit-curves") {
+		p.Simplify.MaxDeviation = saved.Simplify.MaxDeviation
+	}
+	if !isSet(o.flags, "optimize") {
+		p.Optimize = saved.Optimize
+	}
+	// a budget is only recorded along with -optimize, otherwise the default of the flag stands
+	if !isSet(o.flags, "optimize-budget") && saved.Optimize {
+		p.OptimizeBudget = saved.OptimizeBudget
+	}
+	return p
+}
+
+func (o sceneOptions) progressWriter() io.Writer {
+	if o.progress == nil {
+		return os.Stdout
+	}
+	return o.progress
+}
+
+func paperNames() string {
+	names := []string{}
+	for _, size := range paper.Sizes {
+		names = append(names, size.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func (o sceneOptions) layout() (paper.Layout, error) {
+	if o.from != "" {
+		m, err := svg.ReadMetadata(o.from)
+		if err != nil {
+			return paper.Layout{}, err
+		}
+		return m.Layout()
+	}
+	layout := paper.Default()
+	var err error
+	if layout.Size, err = paper.Lookup(o.paper); err != nil {
+		return paper.Layout{}, usageError{err.Error()}
+	}
+	if layout.Orientation, err = paper.ParseOrientation(o.orientation); err != nil {
+		return paper.Layout{}, usageError{err.Error()}
+	}
+	// the page is measured in the paper's own units, unless asked otherwise
+	layout.Unit = layout.Size.Unit
+	if o.units != "" {
+		if layout.Unit, err = units.ParseUnit(o.units); err != nil {
+			return paper.Layout{}, usageError{err.Error()}
+		}
+	}
+	if o.margin != "" {
+		if layout.Margin, err = units.ParseLength(o.margin, layout.Unit); err != nil {
+			return paper.Layout{}, usageErrorf("-margin: %s", err)
+		}
+	}
+	return layout, nil
+}
+
+// document generates the scene, or reads it from the saved document
+func (o sceneOptions) document(layout paper.Layout) (scenes.Document, error) {
+	var doc scenes.Document
+	if o.input != "" {
+		var err error
+		if doc, err = scenes.ReadJSON(o.input); err != nil {
+			return scenes.Document{}, err
+		}
+	} else {
+		scene, values, seed, err := o.sceneValues()
+		if err != nil {
+			return scenes.Document{}, err
+		}
+		params, err := scene.WithValues(values)
+		if err != nil {
+			return scenes.Document{}, usageError{err.Error()}
+		}
+		if scene.Random {
+			fmt.Fprintf(o.progressWriter(), "Rendering %s with seed %d\n", scene.Name, seed)
+		}
+		doc = scene.Render(layout.SceneBox(), params, seed)
+	}
+	return o.process(doc)
+}
+
+// sceneValues looks up the scene, along with the values of its parameters and the seed to render it with,
+// which come from the SVG of -from, then from the parameters file, and then from the -set flags
+func (o sceneOptions) sceneValues() (scenes.Scene, map[string]any, int64, error) {
+	name, seed, values := o.scene, o.seed, map[string]any{}
+	if o.from != "" {
+		m, err := svg.ReadMetadata(o.from)
+		if err != nil {
+			return scenes.Scene{}, nil, 0, err
+		}
+		name, seed = m.Scene, m.Seed
+		if m.Params != nil {
+			values = m.Params
+		}
+	}
+	scene, err := findScene(o.sceneDir, name)
+	if err != nil {
+		return scenes.Scene{}, nil, 0, err
+	}
+	if o.params != "" {
+		b, err := os.ReadFile(o.params)
+		if err != nil {
+			return scenes.Scene{}, nil, 0, err
+		}
+		if err := json.Unmarshal(b, &values); err != nil {
+			return scenes.Scene{}, nil, 0, fmt.Errorf("reading %s: %w", o.params, err)
+		}
+	}
+	for _, setting := range o.set {
+		name, value, _ := scenes.SplitSetting(setting) // checked by settings.Set
+		values[name] = value
+	}
+	if seed == 0 {
+		seed = rand.Int63()
+	}
+	return scene, values, seed, nil
+}
+
+// outputOptions choose which files get written, and where
+type outputOptions struct {
+	outDir  string
+	name    string
+	fname   string
+	formats string
+	dpi     float64
+	split   string
+	axidraw string
+}
+
+func (o *outputOptions) register(fs *flag.FlagSet, defaultFormats string) {
+	names := []string{}
+	for format := range formats {
+		names = append(names, format)
+	}
+	slices.Sort(names)
+	fs.StringVar(&o.outDir, "out-dir", "gallery", "directory to write the files to")
+	fs.StringVar(&o.name, "name", "test", "name of the files, without the extension")
+	fs.StringVar(&o.fname, "fname", "", "path of the files, overrides -out-dir and -name, e.g. gallery/test.svg")
+	fs.StringVar(&o.formats, "format", defaultFormats, fmt.Sprintf("comma-separated list of formats to write, out of %s", strings.Join(names, ", ")))
+	fs.Float64Var(&o.dpi, "dpi", 100, "resolution of the png format")
+	fs.StringVar(&o.split, "split", "", "also write one file per pen, in the svg or gcode format, with a manifest of the passes")
+	fs.StringVar(&o.axidraw, "axidraw", "", "serial port of an AxiDraw to plot the document on, e.g. /dev/ttyACM0")
+}
+
+func (o outputOptions) validate() error {
+	for _, format := range o.formatList() {
+		if _, ok := formats[format]; !ok {
+			return usageErrorf("unknown format '%s'", format)
+		}
+	}
+	if o.split != "" && o.split != split.FormatSVG && o.split != split.FormatGCode {
+		return usageErrorf("-split must be svg or gcode, got '%s'", o.split)
+	}
+	if o.dpi <= 0 {
+		return usageErrorf("-dpi must be positive, got %v", o.dpi)
+	}
+	return nil
+}
+
+// motionOptions describe how the plotter moves, to estimate how long a plot takes
+type motionOptions struct {
+	model motion.Model
+}
+
+func (o *motionOptions) register(fs *flag.FlagSet) {
+	o.model = motion.DefaultModel()
+	fs.Float64Var(&o.model.Drawing.Speed, "draw-speed", o.model.Drawing.Speed, "top speed of the pen while drawing, in mm/s")
+	fs.Float64Var(&o.model.Drawing.Acceleration, "draw-acceleration", o.model.Drawing.Acceleration, "acceleration of the pen while drawing, in mm/s^2, 0 for no limit")
+	fs.Float64Var(&o.model.Drawing.Cornering, "cornering", o.model.Drawing.Cornering, "how far the pen may cut corners while drawing, in mm, larger values take corners faster, 0 stops at every corner")
+	fs.Float64Var(&o.model.Travel.Speed, "travel-speed", o.model.Travel.Speed, "top speed of the pen while it's up, in mm/s")
+	fs.Float64Var(&o.model.Travel.Acceleration, "travel-acceleration", o.model.Travel.Acceleration, "acceleration of the pen while it's up, in mm/s^2, 0 for no limit")
+	fs.DurationVar(&o.model.PenUpDelay, "pen-up-delay", o.model.PenUpDelay, "time for the pen to lift")
+	fs.DurationVar(&o.model.PenDownDelay, "pen-down-delay", o.model.PenDownDelay, "time for the pen to come down")
+}
+
+func (o motionOptions) validate() error {
+	if o.model.Drawing.Speed <= 0 || o.model.Travel.Speed <= 0 {
+		return usageErrorf("-draw-speed and -travel-speed must be positive")
+	}
+	if o.model.Drawing.Acceleration < 0 || o.model.Travel.Acceleration < 0 {
+		return usageErrorf("-draw-acceleration and -travel-acceleration can't be negative")
+	}
+	if o.model.Drawing.Cornering < 0 {
+		return usageErrorf("-cornering can't be negative")
+	}
+	if o.model.PenUpDelay < 0 || o.model.PenDownDelay < 0 {
+		return usageErrorf("-pen-up-delay and -pen-down-delay can't be negative")
+	}
+	return nil
+}
+
+func (o outputOptions) formatList() []string {
+	ret := []string{}
+	for _, format := range strings.Split(o.formats, ",") {
+		if format = strings.TrimSpace(format); format != "" {
+			ret = append(ret, format)
+		}
+	}
+	return ret
+}
+
+func (o outputOptions) path(extension string) string {
+	if o.fname != "" {
+		return strings.TrimSuffix(o.fname, filepath.Ext(o.fname)) + extension
+	}
+	return filepath.Join(o.outDir, o.name+extension)
+}
+
+// files are the paths that write writes to, without the page numbers of multi-page documents
+func (o outputOptions) files() []string {
+	files := []string{}
+	for _, format := range o.formatList() {
+		files = append(files, o.path(formats[format]))
+	}
+	return files
+}
+
+// write writes the document in each of the formats
+func (o outputOptions) write(doc scenes.Document, layout paper.Layout) error {
+	if dir := filepath.Dir(o.path("")); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	width, height := layout.Dimensions()
+	for _, format := range o.formatList() {
+		fname := o.path(formats[format])
+		var err error
+		switch format {
+		case "svg":
+			err = svg.SVG{Fname: fname, Paper: layout, Document: doc}.WriteSVG()
+		case "png":
+			err = preview.Preview{Fname: fname, DPI: o.dpi, Width: width, Height: height, Document: doc}.WritePNG()
+		case "pdf":
+			config := pdf.DefaultConfig()
+			config.Width, config.Height = width, height
+			err = pdf.PDF{Fname: fname, Config: config, Document: doc}.WritePDF()
+		case "gcode":
+			err = gcode.GCode{Fname: fname, Config: gcode.DefaultConfig(), Document: doc}.WriteGCode()
+		case "hpgl":
+			err = hpgl.HPGL{Fname: fname, Config: hpgl.DefaultConfig(), Document: doc}.WriteHPGL()
+		case "dxf":
+			config := dxf.DefaultConfig()
+			config.Height = height
+			err = dxf.DXF{Fname: fname, Config: config, Document: doc}.WriteDXF()
+		case "json":
+			err = doc.WriteJSON(fname)
+		}
+		if err != nil {
+			return fmt.Errorf("writing %s: %w", format, err)
+		}
+	}
+	if o.split != "" {
+		_, err := split.Split{
+			Fname:    o.path(formats[o.split]),
+			Format:   o.split,
+			Paper:    layout,
+			GCode:    gcode.DefaultConfig(),
+			Document: doc,
+		}.WriteSplit()
+		if err != nil {
+			return fmt.Errorf("writing split files: %w", err)
+		}
+	}
+	if o.axidraw != "" {
+		port, err := axidraw.OpenPort(o.axidraw)
+		if err != nil {
+			return err
+		}
+		defer port.Close()
+		if err := axidraw.NewDriver(port, axidraw.DefaultConfig()).Plot(doc); err != nil {
+			return fmt.Errorf("plotting on %s: %w", o.axidraw, err)
+		}
+	}
+	return nil
+}
+
+func runRender(args []string) error {
+	fs := newFlagSet("render", "[options]")
+	scene := sceneOptions{}
+	scene.register(fs)
+	out := outputOptions{}
+	out.register(fs, "svg")
+	all := fs.Bool("all", false, "render every scene, each into a file named after the scene")
+	jobs := fs.Int("jobs", runtime.NumCPU(), "with -all, how many scenes to render at the same time")
+	timeout := fs.Duration("timeout", 0, "with -all, how long each scene may take to render before it's given up on, e.g. 30s, 0 for no limit")
+	report := fs.String("report", "", "with -all, where to write the report of the run, as .html or .json (default report.html in the output directory)")
+	expensive := fs.Bool("expensive", false, "with -all, also render the scenes that are marked as expensive")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if err := out.validate(); err != nil {
+		return err
+	}
+	layout, err := scene.layout()
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	if *all {
+		if len(scene.set) > 0 || scene.params != "" {
+			return usageErrorf("-set and -params can't be used with -all, since scenes have different parameters")
+		}
+		if !isSet(fs, "out-dir") {
+			out.outDir = "gallery/debug"
+		}
+		out.fname = ""
+		if *report == "" {
+			*report = filepath.Join(out.outDir, "report.html")
+		}
+		return renderAll(scene, out, layout, *jobs, *timeout, *report, *expensive)
+	}
+	doc, err := scene.document(layout)
+	if err != nil {
+		return err
+	}
+	fmt.Println(doc.Statistics(motion.DefaultModel()))
+	if err := out.write(doc, layout); err != nil {
+		return err
+	}
+	fmt.Printf("Rendering took %s.\n", time.Since(start))
+	return nil
+}
+
+// renderAll renders every scene in a pool of workers, so that a scene that fails or takes too long doesn't
+// hold up the others, and reports how each of them went
+func renderAll(scene sceneOptions, out outputOptions, layout paper.Layout, jobs int, timeout time.Duration, report string, expensive bool) error {
+	if jobs < 1 {
+		return usageErrorf("-jobs must be at least 1, got %d", jobs)
+	}
+	start := time.Now()
+	if err := os.MkdirAll(out.outDir, 0755); err != nil {
+		return err
+	}
+	library, err := loadScenes(scene.sceneDir)
+	if err != nil {
+		return err
+	}
+	tasks := []batch.Task{}
+	skipped := []string{}
+	for _, sc := range library {
+		if sc.Expensive && !expensive {
+			skipped = append(skipped, sc.Name)
+			continue
+		}
+		name := sc.Name
+		sceneOut := out
+		sceneOut.name = name
+		sceneOpts := scene
+		sceneOpts.scene = name
+		tasks = append(tasks, batch.Task{
+			Name: name,
+			Render: func() (scenes.Document, error) {
+				fmt.Printf("Rendering scene %s...\n", name)
+				return sceneOpts.document(layout)
+			},
+			Write: func(doc scenes.Document) ([]string, error) {
+				if err := sceneOut.write(doc, layout); err != nil {
+					return nil, err
+				}
+				return sceneOut.files(), nil
+			},
+		})
+	}
+	if len(skipped) > 0 {
+		fmt.Printf("Skipping expensive scenes %s, render them with -expensive\n", strings.Join(skipped, ", "))
+	}
+	results := batch.NewReport(start, batch.Run(tasks, jobs, timeout))
+	fmt.Print(results.Summary())
+	if err := results.WriteReport(report); err != nil {
+		return fmt.Errorf("writing the report: %w", err)
+	}
+	if failed := len(tasks) - results.Count(batch.StatusOK); failed > 0 {
+		return fmt.Errorf("%d of %d scenes didn't render, see %s", failed, len(tasks), report)
+	}
+	return nil
+}
+
+func isSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+func runList(args []string) error {
+	fs := newFlagSet("list", "")
+	sceneDir := fs.String("scene-dir", defaultSceneDir, sceneDirUsage)
+	tag := fs.String("tag", "", "only list the scenes with this tag, e.g. truchet, foldable, calibration or test")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	library, err := loadScenes(*sceneDir)
+	if err != nil {
+		return err
+	}
+	if *tag != "" {
+		library = slices.DeleteFunc(library, func(scene scenes.Scene) bool { return !scene.HasTag(*tag) })
+		if len(library) == 0 {
+			return fmt.Errorf("no scene has the tag '%s'", *tag)
+		}
+	}
+	fmt.Printf("These scenes are available:\n")
+	for _, scene := range library {
+		fmt.Printf("\t%s%s\n", scene.Name, sceneMarkers(scene))
+		if scene.Description != "" {
+			fmt.Printf("\t\t%s\n", scene.Description)
+		}
+		if len(scene.Pens) > 0 {
+			names := []string{}
+			for _, p := range scene.Pens {
+				names = append(names, p.Name)
+			}
+			fmt.Printf("\t\tpens: %s\n", strings.Join(names, ", "))
+		}
+		for _, param := range scene.Params {
+			fmt.Printf("\t\t-set %s\n", param)
+		}
+	}
+	return nil
+}
+
+// sceneMarkers lists the tags of the scene, and whether it's multi-page or expensive, e.g. " [truchet] (expensive)"
+func sceneMarkers(scene scenes.Scene) string {
+	s := ""
+	if len(scene.Tags) > 0 {
+		s += " [" + strings.Join(scene.Tags, ", ") + "]"
+	}
+	markers := []string{}
+	if scene.MultiPage {
+		markers = append(markers, "multi-page")
+	}
+	if scene.Expensive {
+		markers = append(markers, "expensive")
+	}
+	if len(markers) > 0 {
+		s += " (" + strings.Join(markers, ", ") + ")"
+	}
+	return s
+}
+
+func runStats(args []string) error {
+	fs := newFlagSet("stats", "[options]")
+	scene := sceneOptions{}
+	scene.register(fs)
+	model := motionOptions{}
+	model.register(fs)
+	asJSON := fs.Bool("json", false, "print the statistics as JSON, with times in seconds and distances in meters, and the progress of processing the scene on stderr")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if err := model.validate(); err != nil {
+		return err
+	}
+	layout, err := scene.layout()
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		// keep the messages about processing the scene out of the JSON
+		scene.progress = os.Stderr
+	}
+	doc, err := scene.document(layout)
+	if err != nil {
+		return err
+	}
+	stats := doc.Statistics(model.model)
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	}
+	fmt.Println(stats)
+	return nil
+}
+
+func runPreview(args []string) error {
+	fs := newFlagSet("preview", "[options]")
+	scene := sceneOptions{}
+	scene.register(fs)
+	out := outputOptions{}
+	out.register(fs, "png")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	return generateAndWrite(scene, out)
+}
+
+func runExport(args []string) error {
+	fs := newFlagSet("export", "-in <document.json> [options]")
+	scene := sceneOptions{}
+	scene.register(fs)
+	out := outputOptions{}
+	out.register(fs, "svg")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if scene.input == "" {
+		return usageErrorf("-in is required")
+	}
+	if !isSet(fs, "name") && out.fname == "" {
+		out.name = strings.TrimSuffix(filepath.Base(scene.input), filepath.Ext(scene.input))
+	}
+	return generateAndWrite(scene, out)
+}
+
+func runOptimize(args []string) error {
+	fs := newFlagSet("optimize", "[options]")
+	scene := sceneOptions{}
+	scene.register(fs)
+	out := outputOptions{}
+	out.register(fs, "json")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if err := fs.Set("optimize", "true"); err != nil {
+		return err
+	}
+	return generateAndWrite(scene, out)
+}
+
+func generateAndWrite(scene sceneOptions, out outputOptions) error {
+	if err := out.validate(); err != nil {
+		return err
+	}
+	layout, err := scene.layout()
+	if err != nil {
+		return err
+	}
+	doc, err := scene.document(layout)
+	if err != nil {
+		return err
+	}
+	return out.write(doc, layout)
+}
+
+func runServe(args []string) error {
+	fs := newFlagSet("serve", "[options]")
+	addr := fs.String("addr", "localhost:8080", "address to listen on")
+	sceneDir := fs.String("scene-dir", defaultSceneDir, sceneDirUsage)
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	library, err := loadScenes(*sceneDir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Serving the scenes on http://%s/\n", *addr)
+	return http.ListenAndServe(*addr, server.New(library).Handler())
+}
+
+// axisFlag parses an -x or -y flag of the sweep command
+type axisFlag struct {
+	axis scenes.Axis
+}
+
+func (a *axisFlag) String() string {
+	if a == nil || a.axis.Param == "" {
+		return ""
+	}
+	return a.axis.Param + "=" + strings.Join(a.axis.Values, ",")
+}
+
+func (a *axisFlag) Set(v string) error {
+	axis, err := scenes.ParseAxis(v)
+	if err != nil {
+		return err
+	}
+	a.axis = axis
+	return nil
+}
+
+func runSweep(args []string) error {
+	fs := newFlagSet("sweep", "-scene <name> -x <param>=<values> [-y <param>=<values>] [options]")
+	scene := sceneOptions{}
+	scene.register(fs)
+	out := outputOptions{}
+	out.register(fs, "svg")
+	x, y := axisFlag{}, axisFlag{}
+	fs.Var(&x, "x", "parameter that changes from column to column, with its values, e.g. n=4,8,16 or spacing=10:40:4 for 4 values from 10 to 40, or seed=1,2,3")
+	fs.Var(&y, "y", "parameter that changes from row to row, in the same form as -x")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if err := out.validate(); err != nil {
+		return err
+	}
+	if x.axis.Param == "" {
+		return usageErrorf("-x is required")
+	}
+	if scene.input != "" {
+		return usageErrorf("-in can't be used with sweep, since the variants are generated from the scene")
+	}
+	if !isSet(fs, "name") && out.fname == "" {
+		out.name = scene.scene + "-sweep"
+	}
+	layout, err := scene.layout()
+	if err != nil {
+		return err
+	}
+	sc, values, seed, err := scene.sceneValues()
+	if err != nil {
+		return err
+	}
+	sweep := scenes.Sweep{Scene: sc, Values: values, Seed: seed, X: x.axis, Y: y.axis}
+	if _, err := sweep.Variants(); err != nil {
+		return usageError{err.Error()}
+	}
+	start := time.Now()
+	fmt.Printf("Rendering %d variants of %s with seed %d\n", max(len(y.axis.Values), 1)*len(x.axis.Values), sc.Name, seed)
+	doc, err := sweep.Render(layout.SceneBox())
+	if err != nil {
+		return err
+	}
+	if doc, err = scene.process(doc); err != nil {
+		return err
+	}
+	fmt.Println(doc.Statistics(motion.DefaultModel()))
+	if err := out.write(doc, layout); err != nil {
+		return err
+	}
+	fmt.Printf("Rendering took %s.\n", time.Since(start))
+	return nil
+}
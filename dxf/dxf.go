@@ -0,0 +1,258 @@
+package dxf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/primitives"
+	"github.com/libeks/go-plotter-svg/scenes"
+	"github.com/libeks/go-plotter-svg/units"
+)
+
+type Config struct {
+	Height      float64 // page height in internal units, used to flip the y-axis, since DXF's y-axis points up
+	Tolerance   float64 // maximum deviation in mm when flattening Bezier curves into polylines
+	UnitsPerMM  float64 // how many internal units fit in a millimeter
+	Annotations bool    // include the annotations of foldable patterns, in an ANNOTATE layer
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Height:     10000,
+		Tolerance:  0.05,
+		UnitsPerMM: units.Millimeter,
+	}
+}
+
+// Layer is a DXF layer, with its color given as an AutoCAD Color Index
+type Layer struct {
+	Name      string
+	Color     int
+	LineLikes []lines.LineLike
+}
+
+type DXF struct {
+	Fname string
+	Config
+	scenes.Document
+}
+
+// WriteDXF writes each page of the document to its own file, named as in Document.PageFileNames.
+// Pages made from foldable patterns get CUT, SCORE and ENGRAVE layers, other pages keep their own layers.
+func (d DXF) WriteDXF() error {
+	for i, fname := range d.Document.PageFileNames(d.Fname) {
+		if err := d.writePageFile(fname, d.Document.Page(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d DXF) writePageFile(fname string, page scenes.Page) error {
+	fmt.Printf("trying to open file %s\n", fname)
+	f, err := os.OpenFile(fname, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	layers := FromPage(page)
+	if patterns := page.FoldablePatterns(); len(patterns) > 0 {
+		layers = FromPatterns(patterns, d.Annotations)
+	}
+	if err := d.Write(f, layers); err != nil {
+		return err
+	}
+	fmt.Printf("Finished rendering to file %s\n", fname)
+	return nil
+}
+
+// FromPage turns every layer of the page into a DXF layer of the same name and color
+func FromPage(page scenes.Page) []Layer {
+	layers := []Layer{}
+	for _, layer := range page.GetLayers() {
+		linelikes := make([]lines.LineLike, 0, len(layer.LineLikes()))
+		offset := layer.Offset()
+		for _, linelike := range layer.LineLikes() {
+			if linelike != nil && !linelike.IsEmpty() {
+				linelikes = append(linelikes, linelike.Translate(offset))
+			}
+		}
+		layers = append(layers, Layer{
+			Name:      layer.Name(),
+			Color:     colorIndex(layer.Color()),
+			LineLikes: linelikes,
+		})
+	}
+	return layers
+}
+
+// Write writes a single DXF file in the R12 format, which just about every cutter software can read.
+// Coordinates are in millimeters.
+func (c Config) Write(w io.Writer, layers []Layer) error {
+	d := &drawing{Config: c, w: bufio.NewWriter(w)}
+	d.pair(0, "SECTION")
+	d.pair(2, "HEADER")
+	d.pair(9, "$ACADVER")
+	d.pair(1, "AC1009")
+	d.pair(9, "$INSUNITS")
+	d.pair(70, "4") // millimeters
+	d.pair(9, "$MEASUREMENT")
+	d.pair(70, "1") // metric
+	d.pair(0, "ENDSEC")
+
+	d.pair(0, "SECTION")
+	d.pair(2, "TABLES")
+	d.pair(0, "TABLE")
+	d.pair(2, "LTYPE")
+	d.pair(70, "1")
+	d.pair(0, "LTYPE")
+	d.pair(2, "CONTINUOUS")
+	d.pair(70, "0")
+	d.pair(3, "Solid line")
+	d.pair(72, "65")
+	d.pair(73, "0")
+	d.pair(40, "0.0")
+	d.pair(0, "ENDTAB")
+	d.pair(0, "TABLE")
+	d.pair(2, "LAYER")
+	d.pair(70, fmt.Sprintf("%d", len(layers)))
+	for _, layer := range layers {
+		d.pair(0, "LAYER")
+		d.pair(2, layerName(layer.Name))
+		d.pair(70, "0")
+		d.pair(62, fmt.Sprintf("%d", layer.Color))
+		d.pair(6, "CONTINUOUS")
+	}
+	d.pair(0, "ENDTAB")
+	d.pair(0, "ENDSEC")
+
+	d.pair(0, "SECTION")
+	d.pair(2, "ENTITIES")
+	for _, layer := range layers {
+		for _, linelike := range layer.LineLikes {
+			d.path(layerName(layer.Name), lines.ToPath(linelike))
+		}
+	}
+	d.pair(0, "ENDSEC")
+	d.pair(0, "EOF")
+	return d.w.Flush()
+}
+
+// layerName strips the characters that aren't allowed in DXF layer names
+func layerName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '$' {
+			return r
+		}
+		return '_'
+	}, name)
+	if name == "" {
+		return "0"
+	}
+	return name
+}
+
+// drawing writes the DXF group code/value pairs
+type drawing struct {
+	Config
+	w *bufio.Writer
+}
+
+func (d *drawing) pair(code int, value string) {
+	fmt.Fprintf(d.w, "%3d\n%s\n", code, value)
+}
+
+func (d *drawing) coords(xCode int, pt primitives.Point) {
+	x, y := d.toMM(pt)
+	d.pair(xCode, fmt.Sprintf("%.4f", x))
+	d.pair(xCode+10, fmt.Sprintf("%.4f", y))
+	d.pair(xCode+20, "0.0")
+}
+
+func (d *drawing) toMM(pt primitives.Point) (float64, float64) {
+	return pt.X / d.UnitsPerMM, (d.Height - pt.Y) / d.UnitsPerMM
+}
+
+// path writes consecutive straight and Bezier chunks as a single polyline, and circle arcs as ARC entities
+func (d *drawing) path(layer string, path lines.Path) {
+	points := []primitives.Point{path.Start()}
+	flush := func() {
+		if len(points) > 1 {
+			d.polyline(layer, points)
+		}
+	}
+	for _, chunk := range path.Chunks() {
+		if arc, ok := chunk.(lines.ArcChunk); ok {
+			flush()
+			d.arc(layer, arc)
+			points = []primitives.Point{arc.Endpoint()}
+			continue
+		}
+		points = append(points, lines.FlattenChunk(chunk, d.Tolerance*d.UnitsPerMM)...)
+	}
+	flush()
+}
+
+func (d *drawing) polyline(layer string, points []primitives.Point) {
+	if len(points) == 2 {
+		d.pair(0, "LINE")
+		d.pair(8, layer)
+		d.coords(10, points[0])
+		d.coords(11, points[1])
+		return
+	}
+	flags := "0"
+	if points[0].Subtract(points[len(points)-1]).Len() < d.Tolerance*d.UnitsPerMM {
+		flags = "1" // closed
+		points = points[:len(points)-1]
+	}
+	d.pair(0, "POLYLINE")
+	d.pair(8, layer)
+	d.pair(66, "1")
+	d.pair(70, flags)
+	d.coords(10, primitives.Origin)
+	for _, pt := range points {
+		d.pair(0, "VERTEX")
+		d.pair(8, layer)
+		d.coords(10, pt)
+	}
+	d.pair(0, "SEQEND")
+	d.pair(8, layer)
+}
+
+func (d *drawing) arc(layer string, arc lines.ArcChunk) {
+	// flipping the y-axis negates the angles. DXF arcs always go counter-clockwise, so if the flipped arc
+	// goes clockwise, draw it from the other end
+	start := -arc.StartAngle()
+	end := start - arc.Sweep()
+	if end < start {
+		start, end = end, start
+	}
+	center := arc.Center()
+	x, y := d.toMM(center)
+	d.pair(0, "ARC")
+	d.pair(8, layer)
+	d.pair(10, fmt.Sprintf("%.4f", x))
+	d.pair(20, fmt.Sprintf("%.4f", y))
+	d.pair(30, "0.0")
+	d.pair(40, fmt.Sprintf("%.4f", arc.Radius()/d.UnitsPerMM))
+	d.pair(50, fmt.Sprintf("%.4f", degrees(start)))
+	d.pair(51, fmt.Sprintf("%.4f", degrees(end)))
+}
+
+// degrees converts radians into degrees in [0, 360)
+func degrees(rad float64) float64 {
+	deg := math.Mod(rad*180/math.Pi, 360)
+	if deg < 0 {
+		deg += 360
+	}
+	if deg == 0 {
+		return 0 // avoid printing -0
+	}
+	return deg
+}
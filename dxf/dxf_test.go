@@ -0,0 +1,96 @@
+package dxf
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/libeks/go-plotter-svg/foldable"
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/objects"
+	"github.com/libeks/go-plotter-svg/primitives"
+)
+
+func polyline(points ...primitives.Point) lines.Path {
+	path := lines.NewPath(points[0])
+	for i := 1; i < len(points); i++ {
+		path = path.AddPathChunk(lines.LineChunk{Start: points[i-1], End: points[i]})
+	}
+	return path
+}
+
+func TestFromPatterns(t *testing.T) {
+	a := primitives.Point{X: 0, Y: 0}
+	b := primitives.Point{X: 100, Y: 0}
+	c := primitives.Point{X: 200, Y: 0}
+	d := primitives.Point{X: 200, Y: 100}
+	e := primitives.Point{X: 100, Y: 100}
+	f := primitives.Point{X: 0, Y: 100}
+	// a flap on the left side of the first face
+	g := primitives.Point{X: -20, Y: 20}
+	h := primitives.Point{X: -20, Y: 80}
+	pattern := foldable.FoldablePattern{
+		// drawn the way foldable.Face.Render does, where every edge appears exactly once
+		Edges: []lines.LineLike{
+			polyline(a, b),
+			polyline(b, c, d, e),
+			polyline(b, e),
+			polyline(e, f),
+			polyline(f, h, g, a),
+			polyline(f, a),
+		},
+		Polygons: []objects.Polygon{
+			{Points: []primitives.Point{a, b, e, f}},
+			{Points: []primitives.Point{b, c, d, e}},
+			{Points: []primitives.Point{f, h, g, a}},
+		},
+		Fill: map[string]foldable.BrushLines{
+			"red": {Color: "red", Lines: []lines.LineLike{polyline(primitives.Point{X: 10, Y: 10}, primitives.Point{X: 90, Y: 10})}},
+		},
+	}
+	layers := FromPatterns([]foldable.FoldablePattern{pattern}, false)
+	got := map[string]float64{}
+	for _, layer := range layers {
+		for _, linelike := range layer.LineLikes {
+			got[layer.Name] += math.Round(linelike.Len())
+		}
+	}
+	flap := math.Round(2*primitives.Vector{X: 20, Y: 20}.Len() + 60)
+	want := map[string]float64{
+		CutLayer:      500 + flap,
+		ScoreLayer:    200,
+		"ENGRAVE-RED": 80,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected diff %v", diff)
+	}
+}
+
+func TestArcAngles(t *testing.T) {
+	config := Config{Height: 0, Tolerance: 0.1, UnitsPerMM: 1}
+	tests := []struct {
+		name      string
+		clockwise bool
+		want      string
+	}{
+		// the first arc passes through the top of the circle on screen, the second one through the bottom,
+		// and they must stay there once the y-axis is flipped
+		{"increasing angle", true, " 50\n0.0000\n 51\n180.0000\n"},
+		{"decreasing angle", false, " 50\n180.0000\n 51\n0.0000\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			arc := lines.CircleArcChunk(primitives.Origin, 10, math.Pi, 2*math.Pi, tt.clockwise)
+			path := lines.NewPath(arc.Startpoint()).AddPathChunk(arc)
+			b := &strings.Builder{}
+			if err := config.Write(b, []Layer{{Name: "arcs", Color: 7, LineLikes: []lines.LineLike{path}}}); err != nil {
+				t.Fatalf("Write() returned error %v", err)
+			}
+			if !strings.Contains(b.String(), tt.want) {
+				t.Errorf("expected the angles %q in\n%s", tt.want, b.String())
+			}
+		})
+	}
+}
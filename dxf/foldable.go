@@ -0,0 +1,146 @@
+package dxf
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/libeks/go-plotter-svg/colors"
+	"github.com/libeks/go-plotter-svg/foldable"
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/objects"
+	"github.com/libeks/go-plotter-svg/primitives"
+)
+
+const (
+	CutLayer      = "CUT"
+	ScoreLayer    = "SCORE"
+	EngraveLayer  = "ENGRAVE"
+	AnnotateLayer = "ANNOTATE"
+
+	// how far apart, in internal units, an edge and a polygon side can be to be considered the same line
+	edgeTolerance = 1.0
+)
+
+// FromPatterns sorts the lines of foldable patterns into layers for a cutter: the outline of each pattern
+// goes into CUT, edges between two faces, or between a face and a flap, go into SCORE, and the fills go
+// into one ENGRAVE layer per color
+func FromPatterns(patterns []foldable.FoldablePattern, annotations bool) []Layer {
+	cut := Layer{Name: CutLayer, Color: 1}     // red
+	score := Layer{Name: ScoreLayer, Color: 5} // blue
+	fills := map[string]Layer{}
+	annotate := Layer{Name: AnnotateLayer, Color: 8} // grey
+	for _, pattern := range patterns {
+		for _, edge := range pattern.Edges {
+			if edge == nil || edge.IsEmpty() {
+				continue
+			}
+			for _, run := range splitFolds(lines.ToPath(edge), pattern.Polygons) {
+				if run.fold {
+					score.LineLikes = append(score.LineLikes, run.path)
+				} else {
+					cut.LineLikes = append(cut.LineLikes, run.path)
+				}
+			}
+		}
+		for label, fill := range pattern.Fill {
+			name := fmt.Sprintf("%s-%s", EngraveLayer, strings.ToUpper(label))
+			layer, ok := fills[name]
+			if !ok {
+				layer = Layer{Name: name, Color: colorIndex(fill.Color)}
+			}
+			layer.LineLikes = append(layer.LineLikes, fill.Lines...)
+			fills[name] = layer
+		}
+		annotate.LineLikes = append(annotate.LineLikes, pattern.Annotations...)
+	}
+	layers := []Layer{cut, score}
+	names := []string{}
+	for name := range fills {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		layers = append(layers, fills[name])
+	}
+	if annotations {
+		layers = append(layers, annotate)
+	}
+	return layers
+}
+
+type run struct {
+	path lines.Path
+	fold bool
+}
+
+// splitFolds breaks the edge up into runs of cut and fold lines. A straight chunk is a fold if it lies on
+// the sides of at least two of the polygons.
+func splitFolds(path lines.Path, polygons []objects.Polygon) []run {
+	runs := []run{}
+	for _, chunk := range path.Chunks() {
+		fold := false
+		if line, ok := chunk.(lines.LineChunk); ok {
+			fold = sharedSides(line, polygons) >= 2
+		}
+		if len(runs) > 0 && runs[len(runs)-1].fold == fold {
+			runs[len(runs)-1].path = runs[len(runs)-1].path.AddPathChunk(chunk)
+			continue
+		}
+		runs = append(runs, run{path: lines.NewPath(chunk.Startpoint()).AddPathChunk(chunk), fold: fold})
+	}
+	return runs
+}
+
+// sharedSides counts the polygons that have a side running along the line
+func sharedSides(line lines.LineChunk, polygons []objects.Polygon) int {
+	count := 0
+	for _, polygon := range polygons {
+		for _, side := range polygon.EdgeLines() {
+			if onSegment(line.Start, side) && onSegment(line.End, side) {
+				count += 1
+				break
+			}
+		}
+	}
+	return count
+}
+
+func onSegment(pt primitives.Point, segment lines.LineSegment) bool {
+	v := segment.P2.Subtract(segment.P1)
+	length := v.Len()
+	if length == 0 {
+		return pt.Subtract(segment.P1).Len() < edgeTolerance
+	}
+	w := pt.Subtract(segment.P1)
+	along := w.Dot(v) / length
+	across := (w.X*v.Y - w.Y*v.X) / length
+	return along > -edgeTolerance && along < length+edgeTolerance && across > -edgeTolerance && across < edgeTolerance
+}
+
+// the AutoCAD Color Index entries that plotter colors are matched against
+var aciColors = []struct {
+	index int
+	name  string
+}{
+	{1, "red"}, {2, "yellow"}, {3, "lime"}, {4, "cyan"}, {5, "blue"}, {6, "magenta"}, {7, "black"},
+	{8, "grey"}, {9, "silver"}, {30, "orange"}, {94, "green"}, {200, "purple"},
+}
+
+// colorIndex finds the closest AutoCAD Color Index to the color. Index 7 is drawn black on white backgrounds.
+func colorIndex(color string) int {
+	c, err := colors.Parse(color)
+	if err != nil {
+		return 7
+	}
+	best, bestDistance := 7, -1
+	for _, aci := range aciColors {
+		ac, _ := colors.Parse(aci.name)
+		dr, dg, db := int(c.R)-int(ac.R), int(c.G)-int(ac.G), int(c.B)-int(ac.B)
+		distance := dr*dr + dg*dg + db*db
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = aci.index, distance
+		}
+	}
+	return best
+}
@@ -2,7 +2,10 @@ package foldable
 
 import (
 	"fmt"
+	"maps"
 	"math"
+	"os"
+	"slices"
 
 	"github.com/libeks/go-plotter-svg/collections"
 	"github.com/libeks/go-plotter-svg/fonts"
@@ -127,7 +130,7 @@ func (c CutOut) computeTrees(container primitives.BBox) cutoutTrees {
 	connectionsCompleted := map[FaceEdge]bool{} // tracks whether all edge connections are accounted for
 	for _, face := range c.Faces {
 		if _, ok := faceByID[face.Name]; ok {
-			fmt.Printf("Edge by the name %s already exists\n", face.Name)
+			fmt.Fprintf(os.Stderr, "Edge by the name %s already exists\n", face.Name)
 		}
 		faceByID[face.Name] = &Face{
 			Shape:    face.Shape,
@@ -151,35 +154,35 @@ func (c CutOut) computeTrees(container primitives.BBox) cutoutTrees {
 		}
 		faceA, ok := faceByID[connection.FaceA]
 		if !ok {
-			fmt.Printf("Could not find face named %s for connection number %d\n", connection.FaceA, i)
+			fmt.Fprintf(os.Stderr, "Could not find face named %s for connection number %d\n", connection.FaceA, i)
 			panic("Couldn't render")
 		}
 		faceB, ok := faceByID[connection.FaceB]
 		if !ok {
-			fmt.Printf("Could not find face named %s for connection number %d\n", connection.FaceB, i)
+			fmt.Fprintf(os.Stderr, "Could not find face named %s for connection number %d\n", connection.FaceB, i)
 			panic("Couldn't render")
 		}
 		if connection.ConnectionType == FaceConnection {
 			if _, ok := visitedFaces[faceB.Name]; ok {
-				fmt.Printf("Face %s has already been connected to, this creates a cycle in the graph", faceB.Name)
+				fmt.Fprintf(os.Stderr, "Face %s has already been connected to, this creates a cycle in the graph", faceB.Name)
 				panic("Couldn't render")
 			}
 			visitedFaces[faceB.Name] = struct{}{}
 		}
 
 		if connection.EdgeAID >= len(faceA.Shape.Edges) {
-			fmt.Printf("Face %s with %d faces doesn't have an edge number %d\n", connection.FaceA, len(faceA.Shape.Edges), connection.EdgeAID)
+			fmt.Fprintf(os.Stderr, "Face %s with %d faces doesn't have an edge number %d\n", connection.FaceA, len(faceA.Shape.Edges), connection.EdgeAID)
 			panic("Couldn't render")
 		}
 		if connection.EdgeBID >= len(faceB.Shape.Edges) {
-			fmt.Printf("Face %s with %d faces doesn't have an edge number %d\n", connection.FaceB, len(faceB.Shape.Edges), connection.EdgeBID)
+			fmt.Fprintf(os.Stderr, "Face %s with %d faces doesn't have an edge number %d\n", connection.FaceB, len(faceB.Shape.Edges), connection.EdgeBID)
 			panic("Couldn't render")
 		}
 		// check that the two edges are of the same length
 		aLen := faceA.Shape.Edges[connection.EdgeAID].Vector.Len()
 		bLen := faceB.Shape.Edges[connection.EdgeBID].Vector.Len()
 		if math.Abs(aLen-bLen) > MATH_PRECISION {
-			fmt.Printf("The connected edges %s:%d and %s:%d have different lengths (%.3f vs %.3f = diff of %.3f)\n",
+			fmt.Fprintf(os.Stderr, "The connected edges %s:%d and %s:%d have different lengths (%.3f vs %.3f = diff of %.3f)\n",
 				connection.FaceA, connection.EdgeAID,
 				connection.FaceB, connection.EdgeBID,
 				aLen, bLen, math.Abs(aLen-bLen),
@@ -217,18 +220,18 @@ func (c CutOut) computeTrees(container primitives.BBox) cutoutTrees {
 		}
 		for _, edge := range edges {
 			if connectionsCompleted[edge] {
-				fmt.Printf("Edge %s:%d is already connected elsewhere\n", edge.Face, edge.EdgeID)
+				fmt.Fprintf(os.Stderr, "Edge %s:%d is already connected elsewhere\n", edge.Face, edge.EdgeID)
 			}
 			connectionsCompleted[edge] = true
 		}
 	}
 	for edge, ok := range connectionsCompleted {
 		if !ok {
-			fmt.Printf("Edge %s:%d is not connected to anything\n", edge.Face, edge.EdgeID)
+			fmt.Fprintf(os.Stderr, "Edge %s:%d is not connected to anything\n", edge.Face, edge.EdgeID)
 		}
 	}
 	// for each face that has never appeared as faceB in a face connection, it is a root/head of its own tree
-	for name := range faceByID {
+	for _, name := range slices.Sorted(maps.Keys(faceByID)) {
 		if _, ok := visitedFaces[name]; !ok {
 			heads = append(heads, name)
 		}
@@ -257,7 +260,9 @@ func (c CutOut) GeneratePatterns(container primitives.BBox) []FoldablePattern {
 		annotations := []lines.LineLike{}
 		fills := map[string]BrushLines{}
 		minAnnotationSize := math.MaxFloat64
-		for key, polygon := range faceBundle.FacePolygons {
+		keys := slices.Sorted(maps.Keys(faceBundle.FacePolygons))
+		for _, key := range keys {
+			polygon := faceBundle.FacePolygons[key]
 			polygons = append(polygons, polygon)
 			bbox := polygon.LargestContainedSquareBBox()
 			bbox = bbox.WithPadding(100)
@@ -296,7 +301,8 @@ func (c CutOut) GeneratePatterns(container primitives.BBox) []FoldablePattern {
 			}
 		}
 		// redo it again with the min annotation size
-		for key, polygon := range faceBundle.FacePolygons {
+		for _, key := range keys {
+			polygon := faceBundle.FacePolygons[key]
 			bbox := polygon.LargestContainedSquareBBox()
 			bbox = bbox.WithPadding(100)
 			annotations = append(annotations, fonts.RenderText(bbox, key, fonts.WithSize(minAnnotationSize)).CharCurves...)
@@ -3,7 +3,8 @@ package foldable
 import (
 	"fmt"
 	"math"
-	"math/rand/v2"
+	"math/rand"
+	"os"
 
 	"github.com/libeks/go-plotter-svg/maths"
 	"github.com/libeks/go-plotter-svg/pen"
@@ -616,7 +617,7 @@ func CutCube(b primitives.BBox, side float64, cutRatio float64) []FoldablePatter
 	return c.Render(b)
 }
 
-func VoronoiFoldable(b primitives.BBox) []FoldablePattern {
+func VoronoiFoldable(r *rand.Rand, b primitives.BBox) []FoldablePattern {
 	printPoints := false
 	bbox := primitives.BBox{UpperLeft: primitives.Origin, LowerRight: primitives.Point{X: 5000, Y: 5000}}
 	edgeWidth := 400.0
@@ -681,19 +682,19 @@ func VoronoiFoldable(b primitives.BBox) []FoldablePattern {
 	nPoints := 7
 	points := make([]primitives.Point, nPoints)
 	if printPoints {
-		fmt.Printf("points := []primitives.Point{\n")
+		fmt.Fprintf(os.Stderr, "points := []primitives.Point{\n")
 	}
 	for i := range nPoints {
 		points[i] = primitives.Point{
-			X: rand.Float64()*4000 + 500.0,
-			Y: rand.Float64()*4000 + 500.0,
+			X: r.Float64()*4000 + 500.0,
+			Y: r.Float64()*4000 + 500.0,
 		}
 		if printPoints {
-			fmt.Printf("    {%.1f, %.1f},\n", points[i].X, points[i].Y)
+			fmt.Fprintf(os.Stderr, "    {%.1f, %.1f},\n", points[i].X, points[i].Y)
 		}
 	}
 	if printPoints {
-		fmt.Printf("}\n")
+		fmt.Fprintf(os.Stderr, "}\n")
 	}
 
 	vor := voronoi.ComputeVoronoiConnections(bbox, points)
@@ -717,7 +718,7 @@ func VoronoiFoldable(b primitives.BBox) []FoldablePattern {
 			edgesVisited[fmt.Sprintf("%s-%d", name, j)] = false
 		}
 	}
-	fmt.Printf("Min Edge is %.1f\n", minEdge)
+	fmt.Fprintf(os.Stderr, "Min Edge is %.1f\n", minEdge)
 	for _, conn := range vor.EdgeMap {
 		connections = append(connections, ConnectionID{
 			FaceA:          faceNames[conn.From.PolyIndex],
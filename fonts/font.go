@@ -3,6 +3,7 @@ package fonts
 import (
 	"io"
 	"os"
+	"runtime"
 
 	"github.com/golang/freetype/truetype"
 	"github.com/kintar/etxt/efixed"
@@ -13,6 +14,22 @@ const (
 	fontHeight = 100
 )
 
+// DefaultFile is the font that text is rendered in, unless WithFont says otherwise. It comes from the PLOTTER_FONT
+// environment variable, or else it's a font that comes with the operating system.
+var DefaultFile = defaultFile()
+
+func defaultFile() string {
+	if fname := os.Getenv("PLOTTER_FONT"); fname != "" {
+		return fname
+	}
+	if runtime.GOOS == "windows" {
+		return "C:/Windows/Fonts/seguihis.ttf"
+		// return "C:/Windows/Fonts/bahnschrift.ttf"
+	}
+	// MacOS
+	return "/System/Library/Fonts/Palatino.ttc"
+}
+
 type Font struct {
 	*truetype.Font
 }
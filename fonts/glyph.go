@@ -2,6 +2,7 @@ package fonts
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/golang/freetype/truetype"
 	"github.com/kintar/etxt/efixed"
@@ -134,7 +135,7 @@ func (g Glyph) GetPointRepr() [][]PointOnLine {
 		points := []PointOnLine{}
 		contour := optimizeContour(contour)
 		if len(contour) == 0 {
-			fmt.Printf("Encountered empty contour!")
+			fmt.Fprintf(os.Stderr, "Encountered empty contour!")
 			continue
 		}
 		for _, pt := range contour {
@@ -153,7 +154,7 @@ func (g Glyph) GetHeightCurves(h float64) Char {
 	for _, contour := range g.Contours() {
 		contour = optimizeContour(contour)
 		if len(contour) == 0 {
-			fmt.Printf("Encountered empty contour!")
+			fmt.Fprintf(os.Stderr, "Encountered empty contour!")
 			continue
 		}
 		// is point on line?
@@ -245,7 +246,7 @@ func (g Glyph) GetCurves(b primitives.BBox) []lines.LineLike {
 	for _, contour := range g.Contours() {
 		contour = optimizeContour(contour)
 		if len(contour) == 0 {
-			fmt.Printf("Encountered empty contour!")
+			fmt.Fprintf(os.Stderr, "Encountered empty contour!")
 			continue
 		}
 		// is point on line?
@@ -0,0 +1,124 @@
+package fonts
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/primitives"
+)
+
+// The stroke font draws each character with a few single strokes, for labels that have to be plotted without
+// a font file. Glyphs are drawn on a grid strokeWidth wide and strokeHeight tall, with the origin at the top
+// left and the baseline at the bottom. Lowercase letters are drawn as uppercase ones.
+const (
+	strokeWidth   = 4.0
+	strokeHeight  = 6.0
+	strokeAdvance = 6.0 // horizontal distance between the starts of consecutive characters
+)
+
+// strokeGlyphs holds the strokes of each character, as space-separated x,y points, with strokes separated by ";"
+var strokeGlyphs = map[rune]string{
+	'A': "0,6 0,2 2,0 4,2 4,6; 0,3 4,3",
+	'B': "0,6 0,0 3,0 4,1 4,2 3,3 0,3; 3,3 4,4 4,5 3,6 0,6",
+	'C': "4,1 3,0 1,0 0,1 0,5 1,6 3,6 4,5",
+	'D': "0,0 0,6 2,6 4,4 4,2 2,0 0,0",
+	'E': "4,0 0,0 0,6 4,6; 0,3 3,3",
+	'F': "4,0 0,0 0,6; 0,3 3,3",
+	'G': "4,1 3,0 1,0 0,1 0,5 1,6 3,6 4,5 4,3 2,3",
+	'H': "0,0 0,6; 4,0 4,6; 0,3 4,3",
+	'I': "1,0 3,0; 2,0 2,6; 1,6 3,6",
+	'J': "4,0 4,5 3,6 1,6 0,5",
+	'K': "0,0 0,6; 4,0 0,4; 1,3 4,6",
+	'L': "0,0 0,6 4,6",
+	'M': "0,6 0,0 2,3 4,0 4,6",
+	'N': "0,6 0,0 4,6 4,0",
+	'O': "1,0 3,0 4,1 4,5 3,6 1,6 0,5 0,1 1,0",
+	'P': "0,6 0,0 3,0 4,1 4,2 3,3 0,3",
+	'Q': "1,0 3,0 4,1 4,5 3,6 1,6 0,5 0,1 1,0; 2,4 4,6",
+	'R': "0,6 0,0 3,0 4,1 4,2 3,3 0,3; 2,3 4,6",
+	'S': "4,1 3,0 1,0 0,1 0,2 1,3 3,3 4,4 4,5 3,6 1,6 0,5",
+	'T': "0,0 4,0; 2,0 2,6",
+	'U': "0,0 0,5 1,6 3,6 4,5 4,0",
+	'V': "0,0 2,6 4,0",
+	'W': "0,0 1,6 2,3 3,6 4,0",
+	'X': "0,0 4,6; 4,0 0,6",
+	'Y': "0,0 2,3 4,0; 2,3 2,6",
+	'Z': "0,0 4,0 0,6 4,6",
+	'0': "1,0 3,0 4,1 4,5 3,6 1,6 0,5 0,1 1,0; 0,5 4,1",
+	'1': "1,1 2,0 2,6; 1,6 3,6",
+	'2': "0,1 1,0 3,0 4,1 4,2 0,6 4,6",
+	'3': "0,1 1,0 3,0 4,1 4,2 3,3 1,3; 3,3 4,4 4,5 3,6 1,6 0,5",
+	'4': "3,6 3,0 0,4 4,4",
+	'5': "4,0 0,0 0,3 3,3 4,4 4,5 3,6 1,6 0,5",
+	'6': "3,0 1,0 0,1 0,5 1,6 3,6 4,5 4,4 3,3 0,3",
+	'7': "0,0 4,0 1,6",
+	'8': "1,0 3,0 4,1 4,2 3,3 1,3 0,2 0,1 1,0; 1,3 0,4 0,5 1,6 3,6 4,5 4,4 3,3",
+	'9': "4,3 1,3 0,2 0,1 1,0 3,0 4,1 4,5 3,6 1,6",
+	'.': "1.75,6 2.25,6",
+	',': "2,5 1.5,7",
+	':': "2,1.75 2,2.25; 2,4.75 2,5.25",
+	'=': "0,2 4,2; 0,4 4,4",
+	'-': "0,3 4,3",
+	'+': "0,3 4,3; 2,1 2,5",
+	'_': "0,6 4,6",
+	'/': "0,6 4,0",
+	'(': "3,0 2,1 2,5 3,6",
+	')': "1,0 2,1 2,5 1,6",
+	'#': "1,0 1,6; 3,0 3,6; 0,2 4,2; 0,4 4,4",
+	'?': "0,1 1,0 3,0 4,1 4,2 2,3 2,4; 1.75,6 2.25,6",
+	' ': "",
+}
+
+// StrokeTextWidth is how wide StrokeText draws the text, when its capital letters are height tall
+func StrokeTextWidth(text string, height float64) float64 {
+	n := len([]rune(text))
+	if n == 0 {
+		return 0
+	}
+	return (float64(n-1)*strokeAdvance + strokeWidth) * height / strokeHeight
+}
+
+// StrokeText draws the text with single strokes, starting with its top left corner at origin, such that its
+// capital letters are height tall. Characters that the font doesn't have are drawn as '?'.
+func StrokeText(text string, origin primitives.Point, height float64) []lines.LineLike {
+	scale := height / strokeHeight
+	ret := []lines.LineLike{}
+	for i, r := range []rune(text) {
+		glyph, ok := strokeGlyphs[unicode.ToUpper(r)]
+		if !ok {
+			glyph = strokeGlyphs['?']
+		}
+		corner := origin.Add(primitives.Vector{X: float64(i) * strokeAdvance * scale})
+		for _, stroke := range strings.Split(glyph, ";") {
+			points := parseStroke(stroke)
+			if len(points) < 2 {
+				continue
+			}
+			path := lines.NewPath(corner.Add(points[0].Mult(scale)))
+			for j := 1; j < len(points); j++ {
+				path = path.AddPathChunk(lines.LineChunk{
+					Start: corner.Add(points[j-1].Mult(scale)),
+					End:   corner.Add(points[j].Mult(scale)),
+				})
+			}
+			ret = append(ret, path)
+		}
+	}
+	return ret
+}
+
+func parseStroke(stroke string) []primitives.Vector {
+	points := []primitives.Vector{}
+	for _, field := range strings.Fields(stroke) {
+		x, y, _ := strings.Cut(field, ",")
+		fx, errX := strconv.ParseFloat(x, 64)
+		fy, errY := strconv.ParseFloat(y, 64)
+		if errX != nil || errY != nil {
+			panic("bad point '" + field + "' in the stroke font")
+		}
+		points = append(points, primitives.Vector{X: fx, Y: fy})
+	}
+	return points
+}
@@ -2,7 +2,7 @@ package fonts
 
 import (
 	"fmt"
-	"runtime"
+	"os"
 
 	"github.com/libeks/go-plotter-svg/lines"
 	"github.com/libeks/go-plotter-svg/primitives"
@@ -91,16 +91,8 @@ func WithFitToBox() textOption {
 // RenderText renders the specified text, with the specified options, in the middle of the bounding box
 // The text may span outside of the bounding box, unless WithFitToBox is used
 func RenderText(b primitives.BBox, text string, textOptions ...textOption) TextRender {
-	var fontFile string
-	if runtime.GOOS == "windows" {
-		fontFile = "C:/Windows/Fonts/seguihis.ttf"
-		// fontFile="C:/Windows/Fonts/bahnschrift.ttf"
-	} else {
-		// MacOS
-		fontFile = "/System/Library/Fonts/Palatino.ttc"
-	}
 	o := option{
-		fontFile: fontFile,
+		fontFile: DefaultFile,
 		size:     1000.0,
 	}
 	for _, opt := range textOptions {
@@ -157,6 +149,6 @@ func RenderText(b primitives.BBox, text string, textOptions ...textOption) TextR
 		}
 		size = size / max(hRatio, vRatio)
 	}
-	fmt.Printf("Box is too small to render text\n")
+	fmt.Fprintf(os.Stderr, "Box is too small to render text\n")
 	return TextRender{}
 }
@@ -0,0 +1,208 @@
+package gcode
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/primitives"
+	"github.com/libeks/go-plotter-svg/scenes"
+	"github.com/libeks/go-plotter-svg/units"
+)
+
+// Config describes how to drive a GRBL-style pen plotter
+type Config struct {
+	PenUp              string  // command that lifts the pen, e.g. a servo position "M3 S30"
+	PenDown            string  // command that lowers the pen, e.g. "M3 S90"
+	PenDelay           float64 // seconds to dwell after each pen up or down command, to let the servo settle
+	FeedRate           float64 // drawing speed, in mm/min
+	TravelRate         float64 // pen-up speed in mm/min. If zero, travel moves use rapid G0 moves
+	PauseBetweenLayers bool    // emit an M0 pause before each layer after the first one, e.g. to swap pens
+	Arcs               bool    // emit circle arcs as G2/G3 moves instead of flattening them
+	InvertY            bool    // negate the y-axis, for machines whose y-axis points away from the operator
+	Tolerance          float64 // maximum deviation in mm when flattening curves into line segments
+	UnitsPerMM         float64 // how many internal units fit in a millimeter
+}
+
+func DefaultConfig() Config {
+	return Config{
+		PenUp:              "M3 S30",
+		PenDown:            "M3 S90",
+		PenDelay:           0.15,
+		FeedRate:           2000,
+		PauseBetweenLayers: true,
+		Arcs:               true,
+		Tolerance:          0.05,
+		UnitsPerMM:         units.Millimeter,
+	}
+}
+
+type GCode struct {
+	Fname string
+	Config
+	scenes.Document
+}
+
+// WriteGCode writes each page of the document to its own file, named as in Document.PageFileNames
+func (g GCode) WriteGCode() error {
+	for i, fname := range g.Document.PageFileNames(g.Fname) {
+		if err := g.writePageFile(fname, g.Document.Page(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g GCode) writePageFile(fname string, page scenes.Page) error {
+	fmt.Printf("trying to open file %s\n", fname)
+	f, err := os.OpenFile(fname, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := g.WritePage(f, page); err != nil {
+		return err
+	}
+	fmt.Printf("Finished rendering to file %s\n", fname)
+	return nil
+}
+
+// WritePage writes the G-code program for a single page
+func (g GCode) WritePage(w io.Writer, page scenes.Page) error {
+	p := &program{
+		Config: g.Config,
+		w:      bufio.NewWriter(w),
+	}
+	p.line("; generated by go-plotter-svg")
+	p.line("G21 ; millimeters")
+	p.line("G90 ; absolute positioning")
+	p.liftPen() // the pen state is unknown at the start, so make sure it is up
+	for i, layer := range page.GetLayers() {
+		p.line("")
+		p.line(fmt.Sprintf("; layer %d - %s", i, layer.Name()))
+		if i > 0 && g.PauseBetweenLayers {
+			p.liftPen()
+			p.line(fmt.Sprintf("M0 ; pause before layer '%s'", layer.Name()))
+		}
+		offset := layer.Offset()
+		for _, linelike := range layer.LineLikes() {
+			if linelike == nil || linelike.IsEmpty() {
+				continue
+			}
+			p.drawPath(lines.ToPath(linelike.Translate(offset)))
+		}
+	}
+	p.line("")
+	p.liftPen()
+	p.line("G0 X0 Y0")
+	return p.w.Flush()
+}
+
+// program tracks the state of the plotter while the G-code is being written
+type program struct {
+	Config
+	w        *bufio.Writer
+	position primitives.Point
+	penUp    bool
+	feed     float64
+}
+
+func (p *program) line(s string) {
+	p.w.WriteString(s)
+	p.w.WriteString("\n")
+}
+
+func (p *program) coords(pt primitives.Point) string {
+	x := pt.X / p.UnitsPerMM
+	y := pt.Y / p.UnitsPerMM
+	if p.InvertY {
+		y = -y
+	}
+	return fmt.Sprintf("X%.3f Y%.3f", x, y)
+}
+
+func (p *program) feedRate(f float64) string {
+	if f == p.feed {
+		return ""
+	}
+	p.feed = f
+	return fmt.Sprintf(" F%.0f", f)
+}
+
+func (p *program) dwell() {
+	if p.PenDelay > 0 {
+		p.line(fmt.Sprintf("G4 P%.2f", p.PenDelay))
+	}
+}
+
+func (p *program) liftPen() {
+	if p.penUp {
+		return
+	}
+	p.line(p.PenUp)
+	p.dwell()
+	p.penUp = true
+}
+
+func (p *program) lowerPen() {
+	if !p.penUp {
+		return
+	}
+	p.line(p.PenDown)
+	p.dwell()
+	p.penUp = false
+}
+
+func (p *program) travelTo(pt primitives.Point) {
+	if p.TravelRate > 0 {
+		p.line(fmt.Sprintf("G1 %s%s", p.coords(pt), p.feedRate(p.TravelRate)))
+	} else {
+		p.line(fmt.Sprintf("G0 %s", p.coords(pt)))
+	}
+	p.position = pt
+}
+
+func (p *program) drawTo(pt primitives.Point) {
+	p.line(fmt.Sprintf("G1 %s%s", p.coords(pt), p.feedRate(p.FeedRate)))
+	p.position = pt
+}
+
+func (p *program) drawPath(path lines.Path) {
+	tolerance := p.Tolerance * p.UnitsPerMM
+	// keep the pen down if this path continues where the previous one ended
+	if p.penUp || path.Start().Subtract(p.position).Len() > tolerance {
+		p.liftPen()
+		p.travelTo(path.Start())
+	}
+	p.lowerPen()
+	for _, chunk := range path.Chunks() {
+		if arc, ok := chunk.(lines.ArcChunk); ok && p.Arcs {
+			p.drawArc(arc)
+			continue
+		}
+		for _, pt := range lines.FlattenChunk(chunk, tolerance) {
+			p.drawTo(pt)
+		}
+	}
+}
+
+func (p *program) drawArc(arc lines.ArcChunk) {
+	// a positive sweep increases the angle, which is counter-clockwise in a y-up coordinate system
+	counterClockwise := arc.Sweep() > 0
+	if p.InvertY {
+		counterClockwise = !counterClockwise
+	}
+	command := "G2"
+	if counterClockwise {
+		command = "G3"
+	}
+	offset := arc.Center().Subtract(p.position).Mult(1 / p.UnitsPerMM)
+	if p.InvertY {
+		offset.Y = -offset.Y
+	}
+	end := arc.Endpoint()
+	p.line(fmt.Sprintf("%s %s I%.3f J%.3f%s", command, p.coords(end), offset.X, offset.Y, p.feedRate(p.FeedRate)))
+	p.position = end
+}
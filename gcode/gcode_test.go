@@ -0,0 +1,160 @@
+package gcode
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/primitives"
+	"github.com/libeks/go-plotter-svg/scenes"
+)
+
+var testConfig = Config{
+	PenUp:      "M3 S30",
+	PenDown:    "M3 S90",
+	PenDelay:   0.1,
+	FeedRate:   1500,
+	Arcs:       true,
+	Tolerance:  0.1,
+	UnitsPerMM: 10,
+}
+
+// quarterArc goes a quarter of the way around the origin, from (100, 100) to (-100, 100) when the angle increases,
+// and the long way around otherwise
+func quarterArc(increasing bool) lines.PathChunk {
+	return lines.CircleArcChunk(primitives.Origin, 100*math.Sqrt2, math.Pi/4, 3*math.Pi/4, increasing)
+}
+
+func writePage(t *testing.T, config Config, lineLikes ...lines.LineLike) []string {
+	t.Helper()
+	page := scenes.Page{}.AddLayer(scenes.NewLayer("test").WithLineLike(lineLikes))
+	b := &strings.Builder{}
+	if err := (GCode{Config: config}).WritePage(b, page); err != nil {
+		t.Fatalf("WritePage() returned error %v", err)
+	}
+	return strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+}
+
+func TestArcDirection(t *testing.T) {
+	tests := []struct {
+		name       string
+		increasing bool
+		invertY    bool
+		want       string
+	}{
+		// the angle increases counter-clockwise in a y-up coordinate system, which flipping the y-axis reverses
+		{"increasing angle", true, false, "G3 X-10.000 Y10.000 I-10.000 J-10.000 F1500"},
+		{"decreasing angle", false, false, "G2 X-10.000 Y10.000 I-10.000 J-10.000 F1500"},
+		{"increasing angle, inverted y", true, true, "G2 X-10.000 Y-10.000 I-10.000 J10.000 F1500"},
+		{"decreasing angle, inverted y", false, true, "G3 X-10.000 Y-10.000 I-10.000 J10.000 F1500"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig
+			config.InvertY = tt.invertY
+			arc := quarterArc(tt.increasing)
+			program := writePage(t, config, lines.NewPath(arc.Startpoint()).AddPathChunk(arc))
+			got := []string{}
+			for _, line := range program {
+				if strings.HasPrefix(line, "G2 ") || strings.HasPrefix(line, "G3 ") {
+					got = append(got, line)
+				}
+			}
+			if diff := cmp.Diff([]string{tt.want}, got); diff != "" {
+				t.Errorf("Unexpected diff %v", diff)
+			}
+		})
+	}
+}
+
+func TestWritePage(t *testing.T) {
+	arc := quarterArc(true)
+	path := lines.NewPath(primitives.Origin).
+		AddPathChunk(lines.LineChunk{Start: primitives.Origin, End: arc.Startpoint()}).
+		AddPathChunk(arc).
+		AddPathChunk(lines.LineChunk{Start: arc.Endpoint(), End: primitives.Point{X: -100, Y: 0}})
+	tests := []struct {
+		name   string
+		config func(Config) Config
+		want   []string
+	}{
+		{
+			name:   "arcs",
+			config: func(c Config) Config { return c },
+			want: []string{
+				"G0 X0.000 Y0.000",
+				"M3 S90",
+				"G4 P0.10",
+				"G1 X10.000 Y10.000 F1500",
+				"G3 X-10.000 Y10.000 I-10.000 J-10.000",
+				"G1 X-10.000 Y0.000",
+			},
+		},
+		{
+			name: "travel rate",
+			config: func(c Config) Config {
+				c.TravelRate = 3000
+				return c
+			},
+			want: []string{
+				"G1 X0.000 Y0.000 F3000",
+				"M3 S90",
+				"G4 P0.10",
+				"G1 X10.000 Y10.000 F1500",
+				"G3 X-10.000 Y10.000 I-10.000 J-10.000",
+				"G1 X-10.000 Y0.000",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := writePage(t, tt.config(testConfig), path)
+			want := append([]string{
+				"; generated by go-plotter-svg",
+				"G21 ; millimeters",
+				"G90 ; absolute positioning",
+				"M3 S30",
+				"G4 P0.10",
+				"",
+				"; layer 0 - test",
+			}, tt.want...)
+			want = append(want, "", "M3 S30", "G4 P0.10", "G0 X0 Y0")
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("Unexpected diff %v", diff)
+			}
+		})
+	}
+}
+
+func TestFlattenArcs(t *testing.T) {
+	config := testConfig
+	config.Arcs = false
+	arc := quarterArc(true)
+	program := writePage(t, config, lines.NewPath(arc.Startpoint()).AddPathChunk(arc))
+	// the moves start at the start of the arc, in millimeters
+	points := []primitives.Point{{X: 10, Y: 10}}
+	for _, line := range program {
+		if strings.HasPrefix(line, "G2 ") || strings.HasPrefix(line, "G3 ") {
+			t.Errorf("got arc move %q with Arcs off", line)
+		}
+		pt := primitives.Point{}
+		if _, err := fmt.Sscanf(line, "G1 X%f Y%f", &pt.X, &pt.Y); err == nil {
+			points = append(points, pt)
+		}
+	}
+	if diff := cmp.Diff(primitives.Point{X: -10, Y: 10}, points[len(points)-1]); diff != "" {
+		t.Errorf("the moves don't end where the arc does: %v", diff)
+	}
+	radius := 10 * math.Sqrt2
+	for i := 1; i < len(points); i++ {
+		// how far the middle of the move is from the arc
+		chord := points[i].Subtract(points[i-1]).Len()
+		if sagitta := radius - math.Sqrt(radius*radius-chord*chord/4); sagitta > config.Tolerance {
+			t.Errorf("move %d strays %.3fmm from the arc, more than the tolerance of %.3fmm", i, sagitta, config.Tolerance)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package hpgl
+
+import (
+	"fmt"
+
+	"github.com/libeks/go-plotter-svg/pen"
+	"github.com/libeks/go-plotter-svg/scenes"
+)
+
+// Carousel maps layers to the pen slots of the plotter. A layer is matched by the name of its pen first,
+// then by its color. Layers that match neither get the next free slot, shared by all layers with the same
+// pen and color.
+type Carousel struct {
+	Pens     map[string]int // pen name to slot
+	Colors   map[string]int // layer color to slot
+	assigned map[string]int
+}
+
+func NewCarousel() Carousel {
+	return Carousel{
+		Pens:     map[string]int{},
+		Colors:   map[string]int{},
+		assigned: map[string]int{},
+	}
+}
+
+func (c Carousel) WithPen(p pen.Pen, slot int) Carousel {
+	c = c.clone()
+	c.Pens[p.Name] = slot
+	return c
+}
+
+func (c Carousel) WithColor(color string, slot int) Carousel {
+	c = c.clone()
+	c.Colors[color] = slot
+	return c
+}
+
+func (c Carousel) clone() Carousel {
+	ret := NewCarousel()
+	for name, slot := range c.Pens {
+		ret.Pens[name] = slot
+	}
+	for color, slot := range c.Colors {
+		ret.Colors[color] = slot
+	}
+	for key, slot := range c.assigned {
+		ret.assigned[key] = slot
+	}
+	return ret
+}
+
+// layerColor is the color the layer is drawn with in the SVG output
+func layerColor(layer scenes.Layer) string {
+	if layer.Color() == "" {
+		return "black"
+	}
+	return layer.Color()
+}
+
+// Slot returns the carousel slot that the layer should be drawn with. Layers with an unknown pen and color
+// are assigned the lowest free slot, which is remembered for later layers. The Carousel must have been
+// created with NewCarousel.
+func (c Carousel) Slot(layer scenes.Layer, slots int) (int, error) {
+	penName := layer.Pen().Name
+	if slot, ok := c.Pens[penName]; ok && penName != "" {
+		return slot, nil
+	}
+	color := layerColor(layer)
+	if slot, ok := c.Colors[color]; ok {
+		return slot, nil
+	}
+	key := fmt.Sprintf("%s/%s", penName, color)
+	if slot, ok := c.assigned[key]; ok {
+		return slot, nil
+	}
+	used := map[int]bool{}
+	for _, slots := range []map[string]int{c.Pens, c.Colors, c.assigned} {
+		for _, slot := range slots {
+			used[slot] = true
+		}
+	}
+	for slot := 1; slot <= slots; slot++ {
+		if !used[slot] {
+			c.assigned[key] = slot
+			return slot, nil
+		}
+	}
+	return 0, fmt.Errorf("no free pen slot for layer '%s', the carousel only has %d slots", layer.Name(), slots)
+}
@@ -0,0 +1,38 @@
+package hpgl
+
+import (
+	"testing"
+
+	"github.com/libeks/go-plotter-svg/pen"
+	"github.com/libeks/go-plotter-svg/scenes"
+)
+
+func TestSlot(t *testing.T) {
+	carousel := NewCarousel().WithPen(pen.Micron005, 3).WithColor("red", 2)
+	// the layers get their slots in turn, so the free slots fill up
+	tests := []struct {
+		name    string
+		layer   scenes.Layer
+		want    int
+		wantErr bool
+	}{
+		{name: "pen before color", layer: scenes.NewLayer("a").WithPen(pen.Micron005).WithColor("red"), want: 3},
+		{name: "color", layer: scenes.NewLayer("b").WithPen(pen.Micron01).WithColor("red"), want: 2},
+		{name: "first free slot", layer: scenes.NewLayer("c").WithColor("blue"), want: 1},
+		{name: "same pen and color", layer: scenes.NewLayer("d").WithColor("blue"), want: 1},
+		{name: "next free slot", layer: scenes.NewLayer("e").WithPen(pen.Micron01).WithColor("blue"), want: 4},
+		{name: "full carousel", layer: scenes.NewLayer("f"), wantErr: true},
+		{name: "full carousel, known color", layer: scenes.NewLayer("g").WithColor("red"), want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := carousel.Slot(tt.layer, 4)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Slot() returned error %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got slot %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package hpgl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/primitives"
+	"github.com/libeks/go-plotter-svg/scenes"
+	"github.com/libeks/go-plotter-svg/units"
+)
+
+// the HP 7475A only has a 1kB input buffer, so keep individual PD commands short
+const maxPointsPerCommand = 32
+
+type Config struct {
+	Plotter
+	Carousel   Carousel
+	Velocity   float64 // pen speed in cm/s, sent with VS. If zero, the plotter's default is used
+	Arcs       bool    // emit circle arcs as AA commands instead of flattening them
+	Tolerance  float64 // maximum deviation in mm when flattening curves into line segments
+	UnitsPerMM float64 // how many internal units fit in a millimeter
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Plotter:    HP7475A_A3,
+		Carousel:   NewCarousel(),
+		Arcs:       true,
+		Tolerance:  0.1,
+		UnitsPerMM: units.Millimeter,
+	}
+}
+
+type HPGL struct {
+	Fname string
+	Config
+	scenes.Document
+}
+
+// WriteHPGL writes each page of the document to its own file, named as in Document.PageFileNames.
+// The pen slots are assigned once for the whole document, so that a color keeps its slot across pages.
+func (h HPGL) WriteHPGL() error {
+	h.Carousel = h.Carousel.clone()
+	for i, fname := range h.Document.PageFileNames(h.Fname) {
+		if err := h.writePageFile(fname, h.Document.Page(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h HPGL) writePageFile(fname string, page scenes.Page) error {
+	fmt.Printf("trying to open file %s\n", fname)
+	f, err := os.OpenFile(fname, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := h.WritePage(f, page); err != nil {
+		return err
+	}
+	fmt.Printf("Finished rendering to file %s\n", fname)
+	return nil
+}
+
+// WritePage writes the HPGL program for a single page. The upper left corner of the page is placed in the upper
+// left corner of the plotter's drawing area. An error is returned if any line falls outside of that area.
+func (h HPGL) WritePage(w io.Writer, page scenes.Page) error {
+	carousel := h.Carousel
+	if carousel.assigned == nil {
+		carousel = carousel.clone()
+	}
+	p := &program{
+		Config: h.Config,
+		w:      bufio.NewWriter(w),
+	}
+	p.command("IN")
+	p.command("PA")
+	if h.Velocity > 0 {
+		p.command(fmt.Sprintf("VS%.0f", h.Velocity))
+	}
+	currentSlot := 0
+	for _, layer := range page.GetLayers() {
+		if len(layer.LineLikes()) == 0 {
+			continue
+		}
+		slot, err := carousel.Slot(layer, h.Slots)
+		if err != nil {
+			return err
+		}
+		if slot != currentSlot {
+			p.penUp()
+			p.command(fmt.Sprintf("SP%d", slot))
+			currentSlot = slot
+		}
+		offset := layer.Offset()
+		for _, linelike := range layer.LineLikes() {
+			if linelike == nil || linelike.IsEmpty() {
+				continue
+			}
+			if err := p.drawPath(lines.ToPath(linelike.Translate(offset))); err != nil {
+				return fmt.Errorf("layer '%s': %w", layer.Name(), err)
+			}
+		}
+	}
+	p.penUp()
+	p.command("SP0") // put the pen back into the carousel
+	return p.w.Flush()
+}
+
+// program tracks the state of the plotter while the HPGL is being written
+type program struct {
+	Config
+	w        *bufio.Writer
+	position primitives.Point
+	down     bool
+}
+
+func (p *program) command(s string) {
+	p.w.WriteString(s)
+	p.w.WriteString(";\n")
+}
+
+// plotterCoords converts a point in image space into plotter units, whose y-axis points up
+func (p *program) plotterCoords(pt primitives.Point) (int, int) {
+	x := pt.X / p.UnitsPerMM * PlotterUnitsPerMM
+	y := p.Height - pt.Y/p.UnitsPerMM*PlotterUnitsPerMM
+	return int(math.Round(x)), int(math.Round(y))
+}
+
+// toPlotter is like plotterCoords, but fails for points outside of the plotter's drawing area
+func (p *program) toPlotter(pt primitives.Point) (int, int, error) {
+	x, y := p.plotterCoords(pt)
+	if x < 0 || float64(x) > p.Width || y < 0 || float64(y) > p.Height {
+		return 0, 0, fmt.Errorf("point %s is outside the drawing area of the %s", pt, p.Name)
+	}
+	return x, y, nil
+}
+
+func (p *program) penUp() {
+	if !p.down {
+		return
+	}
+	p.command("PU")
+	p.down = false
+}
+
+func (p *program) drawPath(path lines.Path) error {
+	x, y, err := p.toPlotter(path.Start())
+	if err != nil {
+		return err
+	}
+	tolerance := p.Tolerance * p.UnitsPerMM
+	// keep the pen down if this path continues where the previous one ended
+	if !p.down || path.Start().Subtract(p.position).Len() > tolerance {
+		p.command(fmt.Sprintf("PU%d,%d", x, y))
+		p.down = false
+	}
+	p.position = path.Start()
+	points := []string{}
+	flush := func() {
+		if len(points) > 0 {
+			p.command(fmt.Sprintf("PD%s", strings.Join(points, ",")))
+			p.down = true
+			points = points[:0]
+		}
+	}
+	for _, chunk := range path.Chunks() {
+		if arc, ok := chunk.(lines.ArcChunk); ok && p.Arcs {
+			flush()
+			if err := p.drawArc(arc); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, pt := range lines.FlattenChunk(chunk, tolerance) {
+			x, y, err := p.toPlotter(pt)
+			if err != nil {
+				return err
+			}
+			points = append(points, fmt.Sprintf("%d,%d", x, y))
+			if len(points) == maxPointsPerCommand {
+				flush()
+			}
+			p.position = pt
+		}
+	}
+	flush()
+	return nil
+}
+
+func (p *program) drawArc(arc lines.ArcChunk) error {
+	if !p.down {
+		p.command("PD")
+		p.down = true
+	}
+	// the center of an arc may lie off the paper even when the arc itself doesn't
+	x, y := p.plotterCoords(arc.Center())
+	if _, _, err := p.toPlotter(arc.Endpoint()); err != nil {
+		return err
+	}
+	// AA angles are counter-clockwise in plotter space, where the y-axis is flipped relative to image space
+	sweep := -arc.Sweep() * 180 / math.Pi
+	// the chord angle controls how finely the plotter approximates the arc, derived from the tolerance
+	chord := 5.0
+	if ratio := p.Tolerance * p.UnitsPerMM / arc.Radius(); ratio < 1 {
+		chord = max(min(2*math.Acos(1-ratio)*180/math.Pi, 5), 0.5)
+	}
+	p.command(fmt.Sprintf("AA%d,%d,%.2f,%.1f", x, y, sweep, chord))
+	p.position = arc.Endpoint()
+	return nil
+}
@@ -0,0 +1,73 @@
+package hpgl
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/primitives"
+	"github.com/libeks/go-plotter-svg/scenes"
+)
+
+// testConfig draws on a 100x100mm plotter, with 10 internal units to the millimeter
+var testConfig = Config{
+	Plotter:    Plotter{Name: "test plotter", Width: 4000, Height: 4000, Slots: 2},
+	Carousel:   NewCarousel(),
+	Arcs:       true,
+	Tolerance:  0.1,
+	UnitsPerMM: 10,
+}
+
+func writePage(t *testing.T, config Config, lineLikes ...lines.LineLike) []string {
+	t.Helper()
+	page := scenes.Page{}.AddLayer(scenes.NewLayer("test").WithLineLike(lineLikes))
+	b := &strings.Builder{}
+	if err := (HPGL{Config: config}).WritePage(b, page); err != nil {
+		t.Fatalf("WritePage() returned error %v", err)
+	}
+	return strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+}
+
+func TestWritePage(t *testing.T) {
+	// the y-axis of the plotter points up from the bottom of the paper, so the top of the image is at the top of
+	// the plotter's drawing area
+	line := lines.LineSegment{P1: primitives.Point{X: 0, Y: 0}, P2: primitives.Point{X: 100, Y: 50}}
+	want := []string{"IN;", "PA;", "SP1;", "PU0,4000;", "PD400,3800;", "PU;", "SP0;"}
+	if diff := cmp.Diff(want, writePage(t, testConfig, line)); diff != "" {
+		t.Errorf("Unexpected diff %v", diff)
+	}
+}
+
+func TestArcSweep(t *testing.T) {
+	tests := []struct {
+		name       string
+		increasing bool
+		want       string
+	}{
+		// an increasing angle turns clockwise on the screen, and so clockwise on the plotter too, where AA
+		// takes counter-clockwise angles
+		{"increasing angle", true, "AA2000,2000,-90.00,5.0;"},
+		{"decreasing angle", false, "AA2000,2000,270.00,5.0;"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			arc := lines.CircleArcChunk(primitives.Point{X: 500, Y: 500}, 100, 0, math.Pi/2, tt.increasing)
+			got := writePage(t, testConfig, lines.NewPath(arc.Startpoint()).AddPathChunk(arc))
+			want := []string{"IN;", "PA;", "SP1;", "PU2400,2000;", "PD;", tt.want, "PU;", "SP0;"}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("Unexpected diff %v", diff)
+			}
+		})
+	}
+}
+
+func TestOutsideDrawingArea(t *testing.T) {
+	line := lines.LineSegment{P1: primitives.Point{X: 0, Y: 0}, P2: primitives.Point{X: 1100, Y: 0}}
+	page := scenes.Page{}.AddLayer(scenes.NewLayer("test").WithLineLike([]lines.LineLike{line}))
+	if err := (HPGL{Config: testConfig}).WritePage(&strings.Builder{}, page); err == nil {
+		t.Errorf("WritePage() returned no error for a line off the edge of the plotter")
+	}
+}
@@ -0,0 +1,22 @@
+package hpgl
+
+// HPGL plotters address their paper in plotter units, 40 of which make a millimeter
+const PlotterUnitsPerMM = 40.0
+
+// Plotter describes the hard-clip limits of a plotter for a given paper size, in plotter units,
+// with the origin in the lower left corner of the paper
+type Plotter struct {
+	Name   string
+	Width  float64
+	Height float64
+	Slots  int // number of pens in the carousel
+}
+
+var (
+	HP7475A_A4     = Plotter{"HP 7475A, A4", 10900, 7650, 6}
+	HP7475A_A3     = Plotter{"HP 7475A, A3", 16158, 11040, 6}
+	HP7475A_Letter = Plotter{"HP 7475A, US Letter", 10365, 7962, 6}
+	HP7475A_B      = Plotter{"HP 7475A, US Tabloid", 16640, 10365, 6}
+
+	RolandDXY1300_A3 = Plotter{"Roland DXY-1300, A3", 16640, 11040, 8}
+)
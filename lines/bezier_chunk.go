@@ -2,6 +2,7 @@ package lines
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/libeks/go-plotter-svg/primitives"
 )
@@ -145,7 +146,7 @@ func (c CubicBezierChunk) Startpoint() primitives.Point {
 
 func (c CubicBezierChunk) OffsetLeft(distance float64) PathChunk {
 	// TODO: actually implement
-	fmt.Printf("%s\n", c)
+	fmt.Fprintf(os.Stderr, "%s\n", c)
 	panic("unimplemented")
 }
 
@@ -1,6 +1,9 @@
 package lines
 
-import "fmt"
+import (
+	"fmt"
+	"os"
+)
 
 func getLengthEstimate(o LengthEstimator, segments int) float64 {
 	distance := 0.0
@@ -17,7 +20,7 @@ func getLengthEstimate(o LengthEstimator, segments int) float64 {
 
 func estimateLength(o LengthEstimator, acc float64) float64 {
 	if o.BBox().IsEmpty() {
-		fmt.Printf("chunk %s has empty bbox %s\n", o, o.BBox())
+		fmt.Fprintf(os.Stderr, "chunk %s has empty bbox %s\n", o, o.BBox())
 		return 0
 	}
 	oldLen := 0.0
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"math"
 
-	"github.com/libeks/go-plotter-svg/maths"
 	"github.com/libeks/go-plotter-svg/primitives"
 )
 
@@ -30,6 +29,37 @@ func (c circleArcChunk) String() string {
 	return fmt.Sprintf("CircleArcChunk: center %s, radius %.1f with start %.1f, end %.1f", c.center, c.radius, c.startRad, c.endRad)
 }
 
+func (c circleArcChunk) Center() primitives.Point {
+	return c.center
+}
+
+func (c circleArcChunk) Radius() float64 {
+	return c.radius
+}
+
+// StartAngle is the angle of the startpoint around the center, in radians
+func (c circleArcChunk) StartAngle() float64 {
+	return c.startRad
+}
+
+// EndAngle is the angle of the endpoint around the center, in radians
+func (c circleArcChunk) EndAngle() float64 {
+	return c.endRad
+}
+
+func (c circleArcChunk) IsClockwise() bool {
+	return c.isClockwise
+}
+
+// Sweep returns the signed angle traversed from the startpoint to the endpoint. It is positive when the angle
+// increases along the arc, which is how the SVG sweep flag is interpreted (clockwise on screen, since y points down)
+func (c circleArcChunk) Sweep() float64 {
+	if c.isClockwise {
+		return c.Angle()
+	}
+	return -c.Angle()
+}
+
 func (c circleArcChunk) IsLong() bool {
 	angle := c.Angle()
 	return angle > math.Pi || angle < -math.Pi
@@ -84,14 +114,17 @@ func (c circleArcChunk) ControlLines() string {
 	return fmt.Sprintf("L %.1f %.1f", end.X, end.Y)
 }
 
-func (c circleArcChunk) At(t float64) primitives.Point {
-	var angle float64
+// angleAt is the angle of the point that is t of the way along the arc, going from the startpoint towards
+// the endpoint in the direction of the arc
+func (c circleArcChunk) angleAt(t float64) float64 {
 	if c.isClockwise {
-		angle = maths.Interpolate(c.endRad, c.startRad, t)
-	} else {
-		angle = maths.Interpolate(c.startRad, c.endRad+math.Pi*2, t)
+		return c.startRad + c.Angle()*t
 	}
-	return c.center.Add(primitives.UnitRight.RotateCCW(angle).Mult(c.radius))
+	return c.startRad - c.Angle()*t
+}
+
+func (c circleArcChunk) At(t float64) primitives.Point {
+	return c.center.Add(primitives.UnitRight.RotateCCW(c.angleAt(t)).Mult(c.radius))
 }
 
 func (c circleArcChunk) OffsetLeft(distance float64) PathChunk {
@@ -125,12 +158,7 @@ func (c circleArcChunk) Reverse() PathChunk {
 }
 
 func (c circleArcChunk) Bisect(t float64) (PathChunk, PathChunk) {
-	var midT float64
-	if c.isClockwise {
-		midT = maths.Interpolate(c.startRad, c.endRad, t)
-	} else {
-		midT = maths.Interpolate(c.endRad, c.startRad, t)
-	}
+	midT := c.angleAt(t)
 	return circleArcChunk{
 			radius:      c.radius,
 			center:      c.center,
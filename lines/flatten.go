@@ -0,0 +1,111 @@
+package lines
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/libeks/go-plotter-svg/primitives"
+)
+
+const (
+	maxFlattenDepth    = 16
+	maxFlattenSegments = 1000
+)
+
+// ToPath expresses any LineLike as a Path, so that its PathChunks can be inspected or combined with those of
+// other LineLikes. Every LineLike other than Path has to implement Pather.
+func ToPath(l LineLike) Path {
+	switch v := l.(type) {
+	case Path:
+		return v
+	case Pather:
+		return v.Path()
+	}
+	panic(fmt.Errorf("don't know how to express %s as a path, it doesn't implement Pather", l))
+}
+
+// Flatten approximates the LineLike with a polyline, such that no point on the curve is further than
+// tolerance away from the polyline. The returned points include the start point.
+func Flatten(l LineLike, tolerance float64) []primitives.Point {
+	path := ToPath(l)
+	points := []primitives.Point{path.Start()}
+	for _, chunk := range path.Chunks() {
+		points = append(points, FlattenChunk(chunk, tolerance)...)
+	}
+	return points
+}
+
+// FlattenChunk approximates a single chunk with a polyline, within tolerance. The startpoint of the chunk is
+// not included, since it is expected to be the endpoint of the previous chunk.
+func FlattenChunk(c PathChunk, tolerance float64) []primitives.Point {
+	switch chunk := c.(type) {
+	case LineChunk:
+		return []primitives.Point{chunk.End}
+	case ArcChunk:
+		return flattenArc(chunk, tolerance)
+	case QuadraticBezierChunk:
+		return flattenBezier(chunk, tolerance, 0)
+	case CubicBezierChunk:
+		return flattenBezier(chunk, tolerance, 0)
+	}
+	// unknown chunk, fall back on sampling it uniformly
+	n := int(math.Ceil(math.Sqrt(c.Length() / tolerance)))
+	n = min(max(n, 1), maxFlattenSegments)
+	points := make([]primitives.Point, n)
+	for i := range n {
+		points[i] = c.At(float64(i+1) / float64(n))
+	}
+	points[n-1] = c.Endpoint()
+	return points
+}
+
+func flattenArc(c ArcChunk, tolerance float64) []primitives.Point {
+	sweep := c.Sweep()
+	// the largest angle step whose chord deviates from the arc by at most tolerance
+	step := math.Pi / 4
+	if tolerance < c.Radius() {
+		step = min(step, 2*math.Acos(1-tolerance/c.Radius()))
+	}
+	n := int(math.Ceil(math.Abs(sweep) / step))
+	n = min(max(n, 1), maxFlattenSegments)
+	points := make([]primitives.Point, n)
+	for i := range n {
+		angle := c.StartAngle() + sweep*float64(i+1)/float64(n)
+		points[i] = c.Center().Add(primitives.UnitRight.RotateCCW(angle).Mult(c.Radius()))
+	}
+	points[n-1] = c.Endpoint()
+	return points
+}
+
+// flattenBezier recursively bisects the curve until its control points are all within tolerance of the chord
+func flattenBezier(c PathChunk, tolerance float64, depth int) []primitives.Point {
+	if depth >= maxFlattenDepth || bezierFlatness(c) <= tolerance {
+		return []primitives.Point{c.Endpoint()}
+	}
+	left, right := c.Bisect(0.5)
+	return append(flattenBezier(left, tolerance, depth+1), flattenBezier(right, tolerance, depth+1)...)
+}
+
+// bezierFlatness is the largest distance of a control point to the chord between start and end
+func bezierFlatness(c PathChunk) float64 {
+	var controls []primitives.Point
+	switch chunk := c.(type) {
+	case QuadraticBezierChunk:
+		controls = []primitives.Point{chunk.P1}
+	case CubicBezierChunk:
+		controls = []primitives.Point{chunk.P1, chunk.P2}
+	}
+	chord := c.Endpoint().Subtract(c.Startpoint())
+	flatness := 0.0
+	for _, pt := range controls {
+		v := pt.Subtract(c.Startpoint())
+		var dist float64
+		if chord.Len() == 0 {
+			dist = v.Len()
+		} else {
+			dist = math.Abs(v.Dot(chord.Perp().Unit()))
+		}
+		flatness = max(flatness, dist)
+	}
+	return flatness
+}
@@ -140,6 +140,11 @@ func (l LineSegment) Bisect(t float64) (Path, Path) {
 		})
 }
 
+// Path returns the line segment as a Path with a single LineChunk
+func (l LineSegment) Path() Path {
+	return NewPath(l.P1).AddPathChunk(LineChunk{Start: l.P1, End: l.P2})
+}
+
 func SegmentsToLineLikes(segments []LineSegment) []LineLike {
 	linelikes := make([]LineLike, len(segments))
 	for i, seg := range segments {
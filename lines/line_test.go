@@ -2,6 +2,7 @@ package lines
 
 import (
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -59,3 +60,43 @@ func TestLineIntersection(t *testing.T) {
 		})
 	}
 }
+
+// At and Bisect agree on where a path is split, also for arcs in either direction and on the seams between chunks
+func TestPathAtBisect(t *testing.T) {
+	center := primitives.Point{X: 100, Y: 100}
+	paths := map[string]Path{
+		"circle":        ToPath(FullCircle(center, 50)),
+		"reverse":       ToPath(FullCircle(center, 50).Reverse()),
+		"wrapping arc":  NewPath(CircleArcChunk(center, 50, 3*math.Pi/2, math.Pi/2, true).Startpoint()).AddPathChunk(CircleArcChunk(center, 50, 3*math.Pi/2, math.Pi/2, true)),
+		"line and turn": NewPath(primitives.Origin).AddPathChunk(LineChunk{Start: primitives.Origin, End: primitives.Point{X: 150, Y: 100}}).AddPathChunk(CircleArcChunk(center, 50, 0, math.Pi, false)),
+	}
+	for name, path := range paths {
+		t.Run(name, func(t *testing.T) {
+			if d := path.At(0).Subtract(path.Start()).Len(); d > 1e-6 {
+				t.Errorf("At(0) is %v, want the start %v", path.At(0), path.Start())
+			}
+			for i := range 16 {
+				tt := float64(i) / 16
+				left, right := path.Bisect(tt)
+				if d := right.Start().Subtract(path.At(tt)).Len(); d > 1e-6 {
+					t.Errorf("Bisect(%.4f) splits at %v, but At(%.4f) is %v", tt, right.Start(), tt, path.At(tt))
+				}
+				if d := left.End().Subtract(right.Start()).Len(); d > 1e-6 {
+					t.Errorf("Bisect(%.4f) leaves a gap from %v to %v", tt, left.End(), right.Start())
+				}
+				if d := left.Len() + right.Len() - path.Len(); math.Abs(d) > 1e-6 {
+					t.Errorf("Bisect(%.4f) changes the length by %.4f", tt, d)
+				}
+				if path.Start().Subtract(path.End()).Len() < pathThreshold {
+					rotated := path.StartAt(tt)
+					if d := rotated.Start().Subtract(path.At(tt)).Len(); d > 1e-6 {
+						t.Errorf("StartAt(%.4f) starts at %v, but At(%.4f) is %v", tt, rotated.Start(), tt, path.At(tt))
+					}
+					if d := rotated.Len() - path.Len(); math.Abs(d) > 1e-6 {
+						t.Errorf("StartAt(%.4f) changes the length by %.4f", tt, d)
+					}
+				}
+			}
+		})
+	}
+}
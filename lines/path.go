@@ -6,7 +6,6 @@ import (
 
 	"go.shabbyrobe.org/xmlwriter"
 
-	"github.com/libeks/go-plotter-svg/maths"
 	"github.com/libeks/go-plotter-svg/primitives"
 )
 
@@ -38,19 +37,24 @@ func (p Path) Len() float64 {
 }
 
 func (p Path) At(t float64) primitives.Point {
-	len := p.Len()
+	target := p.Len() * t
 	cumLen := 0.0
 	for _, chunk := range p.chunks {
-		cumLenBefore := cumLen
-		cumLen += chunk.Length()
-		if cumLen > len*t {
-			subT := maths.ReverseInterpolatedTValueFailure(cumLenBefore/len, cumLen/len, t)
-			return chunk.At(subT)
+		chunkLen := chunk.Length()
+		if cumLen+chunkLen > target {
+			// comparing lengths rather than fractions of the length, which could round past each other
+			return chunk.At(max(target-cumLen, 0) / chunkLen)
 		}
+		cumLen += chunkLen
 	}
 	return p.End()
 }
 
+// Chunks returns the PathChunks that make up this path, in drawing order
+func (p Path) Chunks() []PathChunk {
+	return p.chunks
+}
+
 // Return a list of all the control points of this path
 func (p Path) Points() []primitives.Point {
 	points := []primitives.Point{p.start}
@@ -206,6 +210,11 @@ func (p Path) Bisect(t float64) (Path, Path) {
 	var cumLen float64
 	for i, chunk := range p.chunks {
 		chunkLen := chunk.Length()
+		if cumLen >= targetLength {
+			// the split falls between two chunks, splitting the chunk would leave an empty piece of it behind
+			rightChunks = append(rightChunks, p.chunks[i:]...)
+			break
+		}
 		if cumLen+chunkLen > targetLength {
 			tStart := cumLen / l
 			tEnd := (cumLen + chunkLen) / l
@@ -220,9 +229,18 @@ func (p Path) Bisect(t float64) (Path, Path) {
 			cumLen += chunk.Length()
 		}
 	}
+	if len(rightChunks) == 0 {
+		return p, Path{start: p.End()}
+	}
 	return Path{start: p.start, chunks: leftChunks}, Path{start: rightChunks[0].Startpoint(), chunks: rightChunks}
 }
 
+// StartAt returns the closed path, starting t of the way along it and going around to the same point
+func (p Path) StartAt(t float64) Path {
+	start, end := p.Bisect(t)
+	return end.Join(start)
+}
+
 func (p Path) Join(q Path) Path {
 	if p.End().Subtract(q.Start()).Len() > 0.1 {
 		panic("the two paths don't join at the ends")
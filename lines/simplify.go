@@ -0,0 +1,337 @@
+package lines
+
+import (
+	"container/heap"
+	"math"
+
+	"github.com/libeks/go-plotter-svg/primitives"
+)
+
+const (
+	cornerAngle    = math.Pi / 3 // turns sharper than this are kept as corners when fitting curves
+	reparameterize = 4           // rounds of Newton's method that a curve fit gets before it is split
+)
+
+// Simplification says how Path.Simplify cuts down on the chunks of a path, leaving out the steps whose fields are zero
+type Simplification struct {
+	Tolerance    float64 `json:"tolerance,omitempty"`     // Ramer-Douglas-Peucker: the furthest that a dropped point may be from the simplified line
+	MinArea      float64 `json:"min_area,omitempty"`      // Visvalingam-Whyatt: the smallest area of the triangle that a kept point makes with its neighbors
+	MaxDeviation float64 `json:"max_deviation,omitempty"` // the furthest that a point may be from the cubic Beziers fitted to runs of line chunks
+}
+
+// Simplify applies each step of the simplification in turn, first Ramer-Douglas-Peucker, then Visvalingam-Whyatt,
+// and then fitting Beziers
+func (p Path) Simplify(s Simplification) Path {
+	if s.Tolerance > 0 {
+		p = p.SimplifyRDP(s.Tolerance)
+	}
+	if s.MinArea > 0 {
+		p = p.SimplifyVisvalingam(s.MinArea)
+	}
+	if s.MaxDeviation > 0 {
+		p = p.FitBeziers(s.MaxDeviation)
+	}
+	return p
+}
+
+// SimplifyRDP drops points from each run of line chunks with the Ramer-Douglas-Peucker algorithm, which keeps the
+// point furthest from the line between the ends of the run, and then does the same on either side of it, until
+// all the points are within tolerance. Other chunks are kept as they are.
+func (p Path) SimplifyRDP(tolerance float64) Path {
+	return p.mapRuns(func(points []primitives.Point) []PathChunk {
+		return polyline(rdp(points, tolerance))
+	})
+}
+
+// SimplifyVisvalingam drops points from each run of line chunks with the Visvalingam-Whyatt algorithm, which keeps
+// dropping the point that makes the triangle of the smallest area with its neighbors, for as long as that area
+// is less than minArea. Other chunks are kept as they are.
+func (p Path) SimplifyVisvalingam(minArea float64) Path {
+	return p.mapRuns(func(points []primitives.Point) []PathChunk {
+		return polyline(visvalingam(points, minArea))
+	})
+}
+
+// FitBeziers replaces each run of line chunks with cubic Beziers that pass within maxDeviation of its points,
+// keeping sharp turns as corners. Runs of a single line chunk, and other chunks, are kept as they are.
+func (p Path) FitBeziers(maxDeviation float64) Path {
+	return p.mapRuns(func(points []primitives.Point) []PathChunk {
+		chunks := []PathChunk{}
+		for _, part := range splitAtCorners(dedupe(points)) {
+			if len(part) == 2 {
+				chunks = append(chunks, LineChunk{Start: part[0], End: part[1]})
+				continue
+			}
+			start := part[1].Subtract(part[0]).Unit()
+			end := part[len(part)-2].Subtract(part[len(part)-1]).Unit()
+			chunks = append(chunks, fitCubic(part, start, end, maxDeviation)...)
+		}
+		return chunks
+	})
+}
+
+// mapRuns replaces each run of consecutive line chunks with the chunks that f returns for the points of the run
+func (p Path) mapRuns(f func(points []primitives.Point) []PathChunk) Path {
+	ret := Path{start: p.start}
+	var run []primitives.Point
+	flush := func() {
+		if len(run) > 1 {
+			ret.chunks = append(ret.chunks, f(run)...)
+		}
+		run = nil
+	}
+	for _, chunk := range p.chunks {
+		if c, ok := chunk.(LineChunk); ok {
+			if len(run) == 0 {
+				run = append(run, c.Start)
+			}
+			run = append(run, c.End)
+			continue
+		}
+		flush()
+		ret.chunks = append(ret.chunks, chunk)
+	}
+	flush()
+	return ret
+}
+
+func polyline(points []primitives.Point) []PathChunk {
+	chunks := make([]PathChunk, 0, len(points)-1)
+	for i := 1; i < len(points); i++ {
+		chunks = append(chunks, LineChunk{Start: points[i-1], End: points[i]})
+	}
+	return chunks
+}
+
+// segmentDistance is the distance from p to the closest point between a and b
+func segmentDistance(p, a, b primitives.Point) float64 {
+	v := b.Subtract(a)
+	length2 := v.Dot(v)
+	if length2 == 0 {
+		return p.Subtract(a).Len()
+	}
+	t := min(max(p.Subtract(a).Dot(v)/length2, 0), 1)
+	return p.Subtract(a.Add(v.Mult(t))).Len()
+}
+
+func rdp(points []primitives.Point, tolerance float64) []primitives.Point {
+	keep := make([]bool, len(points))
+	keep[0], keep[len(points)-1] = true, true
+	stack := [][2]int{{0, len(points) - 1}}
+	for len(stack) > 0 {
+		i, j := stack[len(stack)-1][0], stack[len(stack)-1][1]
+		stack = stack[:len(stack)-1]
+		furthest, dist := -1, tolerance
+		for k := i + 1; k < j; k++ {
+			if d := segmentDistance(points[k], points[i], points[j]); d > dist {
+				furthest, dist = k, d
+			}
+		}
+		if furthest >= 0 {
+			keep[furthest] = true
+			stack = append(stack, [2]int{i, furthest}, [2]int{furthest, j})
+		}
+	}
+	kept := []primitives.Point{}
+	for i, point := range points {
+		if keep[i] {
+			kept = append(kept, point)
+		}
+	}
+	return kept
+}
+
+// vertex is a point of a polyline in the heap of visvalingam, by the area of its triangle at the time
+type vertex struct {
+	i    int
+	area float64
+}
+
+type vertexHeap []vertex
+
+func (h vertexHeap) Len() int           { return len(h) }
+func (h vertexHeap) Less(i, j int) bool { return h[i].area < h[j].area }
+func (h vertexHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *vertexHeap) Push(x any)        { *h = append(*h, x.(vertex)) }
+func (h *vertexHeap) Pop() any {
+	old := *h
+	v := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return v
+}
+
+func visvalingam(points []primitives.Point, minArea float64) []primitives.Point {
+	n := len(points)
+	prev, next := make([]int, n), make([]int, n)
+	areas := make([]float64, n)
+	removed := make([]bool, n)
+	triangle := func(i int) float64 {
+		a, b := points[prev[i]].Subtract(points[i]), points[next[i]].Subtract(points[i])
+		return math.Abs(a.X*b.Y-a.Y*b.X) / 2
+	}
+	h := &vertexHeap{}
+	for i := range n {
+		prev[i], next[i] = i-1, i+1
+	}
+	for i := 1; i < n-1; i++ {
+		areas[i] = triangle(i)
+		heap.Push(h, vertex{i: i, area: areas[i]})
+	}
+	for h.Len() > 0 {
+		v := heap.Pop(h).(vertex)
+		if removed[v.i] || v.area != areas[v.i] {
+			continue // the point is gone, or its area changed since it was pushed
+		}
+		if v.area >= minArea {
+			break
+		}
+		removed[v.i] = true
+		p, q := prev[v.i], next[v.i]
+		next[p], prev[q] = q, p
+		for _, j := range []int{p, q} {
+			if j > 0 && j < n-1 {
+				// a point never gets a smaller area than the one dropped before it, so that the order in which
+				// points are dropped follows the areas
+				areas[j] = max(triangle(j), v.area)
+				heap.Push(h, vertex{i: j, area: areas[j]})
+			}
+		}
+	}
+	kept := []primitives.Point{}
+	for i, point := range points {
+		if !removed[i] {
+			kept = append(kept, point)
+		}
+	}
+	return kept
+}
+
+// dedupe drops points that are the same as the one before them, which have no direction to fit a curve along
+func dedupe(points []primitives.Point) []primitives.Point {
+	ret := []primitives.Point{points[0]}
+	for _, point := range points[1:] {
+		if point != ret[len(ret)-1] {
+			ret = append(ret, point)
+		}
+	}
+	if len(ret) == 1 {
+		ret = append(ret, points[len(points)-1])
+	}
+	return ret
+}
+
+// splitAtCorners splits the points at the turns sharper than cornerAngle, each part sharing its ends with its
+// neighbors
+func splitAtCorners(points []primitives.Point) [][]primitives.Point {
+	parts := [][]primitives.Point{}
+	start := 0
+	for i := 1; i < len(points)-1; i++ {
+		a, b := points[i].Subtract(points[i-1]), points[i+1].Subtract(points[i])
+		if a.Dot(b) < math.Cos(cornerAngle)*a.Len()*b.Len() {
+			parts = append(parts, points[start:i+1])
+			start = i
+		}
+	}
+	return append(parts, points[start:])
+}
+
+// fitCubic fits cubic Beziers to the points, leaving the first point along the start tangent, and arriving at the
+// last one against the end tangent, as in "An Algorithm for Automatically Fitting Digitized Curves" by Philip
+// J. Schneider, from Graphics Gems. It fits a single curve by least squares, improves on it with a few rounds of
+// Newton's method, and if it still strays too far from the points, splits them where it strays the furthest.
+func fitCubic(points []primitives.Point, start, end primitives.Vector, maxDeviation float64) []PathChunk {
+	if len(points) == 2 {
+		return []PathChunk{LineChunk{Start: points[0], End: points[1]}}
+	}
+	u := chordLengths(points)
+	curve := leastSquaresCubic(points, u, start, end)
+	deviation, split := maxError(points, curve, u)
+	if deviation <= maxDeviation {
+		return []PathChunk{curve}
+	}
+	if deviation <= 4*maxDeviation {
+		for range reparameterize {
+			u = newtonStep(points, curve, u)
+			curve = leastSquaresCubic(points, u, start, end)
+			if deviation, split = maxError(points, curve, u); deviation <= maxDeviation {
+				return []PathChunk{curve}
+			}
+		}
+	}
+	center := points[split-1].Subtract(points[split+1])
+	if center.Len() == 0 {
+		center = points[split-1].Subtract(points[split])
+	}
+	center = center.Unit()
+	left := fitCubic(points[:split+1], start, center, maxDeviation)
+	return append(left, fitCubic(points[split:], center.Mult(-1), end, maxDeviation)...)
+}
+
+// chordLengths parametrizes the points by the fraction of the length of the polyline up to each of them
+func chordLengths(points []primitives.Point) []float64 {
+	u := make([]float64, len(points))
+	for i := 1; i < len(points); i++ {
+		u[i] = u[i-1] + points[i].Subtract(points[i-1]).Len()
+	}
+	for i := range u {
+		u[i] /= u[len(u)-1]
+	}
+	return u
+}
+
+// leastSquaresCubic finds how far along the tangents the control points go, for the curve to pass as close as
+// possible to each point at its parameter
+func leastSquaresCubic(points []primitives.Point, u []float64, start, end primitives.Vector) CubicBezierChunk {
+	first, last := points[0], points[len(points)-1]
+	var c00, c01, c11, x0, x1 float64
+	for i, p := range points {
+		t := u[i]
+		b1, b2, b3 := 3*t*(1-t)*(1-t), 3*t*t*(1-t), t*t*t
+		a0, a1 := start.Mult(b1), end.Mult(b2)
+		c00 += a0.Dot(a0)
+		c01 += a0.Dot(a1)
+		c11 += a1.Dot(a1)
+		// the part of the point that the ends of the curve don't account for
+		rest := p.Subtract(first).Add(last.Subtract(first).Mult(-(b2 + b3)))
+		x0 += a0.Dot(rest)
+		x1 += a1.Dot(rest)
+	}
+	chord := last.Subtract(first).Len()
+	alphaStart, alphaEnd := chord/3, chord/3
+	if det := c00*c11 - c01*c01; det != 0 {
+		l, r := (x0*c11-x1*c01)/det, (c00*x1-c01*x0)/det
+		if l > 1e-6*chord && r > 1e-6*chord {
+			alphaStart, alphaEnd = l, r
+		}
+	}
+	return CubicBezierChunk{Start: first, P1: first.Add(start.Mult(alphaStart)), P2: last.Add(end.Mult(alphaEnd)), End: last}
+}
+
+// maxError returns the furthest that the curve strays from the points at their parameters, and the point where
+// it does, which is never one of the ends
+func maxError(points []primitives.Point, curve CubicBezierChunk, u []float64) (float64, int) {
+	furthest, split := 0.0, len(points)/2
+	for i := 1; i < len(points)-1; i++ {
+		if d := curve.At(u[i]).Subtract(points[i]).Len(); d > furthest {
+			furthest, split = d, i
+		}
+	}
+	return furthest, split
+}
+
+// newtonStep moves the parameter of each point towards that of the closest point of the curve
+func newtonStep(points []primitives.Point, c CubicBezierChunk, u []float64) []float64 {
+	d1 := [3]primitives.Vector{c.P1.Subtract(c.Start).Mult(3), c.P2.Subtract(c.P1).Mult(3), c.End.Subtract(c.P2).Mult(3)}
+	d2 := [2]primitives.Vector{d1[1].Add(d1[0].Mult(-1)).Mult(2), d1[2].Add(d1[1].Mult(-1)).Mult(2)}
+	ret := make([]float64, len(u))
+	for i, t := range u {
+		ret[i] = t
+		diff := c.At(t).Subtract(points[i])
+		q1 := d1[0].Mult((1 - t) * (1 - t)).Add(d1[1].Mult(2 * t * (1 - t))).Add(d1[2].Mult(t * t))
+		q2 := d2[0].Mult(1 - t).Add(d2[1].Mult(t))
+		if den := q1.Dot(q1) + diff.Dot(q2); den != 0 {
+			ret[i] = min(max(t-diff.Dot(q1)/den, 0), 1)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,145 @@
+package lines
+
+import (
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/libeks/go-plotter-svg/primitives"
+)
+
+func polylinePath(points ...primitives.Point) Path {
+	path := NewPath(points[0])
+	for i := 1; i < len(points); i++ {
+		path = path.AddPathChunk(LineChunk{Start: points[i-1], End: points[i]})
+	}
+	return path
+}
+
+func TestSimplify(t *testing.T) {
+	zigzag := polylinePath(
+		primitives.Point{X: 0, Y: 0}, primitives.Point{X: 10, Y: 0.5}, primitives.Point{X: 20, Y: -0.5},
+		primitives.Point{X: 30, Y: 0}, primitives.Point{X: 40, Y: 20}, primitives.Point{X: 50, Y: 0},
+	)
+	tests := []struct {
+		name string
+		path Path
+		s    Simplification
+		want []primitives.Point
+	}{
+		{
+			name: "collinear points",
+			path: polylinePath(primitives.Point{X: 0, Y: 0}, primitives.Point{X: 5, Y: 0}, primitives.Point{X: 10, Y: 0}),
+			s:    Simplification{Tolerance: 0.1},
+			want: []primitives.Point{{X: 0, Y: 0}, {X: 10, Y: 0}},
+		},
+		{
+			name: "rdp keeps the peak",
+			path: zigzag,
+			s:    Simplification{Tolerance: 1},
+			want: []primitives.Point{{X: 0, Y: 0}, {X: 30, Y: 0}, {X: 40, Y: 20}, {X: 50, Y: 0}},
+		},
+		{
+			name: "rdp within the tolerance",
+			path: zigzag,
+			s:    Simplification{Tolerance: 0.1},
+			want: zigzag.Points(),
+		},
+		{
+			name: "visvalingam drops the small triangles",
+			path: zigzag,
+			s:    Simplification{MinArea: 20},
+			want: []primitives.Point{{X: 0, Y: 0}, {X: 30, Y: 0}, {X: 40, Y: 20}, {X: 50, Y: 0}},
+		},
+		{
+			name: "visvalingam keeps the large ones",
+			path: zigzag,
+			s:    Simplification{MinArea: 1},
+			want: zigzag.Points(),
+		},
+		{
+			name: "other chunks split the runs",
+			path: polylinePath(primitives.Point{X: 0, Y: 0}, primitives.Point{X: 5, Y: 0}, primitives.Point{X: 10, Y: 0}).
+				AddPathChunk(CircleArcChunk(primitives.Point{X: 10, Y: 10}, 10, -math.Pi/2, 0, true)).
+				AddPathChunk(LineChunk{Start: primitives.Point{X: 20, Y: 10}, End: primitives.Point{X: 20, Y: 15}}).
+				AddPathChunk(LineChunk{Start: primitives.Point{X: 20, Y: 15}, End: primitives.Point{X: 20, Y: 20}}),
+			s:    Simplification{Tolerance: 0.1},
+			want: []primitives.Point{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 20, Y: 10}, {X: 20, Y: 20}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.path.Simplify(tt.s).Points()
+			for i := range got {
+				// arcs have their ends rounded off a little
+				got[i] = primitives.Point{X: math.Round(got[i].X*1e6) / 1e6, Y: math.Round(got[i].Y*1e6) / 1e6}
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Unexpected diff %v", diff)
+			}
+		})
+	}
+}
+
+func TestFitBeziers(t *testing.T) {
+	center := primitives.Point{X: 100, Y: 100}
+	circle := []primitives.Point{}
+	for i := range 201 {
+		circle = append(circle, center.Add(primitives.UnitRight.RotateCCW(2*math.Pi*float64(i)/200).Mult(50)))
+	}
+	tests := []struct {
+		name      string
+		points    []primitives.Point
+		maxChunks int
+	}{
+		{name: "circle", points: circle, maxChunks: 12},
+		{name: "wavy line", points: sine(), maxChunks: 16},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := polylinePath(tt.points...)
+			fitted := path.FitBeziers(0.5)
+			if n := len(fitted.Chunks()); n > tt.maxChunks {
+				t.Errorf("FitBeziers() returned %d chunks, want at most %d", n, tt.maxChunks)
+			}
+			if fitted.Start() != path.Start() || fitted.End() != path.End() {
+				t.Errorf("FitBeziers() moved the ends to %v and %v", fitted.Start(), fitted.End())
+			}
+			samples := []primitives.Point{}
+			for _, chunk := range fitted.Chunks() {
+				for i := range 1001 {
+					samples = append(samples, chunk.(CubicBezierChunk).At(float64(i)/1000))
+				}
+			}
+			for _, point := range tt.points {
+				closest := math.Inf(1)
+				for _, sample := range samples {
+					closest = min(closest, sample.Subtract(point).Len())
+				}
+				if closest > 0.5+0.1 {
+					t.Errorf("FitBeziers() strays %.2f from %v", closest, point)
+				}
+			}
+		})
+	}
+	t.Run("corners", func(t *testing.T) {
+		path := polylinePath(
+			primitives.Point{X: 0, Y: 0}, primitives.Point{X: 10, Y: 0}, primitives.Point{X: 20, Y: 0},
+			primitives.Point{X: 20, Y: 10}, primitives.Point{X: 20, Y: 20},
+		)
+		got := path.FitBeziers(0.5).Points()
+		want := []primitives.Point{{X: 0, Y: 0}, {X: 20, Y: 0}, {X: 20, Y: 20}}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Unexpected diff %v", diff)
+		}
+	})
+}
+
+func sine() []primitives.Point {
+	points := []primitives.Point{}
+	for i := range 300 {
+		x := float64(i)
+		points = append(points, primitives.Point{X: x, Y: 20 * math.Sin(x/30)})
+	}
+	return points
+}
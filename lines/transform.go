@@ -0,0 +1,80 @@
+package lines
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/libeks/go-plotter-svg/primitives"
+)
+
+// Transform applies an affine transformation to the path. Circle arcs stay arcs if the transformation
+// preserves circles, otherwise they are converted into cubic Bezier curves.
+func (p Path) Transform(t primitives.Transform) Path {
+	path := NewPath(t.Apply(p.start))
+	for _, chunk := range p.chunks {
+		for _, c := range TransformChunk(chunk, t) {
+			path = path.AddPathChunk(c)
+		}
+	}
+	return path
+}
+
+// TransformChunk applies an affine transformation to a single chunk, which may turn into several chunks
+func TransformChunk(chunk PathChunk, t primitives.Transform) []PathChunk {
+	switch c := chunk.(type) {
+	case LineChunk:
+		return []PathChunk{LineChunk{Start: t.Apply(c.Start), End: t.Apply(c.End)}}
+	case QuadraticBezierChunk:
+		return []PathChunk{QuadraticBezierChunk{Start: t.Apply(c.Start), P1: t.Apply(c.P1), End: t.Apply(c.End)}}
+	case CubicBezierChunk:
+		return []PathChunk{CubicBezierChunk{Start: t.Apply(c.Start), P1: t.Apply(c.P1), P2: t.Apply(c.P2), End: t.Apply(c.End)}}
+	case circleArcChunk:
+		if !t.IsSimilarity() {
+			chunks := []PathChunk{}
+			for _, bezier := range ArcToBeziers(c) {
+				chunks = append(chunks, TransformChunk(bezier, t)...)
+			}
+			return chunks
+		}
+		rotation := t.Angle()
+		if t.Determinant() < 0 {
+			// a reflection mirrors the angles, and the direction of travel
+			return []PathChunk{CircleArcChunk(t.Apply(c.center), c.radius*t.Scale(), rotation-c.startRad, rotation-c.endRad, !c.isClockwise)}
+		}
+		return []PathChunk{CircleArcChunk(t.Apply(c.center), c.radius*t.Scale(), c.startRad+rotation, c.endRad+rotation, c.isClockwise)}
+	}
+	panic(fmt.Errorf("don't know how to transform %v", chunk))
+}
+
+// ArcToBeziers approximates a circle arc with cubic Bezier curves, each spanning at most a quarter circle
+func ArcToBeziers(arc ArcChunk) []CubicBezierChunk {
+	return EllipticalArcToBeziers(primitives.Translation(primitives.Vector(arc.Center())), arc.Radius(), arc.Radius(), arc.StartAngle(), arc.Sweep())
+}
+
+// EllipticalArcToBeziers approximates an arc of an axis-aligned ellipse centered on the origin with cubic
+// Bezier curves, which are then placed with the transformation t. The arc starts at angle start and
+// turns by sweep radians, positive sweeps increase the angle.
+func EllipticalArcToBeziers(t primitives.Transform, rx, ry, start, sweep float64) []CubicBezierChunk {
+	n := max(int(math.Ceil(math.Abs(sweep)/(math.Pi/2)-1e-9)), 1)
+	delta := sweep / float64(n)
+	// distance of the control points along the tangent, for a unit circle
+	k := 4.0 / 3.0 * math.Tan(delta/4)
+	point := func(angle float64) primitives.Point {
+		return t.Apply(primitives.Point{X: rx * math.Cos(angle), Y: ry * math.Sin(angle)})
+	}
+	tangent := func(angle float64) primitives.Vector {
+		return t.ApplyVector(primitives.Vector{X: -rx * math.Sin(angle), Y: ry * math.Cos(angle)})
+	}
+	beziers := make([]CubicBezierChunk, n)
+	for i := range n {
+		a := start + delta*float64(i)
+		b := a + delta
+		beziers[i] = CubicBezierChunk{
+			Start: point(a),
+			P1:    point(a).Add(tangent(a).Mult(k)),
+			P2:    point(b).Add(tangent(b).Mult(-k)),
+			End:   point(b),
+		}
+	}
+	return beziers
+}
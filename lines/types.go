@@ -38,3 +38,19 @@ type PathChunk interface {
 	Bisect(t float64) (PathChunk, PathChunk)
 	At(t float64) primitives.Point
 }
+
+// ArcChunk is implemented by the chunk returned by CircleArcChunk, to allow exporters to emit native arcs
+type ArcChunk interface {
+	PathChunk
+	Center() primitives.Point
+	Radius() float64
+	StartAngle() float64
+	EndAngle() float64
+	IsClockwise() bool
+	Sweep() float64
+}
+
+// Pather is implemented by LineLikes that can be expressed as a Path made up of PathChunks
+type Pather interface {
+	Path() Path
+}
@@ -2,116 +2,127 @@ package main
 
 import (
 	"errors"
+	"flag"
 	"fmt"
 	"os"
-	"time"
+	"strings"
+)
 
-	"github.com/libeks/go-plotter-svg/primitives"
-	"github.com/libeks/go-plotter-svg/scenes"
-	"github.com/libeks/go-plotter-svg/svg"
+const (
+	exitOK    = 0
+	exitError = 1 // something went wrong while rendering or writing files
+	exitUsage = 2 // the command line doesn't make sense
 )
 
-type Config struct {
-	fname          string
-	sceneName      string
-	showSceneNames bool
-	renderAll      bool
+// usageError is returned for mistakes on the command line, as opposed to failures while running a command
+type usageError struct {
+	msg string
 }
 
-func main() {
-	args := os.Args[1:]
-	sizePx := 10000.0
+func (e usageError) Error() string {
+	return e.msg
+}
+
+func usageErrorf(format string, a ...any) error {
+	return usageError{fmt.Sprintf(format, a...)}
+}
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands []command
 
-	config, err := parseFlags(args)
-	if err != nil {
-		panic(err)
+func init() {
+	// set up here, since the help command refers back to the list of commands
+	commands = []command{
+		{"render", "generate a scene and write it in one or more formats", runRender},
+		{"list", "list the available scenes", runList},
+		{"stats", "print line counts, distances and time estimates for a scene", runStats},
+		{"preview", "generate a scene and write a PNG preview of it", runPreview},
+		{"export", "write a saved JSON document in one or more formats, without generating it again", runExport},
+		{"optimize", "reorder the lines of a scene to cut down on pen-up travel", runOptimize},
+		{"sweep", "render variants of a scene side by side on one page, varying one or two parameters or the seed", runSweep},
+		{"serve", "host a local page that renders the scenes, with their parameters, layers and stats", runServe},
+		{"help", "show the usage of a command", runHelp},
 	}
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
 
-	outerBox := primitives.BBox{
-		UpperLeft:  primitives.Point{X: 0, Y: 800},                        // leave space at the top for guides
-		LowerRight: primitives.Point{X: sizePx * (12.0 / 9.0), Y: sizePx}, // make sure it spans the 9"x12" canvas
+func run(args []string) int {
+	if len(args) == 0 {
+		usage()
+		return exitUsage
 	}
-	start := time.Now()
-	innerBox := outerBox.WithPadding(500) // enough to no hit the edges
-	library := scenes.GatherScenes()
-	if config.showSceneNames {
-		sceneNames := library.GetNames()
-		fmt.Printf("These scenes are available:\n")
-		for _, name := range sceneNames {
-			fmt.Printf("\t%s\n", name)
-		}
-		return
+	if args[0] == "-h" || args[0] == "--help" {
+		usage()
+		return exitOK
 	}
-
-	if config.renderAll {
-		for _, name := range library.GetNames() {
-			if sceneFn, err := library.Get(name); err != nil {
-				panic(err)
-			} else {
-				fmt.Printf("Rendering scene %s...\n", name)
-				scene := sceneFn(innerBox)
-				svg.SVG{
-					Fname:    fmt.Sprintf("gallery/debug/%s.svg", name),
-					Width:    "12in",
-					Height:   "9in",
-					Document: scene,
-				}.WriteSVG()
-			}
-		}
-		fmt.Printf("Rendering all took %s.\n", time.Since(start))
-		return
+	name, args := commandArgs(args)
+	cmd, ok := findCommand(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command '%s'\n\n", name)
+		usage()
+		return exitUsage
 	}
-	sceneFn, err := library.Get(config.sceneName)
-	if err != nil {
-		panic(err)
+	err := cmd.run(args)
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return exitOK
 	}
-	scene := sceneFn(innerBox)
+	fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+	if errors.As(err, &usageError{}) {
+		fmt.Fprintf(os.Stderr, "Run 'go-plotter-svg help %s' for usage.\n", cmd.name)
+		return exitUsage
+	}
+	return exitError
+}
 
-	scene.CalculateStatistics()
-	svg.SVG{
-		Fname:    config.fname,
-		Width:    "12in",
-		Height:   "9in",
-		Document: scene,
-	}.WriteSVG()
-	fmt.Printf("Rendering took %s.\n", time.Since(start))
+// commandArgs splits the arguments into the name of the command and its own arguments, also for the flags that
+// the binary used to be called with, before it had commands
+func commandArgs(args []string) (string, []string) {
+	name := args[0]
+	switch {
+	case name == "--list-scenes":
+		return "list", args[1:]
+	case name == "--render-all":
+		return "render", append([]string{"-all"}, args[1:]...)
+	case strings.HasPrefix(name, "-"):
+		// flags without a command
+		return "render", args
+	}
+	return name, args[1:]
 }
 
-func parseFlags(args []string) (Config, error) {
-	fname := "gallery/test.svg"
-	sceneName := "test-density-v2"
-	// n := len(args)
-	for len(args) > 0 {
-		arg := args[0]
-		if arg == "--fname" || arg == "--file" || arg == "--filename" {
-			if len(args) < 2 {
-				return Config{}, errors.New("Parameter '--fname' must be followed by a filename")
-			}
-			fname = args[1]
-			args = args[2:]
-			continue
-		}
-		if arg == "--scene" {
-			if len(args) < 2 {
-				return Config{}, errors.New("Parameter '--scene' must be followed by a scene name")
-			}
-			sceneName = args[1]
-			args = args[2:]
-			continue
-		}
-		if arg == "--list-scenes" {
-			return Config{showSceneNames: true}, nil
-		}
-		if arg == "--render-all" {
-			return Config{renderAll: true}, nil
-		}
-		if arg == "--help" {
-			//TODO: write a help doc ehre
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
 		}
-		return Config{}, errors.New(fmt.Sprintf("Not sure what to do with parameters %v", args))
 	}
-	return Config{
-		fname:     fname,
-		sceneName: sceneName,
-	}, nil
+	return command{}, false
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: go-plotter-svg <command> [options]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun 'go-plotter-svg help <command>' for the options of a command.\n")
+}
+
+func runHelp(args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" {
+		usage()
+		return nil
+	}
+	cmd, ok := findCommand(args[0])
+	if !ok {
+		return usageErrorf("unknown command '%s'", args[0])
+	}
+	return cmd.run([]string{"-h"})
 }
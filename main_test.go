@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/scenes"
+)
+
+// stats -json prints nothing but the JSON on stdout, also for scenes that report on their own progress while
+// they render, such as those that call Layer.MinimizePath
+func TestStatsJSON(t *testing.T) {
+	for _, scene := range []string{"rising-sun", "maze"} {
+		t.Run(scene, func(t *testing.T) {
+			out := captureStdout(t, func() {
+				if code := run([]string{"stats", "-scene", scene, "-seed", "1", "-json"}); code != exitOK {
+					t.Errorf("run() returned exit code %d", code)
+				}
+			})
+			var stats map[string]any
+			if err := json.Unmarshal(out, &stats); err != nil {
+				t.Errorf("stdout isn't JSON: %v\n%s", err, out)
+			}
+		})
+	}
+}
+
+// captureStdout returns what f writes to os.Stdout
+func captureStdout(t *testing.T, f func()) []byte {
+	t.Helper()
+	fname := filepath.Join(t.TempDir(), "stdout")
+	file, err := os.Create(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = file
+	f()
+	os.Stdout = stdout
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.ReadFile(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// -from processes the scene again as recorded in the SVG, unless the processing flags say otherwise
+func TestFromProcessing(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "test.svg")
+	if code := run([]string{"render", "-scene", "circles-in-square", "-fname", fname, "-chain", "5", "-simplify", "0.5", "-optimize", "-optimize-budget", "1s"}); code != exitOK {
+		t.Fatalf("run() returned exit code %d", code)
+	}
+	tests := []struct {
+		name string
+		args []string
+		want scenes.Processing
+	}{
+		{
+			name: "as recorded",
+			want: scenes.Processing{Chain: 5, Simplify: lines.Simplification{Tolerance: 0.5}, Optimize: true, OptimizeBudget: time.Second},
+		},
+		{
+			name: "flags take precedence",
+			args: []string{"-chain", "0", "-fit-curves", "2", "-optimize-budget", "0"},
+			want: scenes.Processing{Simplify: lines.Simplification{Tolerance: 0.5, MaxDeviation: 2}, Optimize: true},
+		},
+		{
+			name: "without optimizing",
+			args: []string{"-optimize=false"},
+			want: scenes.Processing{Chain: 5, Simplify: lines.Simplification{Tolerance: 0.5}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := newFlagSet("test", "")
+			scene := sceneOptions{progress: io.Discard}
+			scene.register(fs)
+			if err := parse(fs, append([]string{"-from", fname}, tt.args...)); err != nil {
+				t.Fatalf("parse() returned error %v", err)
+			}
+			doc, err := scene.process(scenes.Document{}.WithSource(scenes.Source{Scene: "circles-in-square"}))
+			if err != nil {
+				t.Fatalf("process() returned error %v", err)
+			}
+			source, _ := doc.Source()
+			if diff := cmp.Diff(&tt.want, source.Processing); diff != "" {
+				t.Errorf("Unexpected diff %v", diff)
+			}
+		})
+	}
+}
+
+func TestCommandArgs(t *testing.T) {
+	tests := []struct {
+		args     []string
+		wantName string
+		wantArgs []string
+	}{
+		{args: []string{"stats", "-scene", "maze"}, wantName: "stats", wantArgs: []string{"-scene", "maze"}},
+		{args: []string{"-scene", "maze"}, wantName: "render", wantArgs: []string{"-scene", "maze"}},
+		{args: []string{"--list-scenes"}, wantName: "list", wantArgs: []string{}},
+		{args: []string{"--render-all"}, wantName: "render", wantArgs: []string{"-all"}},
+		{args: []string{"--render-all", "-jobs", "2"}, wantName: "render", wantArgs: []string{"-all", "-jobs", "2"}},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			name, args := commandArgs(tt.args)
+			if name != tt.wantName {
+				t.Errorf("Got command %s, want %s", name, tt.wantName)
+			}
+			if diff := cmp.Diff(tt.wantArgs, args); diff != "" {
+				t.Errorf("Unexpected diff %v", diff)
+			}
+		})
+	}
+}
@@ -50,12 +50,12 @@ func SumFloats(l []float64) float64 {
 	return total
 }
 
-func RandRangeMinusPlusOne() float64 {
-	return 2 * (rand.Float64() - 0.5)
+func RandRangeMinusPlusOne(r *rand.Rand) float64 {
+	return 2 * (r.Float64() - 0.5)
 }
 
-func RandInRange(min, max float64) float64 {
-	return (max-min)*rand.Float64() + min
+func RandInRange(r *rand.Rand, min, max float64) float64 {
+	return (max-min)*r.Float64() + min
 }
 
 // interpolate between a,b, with t in range [0,1]/ t=0 => a, t=1 => b
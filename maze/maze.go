@@ -3,6 +3,7 @@ package maze
 import (
 	"fmt"
 	"math/rand"
+	"os"
 	"slices"
 
 	"github.com/libeks/go-plotter-svg/lines"
@@ -103,7 +104,7 @@ func (g *Grid) CellCenterInBox(b primitives.BBox, x, y int) primitives.Point {
 	return b.NWCorner().Add(primitives.Vector{X: (float64(x) + 0.5) * edgeSize, Y: (float64(y) + 0.5) * edgeSize})
 }
 
-func NewMaze(size int) *Maze {
+func NewMaze(r *rand.Rand, size int) *Maze {
 	grid := NewGrid(size)
 	start := grid.At(0, 0)
 	start.visited = true
@@ -123,7 +124,7 @@ func NewMaze(size int) *Maze {
 		if len(possibilities) == 0 {
 			stack = stack[1:] // pop first item
 		} else {
-			dir := possibilities[rand.Intn(len(possibilities))]
+			dir := possibilities[r.Intn(len(possibilities))]
 			current.connections = append(current.connections, dir)
 			next := current.Direction(dir)
 			next.visited = true
@@ -152,7 +153,7 @@ func (m *Maze) Render(b primitives.BBox) MazeRender {
 	paths := []lines.LineLike{}
 	walls := []lines.LineLike{}
 
-	fmt.Printf("Calculating path...\n")
+	fmt.Fprintf(os.Stderr, "Calculating path...\n")
 	for len(stack) > 0 {
 		current := stack[0]
 		centerPoint := m.Grid.CellCenterInBox(b, current.X, current.Y)
@@ -174,7 +175,7 @@ func (m *Maze) Render(b primitives.BBox) MazeRender {
 		}
 		stack = stack[1:]
 	}
-	fmt.Printf("Done.\n")
+	fmt.Fprintf(os.Stderr, "Done.\n")
 
 	return MazeRender{
 		Path:  paths,
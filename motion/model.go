@@ -0,0 +1,47 @@
+package motion
+
+import (
+	"time"
+
+	"github.com/libeks/go-plotter-svg/primitives"
+)
+
+// Model describes how a plotter moves, to estimate how long a plot takes. Distances are in millimeters.
+type Model struct {
+	Drawing      Limits        // limits while the pen is down
+	Travel       Limits        // limits while the pen is up
+	PenUpDelay   time.Duration // time for the pen to lift
+	PenDownDelay time.Duration // time for the pen to come down
+	Tolerance    float64       // how closely the curves are followed by the polylines that are timed, in mm
+}
+
+// DefaultModel is an AxiDraw with its default settings
+func DefaultModel() Model {
+	return Model{
+		Drawing:      Limits{Speed: 50, Acceleration: 400, Cornering: 0.05},
+		Travel:       Limits{Speed: 150, Acceleration: 1000, Cornering: 0},
+		PenUpDelay:   150 * time.Millisecond,
+		PenDownDelay: 150 * time.Millisecond,
+		Tolerance:    0.05,
+	}
+}
+
+// DrawTime is how long it takes to draw the polyline with the pen down, starting and ending at rest, slowing
+// down for its corners
+func (m Model) DrawTime(points []primitives.Point) time.Duration {
+	return seconds(Duration(Plan(points, m.Drawing)))
+}
+
+// TravelTime is how long it takes to move in a straight line between the points with the pen up
+func (m Model) TravelTime(from, to primitives.Point) time.Duration {
+	return seconds(Duration(Plan([]primitives.Point{from, to}, m.Travel)))
+}
+
+// LiftTime is how long it takes to put the pen down and lift it again, once for every line drawn
+func (m Model) LiftTime() time.Duration {
+	return m.PenUpDelay + m.PenDownDelay
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
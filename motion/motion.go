@@ -0,0 +1,175 @@
+package motion
+
+import (
+	"math"
+
+	"github.com/libeks/go-plotter-svg/primitives"
+)
+
+// Limits constrain how fast the carriage can move, all values are in millimeters and seconds
+type Limits struct {
+	Speed        float64 // maximum speed, in mm/s
+	Acceleration float64 // maximum acceleration and deceleration, in mm/s^2
+	Cornering    float64 // junction deviation in mm, larger values take corners faster. Zero stops at every corner.
+}
+
+// Segment is a straight move with a trapezoidal velocity profile: accelerate from EntrySpeed to CruiseSpeed,
+// keep it, then decelerate to ExitSpeed. Short segments may never reach the maximum speed.
+type Segment struct {
+	Start        primitives.Point
+	End          primitives.Point
+	EntrySpeed   float64
+	CruiseSpeed  float64
+	ExitSpeed    float64
+	Acceleration float64
+}
+
+func (s Segment) Length() float64 {
+	return s.End.Subtract(s.Start).Len()
+}
+
+func (s Segment) accelDistance() float64 {
+	if s.Acceleration <= 0 {
+		return 0
+	}
+	return (s.CruiseSpeed*s.CruiseSpeed - s.EntrySpeed*s.EntrySpeed) / (2 * s.Acceleration)
+}
+
+func (s Segment) decelDistance() float64 {
+	if s.Acceleration <= 0 {
+		return 0
+	}
+	return (s.CruiseSpeed*s.CruiseSpeed - s.ExitSpeed*s.ExitSpeed) / (2 * s.Acceleration)
+}
+
+func (s Segment) accelTime() float64 {
+	if s.Acceleration <= 0 {
+		return 0
+	}
+	return (s.CruiseSpeed - s.EntrySpeed) / s.Acceleration
+}
+
+func (s Segment) decelTime() float64 {
+	if s.Acceleration <= 0 {
+		return 0
+	}
+	return (s.CruiseSpeed - s.ExitSpeed) / s.Acceleration
+}
+
+func (s Segment) cruiseTime() float64 {
+	if s.CruiseSpeed <= 0 {
+		return 0
+	}
+	return max(s.Length()-s.accelDistance()-s.decelDistance(), 0) / s.CruiseSpeed
+}
+
+// Duration is the time in seconds it takes to complete the segment
+func (s Segment) Duration() float64 {
+	return s.accelTime() + s.cruiseTime() + s.decelTime()
+}
+
+// DistanceAt returns how far along the segment the carriage is t seconds after starting it
+func (s Segment) DistanceAt(t float64) float64 {
+	ta, tc, td := s.accelTime(), s.cruiseTime(), s.decelTime()
+	switch {
+	case t <= 0:
+		return 0
+	case t < ta:
+		return s.EntrySpeed*t + s.Acceleration*t*t/2
+	case t < ta+tc:
+		return s.accelDistance() + s.CruiseSpeed*(t-ta)
+	case t < ta+tc+td:
+		dt := t - ta - tc
+		return s.accelDistance() + s.CruiseSpeed*tc + s.CruiseSpeed*dt - s.Acceleration*dt*dt/2
+	}
+	return s.Length()
+}
+
+// PointAt returns the position of the carriage t seconds after starting the segment
+func (s Segment) PointAt(t float64) primitives.Point {
+	length := s.Length()
+	if length == 0 {
+		return s.End
+	}
+	return s.Start.Add(s.End.Subtract(s.Start).Mult(s.DistanceAt(t) / length))
+}
+
+// Plan computes the velocity profile of a polyline that starts and ends at rest. Consecutive points that
+// coincide are dropped.
+func Plan(points []primitives.Point, limits Limits) []Segment {
+	segments := []Segment{}
+	for i := 1; i < len(points); i++ {
+		start := points[i-1]
+		if len(segments) > 0 {
+			start = segments[len(segments)-1].End
+		}
+		if points[i].Subtract(start).Len() == 0 {
+			continue
+		}
+		segments = append(segments, Segment{Start: start, End: points[i], Acceleration: limits.Acceleration})
+	}
+	if len(segments) == 0 {
+		return segments
+	}
+	// the fastest each junction can be taken, from the angle between the segments
+	junctions := make([]float64, len(segments)+1)
+	for i := 1; i < len(segments); i++ {
+		junctions[i] = junctionSpeed(segments[i-1], segments[i], limits)
+	}
+	if limits.Acceleration <= 0 {
+		// no acceleration limit, move at full speed throughout
+		for i := range segments {
+			segments[i].EntrySpeed = limits.Speed
+			segments[i].CruiseSpeed = limits.Speed
+			segments[i].ExitSpeed = limits.Speed
+		}
+		return segments
+	}
+	// backward pass, make sure that there's enough room to decelerate into each junction
+	for i := len(segments) - 1; i >= 0; i-- {
+		reachable := math.Sqrt(junctions[i+1]*junctions[i+1] + 2*limits.Acceleration*segments[i].Length())
+		junctions[i] = min(junctions[i], reachable)
+	}
+	// forward pass, make sure that there's enough room to accelerate out of each junction
+	for i := range segments {
+		reachable := math.Sqrt(junctions[i]*junctions[i] + 2*limits.Acceleration*segments[i].Length())
+		junctions[i+1] = min(junctions[i+1], reachable)
+	}
+	for i := range segments {
+		entry, exit := junctions[i], junctions[i+1]
+		// the highest speed at which the carriage can still decelerate in time, capped by the speed limit
+		peak := math.Sqrt((2*limits.Acceleration*segments[i].Length() + entry*entry + exit*exit) / 2)
+		segments[i].EntrySpeed = entry
+		segments[i].ExitSpeed = exit
+		segments[i].CruiseSpeed = max(min(peak, limits.Speed), entry, exit)
+	}
+	return segments
+}
+
+// junctionSpeed uses the junction deviation model: the corner is taken as if it were a circular arc that
+// deviates from the corner by at most limits.Cornering, at the speed where the centripetal acceleration
+// matches the acceleration limit.
+func junctionSpeed(in, out Segment, limits Limits) float64 {
+	u := in.End.Subtract(in.Start).Mult(1 / in.Length())
+	v := out.End.Subtract(out.Start).Mult(1 / out.Length())
+	cosTheta := -u.Dot(v)
+	if cosTheta < -0.999999 {
+		// going straight
+		return limits.Speed
+	}
+	if cosTheta > 0.999999 || limits.Cornering <= 0 {
+		// full reversal
+		return 0
+	}
+	sinHalfTheta := math.Sqrt((1 - cosTheta) / 2)
+	return min(math.Sqrt(limits.Acceleration*limits.Cornering*sinHalfTheta/(1-sinHalfTheta)), limits.Speed)
+}
+
+// Duration is the total time in seconds to move through all the segments
+func Duration(segments []Segment) float64 {
+	total := 0.0
+	for _, segment := range segments {
+		total += segment.Duration()
+	}
+	return total
+}
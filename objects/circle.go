@@ -43,6 +43,11 @@ func (c Circle) At(t float64) primitives.Point {
 	return c.Center.Add(primitives.Vector{X: c.Radius, Y: 0}.RotateCCW(t))
 }
 
+func (c Circle) BBox() primitives.BBox {
+	r := primitives.Vector{X: c.Radius, Y: c.Radius}
+	return primitives.BBox{UpperLeft: c.Center.Add(r.Mult(-1)), LowerRight: c.Center.Add(r)}
+}
+
 func (c Circle) IsEmpty() bool {
 	return c.Radius == 0
 }
@@ -185,6 +190,15 @@ func (c Circle) Bisect(t float64) (lines.Path, lines.Path) {
 		lines.NewPath(c.At(tRad)).AddPathChunk(lines.CircleArcChunk(c.Center, c.Radius, tRad, 2*math.Pi, true))
 }
 
+// Path returns the circle as a Path of two semicircle arcs, starting and ending at the left extreme point
+func (c Circle) Path() lines.Path {
+	return lines.NewPath(c.Start()).AddPathChunk(
+		lines.CircleArcChunk(c.Center, c.Radius, math.Pi, 2*math.Pi, true),
+	).AddPathChunk(
+		lines.CircleArcChunk(c.Center, c.Radius, 0, math.Pi, true),
+	)
+}
+
 func CircleInsideBox(b primitives.BBox) Circle {
 	return Circle{
 		Center: b.Center(),
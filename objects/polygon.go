@@ -3,6 +3,7 @@ package objects
 import (
 	"fmt"
 	"math"
+	"os"
 	"slices"
 
 	"github.com/libeks/go-plotter-svg/lines"
@@ -168,7 +169,7 @@ func (p Polygon) LargestContainedSquareBBox() primitives.BBox {
 			candidate := bbox.Translate(primitives.UnitRight.RotateCCW(math.Pi / 4.0 * float64(i)).Mult(bbox.Width() * 0.01))
 			candidate = candidate.Scale(1.005)
 			if math.Abs(1.0-candidate.Width()/candidate.Height()) > FLOAT_ACCURACY {
-				fmt.Printf("width %f, height %f\n", candidate.Width(), candidate.Height())
+				fmt.Fprintf(os.Stderr, "width %f, height %f\n", candidate.Width(), candidate.Height())
 				panic("polygon is not a square")
 			}
 			if p.isBBoxInside(candidate) {
@@ -246,7 +247,7 @@ func (p Polygon) LineFill(angle, spacing float64) []lines.LineLike {
 			lineLikes = append(lineLikes, segment)
 
 		} else if len(ts) > 2 {
-			fmt.Printf("ts %v\n", ts)
+			fmt.Fprintf(os.Stderr, "ts %v\n", ts)
 			panic("Unexpected t-values for line and polygon intersection")
 		}
 		reverse = !reverse
@@ -0,0 +1,187 @@
+// Package occlusion hides the strokes of shapes where shapes in front of them cover them, so that shapes that are
+// drawn as outlines look solid
+package occlusion
+
+import (
+	"math"
+	"slices"
+
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/objects"
+	"github.com/libeks/go-plotter-svg/primitives"
+)
+
+const (
+	// sampleSpacing is how far apart, in internal units, curves are sampled to find where they cross an outline.
+	// A curve that dips into an outline and out again in less than this may not be clipped.
+	sampleSpacing = 10.0
+	minSamples    = 16 // samples of the shortest curves
+	bisections    = 40 // halvings of the step between two samples that find where a curve crosses an outline
+	joinThreshold = 1e-6
+)
+
+// Shape is an outline that hides whatever is behind it, along with the strokes that draw it, which may be its
+// edge, a fill, or anything else
+type Shape struct {
+	Outline objects.Object
+	Strokes []lines.LineLike
+}
+
+// bounded is implemented by outlines that know their bounding box, which lets Visible skip the strokes that are
+// nowhere near them
+type bounded interface {
+	BBox() primitives.BBox
+}
+
+// Visible returns the parts of the strokes of the shapes, given from back to front, that aren't inside the
+// outline of any shape in front of them, in the order of the shapes
+func Visible(shapes []Shape) []lines.LineLike {
+	visible := []lines.LineLike{}
+	for i, shape := range shapes {
+		for _, stroke := range shape.Strokes {
+			if stroke == nil {
+				continue
+			}
+			box := lineBBox(stroke)
+			pieces := []lines.LineLike{stroke}
+			for _, front := range shapes[i+1:] {
+				if b, ok := front.Outline.(bounded); ok && !b.BBox().DoesIntersect(box) {
+					continue
+				}
+				outside := []lines.LineLike{}
+				for _, piece := range pieces {
+					_, out := Clip(piece, front.Outline)
+					outside = append(outside, out...)
+				}
+				pieces = outside
+				if len(pieces) == 0 {
+					break
+				}
+			}
+			visible = append(visible, pieces...)
+		}
+	}
+	return visible
+}
+
+// Clip splits the line into the parts inside of the object and those outside of it. Straight chunks are split
+// where they intersect the object, and other chunks, such as arcs and Beziers, where samples along them go in or
+// out of the object. A line that is all inside or all outside is returned as it is.
+func Clip(line lines.LineLike, obj objects.Object) ([]lines.LineLike, []lines.LineLike) {
+	inside, outside := &runs{}, &runs{}
+	for _, chunk := range lines.ToPath(line).Chunks() {
+		ts := append([]float64{0}, crossings(chunk, obj)...)
+		ts = append(ts, 1)
+		for k := 1; k < len(ts); k++ {
+			from, to := ts[k-1], ts[k]
+			if to-from < 1e-9 {
+				continue
+			}
+			if obj.Inside(chunk.At((from + to) / 2)) {
+				inside.add(section(chunk, from, to))
+			} else {
+				outside.add(section(chunk, from, to))
+			}
+		}
+	}
+	if len(inside.lines) == 0 {
+		return nil, []lines.LineLike{line}
+	}
+	if len(outside.lines) == 0 {
+		return []lines.LineLike{line}, nil
+	}
+	return inside.paths(), outside.paths()
+}
+
+// crossings returns the values of t in (0, 1), in order, where the chunk goes in or out of the object
+func crossings(chunk lines.PathChunk, obj objects.Object) []float64 {
+	ts := []float64{}
+	if c, ok := chunk.(lines.LineChunk); ok {
+		if c.Start == c.End {
+			return nil
+		}
+		for _, t := range obj.IntersectTs(lines.Line{P: c.Start, V: c.End.Subtract(c.Start)}) {
+			if t > 0 && t < 1 {
+				ts = append(ts, t)
+			}
+		}
+		slices.Sort(ts)
+		return ts
+	}
+	n := max(minSamples, int(math.Ceil(chunk.Length()/sampleSpacing)))
+	prev := obj.Inside(chunk.At(0))
+	for k := 1; k <= n; k++ {
+		t := float64(k) / float64(n)
+		in := obj.Inside(chunk.At(t))
+		if in != prev {
+			// the chunk crosses the outline between the two samples
+			lo, hi := float64(k-1)/float64(n), t
+			for range bisections {
+				mid := (lo + hi) / 2
+				if obj.Inside(chunk.At(mid)) == prev {
+					lo = mid
+				} else {
+					hi = mid
+				}
+			}
+			ts = append(ts, (lo+hi)/2)
+		}
+		prev = in
+	}
+	return ts
+}
+
+// section returns the part of the chunk between from and to
+func section(chunk lines.PathChunk, from, to float64) lines.PathChunk {
+	if to < 1 {
+		chunk, _ = chunk.Bisect(to)
+	}
+	if from > 0 {
+		_, chunk = chunk.Bisect(from / to)
+	}
+	return chunk
+}
+
+// runs collects chunks into paths, starting a new path wherever a chunk doesn't start where the last one ended
+type runs struct {
+	lines []lines.Path
+}
+
+func (r *runs) add(chunk lines.PathChunk) {
+	if n := len(r.lines); n > 0 && r.lines[n-1].End().Subtract(chunk.Startpoint()).Len() < joinThreshold {
+		r.lines[n-1] = r.lines[n-1].AddPathChunk(chunk)
+		return
+	}
+	r.lines = append(r.lines, lines.NewPath(chunk.Startpoint()).AddPathChunk(chunk))
+}
+
+func (r *runs) paths() []lines.LineLike {
+	ret := make([]lines.LineLike, len(r.lines))
+	for i, path := range r.lines {
+		ret[i] = path
+	}
+	return ret
+}
+
+// lineBBox is a box around the line, though not always the smallest one. Chunks other than straight ones, arcs
+// and those that know their bounding box are sampled, so they may stray a little outside of it.
+func lineBBox(line lines.LineLike) primitives.BBox {
+	points := []primitives.Point{line.Start(), line.End()}
+	for _, chunk := range lines.ToPath(line).Chunks() {
+		switch c := chunk.(type) {
+		case lines.LineChunk:
+			points = append(points, c.Start, c.End)
+		case lines.ArcChunk:
+			r := primitives.Vector{X: c.Radius(), Y: c.Radius()}
+			points = append(points, c.Center().Add(r), c.Center().Add(r.Mult(-1)))
+		case bounded:
+			b := c.BBox()
+			points = append(points, b.UpperLeft, b.LowerRight)
+		default:
+			for k := range minSamples + 1 {
+				points = append(points, chunk.At(float64(k)/minSamples))
+			}
+		}
+	}
+	return primitives.BBoxAroundPoints(points...)
+}
@@ -0,0 +1,94 @@
+package occlusion
+
+import (
+	"math"
+	"testing"
+
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/objects"
+	"github.com/libeks/go-plotter-svg/primitives"
+)
+
+func TestClip(t *testing.T) {
+	box := objects.PolygonFromBBox(primitives.BBox{UpperLeft: primitives.Point{X: 40, Y: -100}, LowerRight: primitives.Point{X: 60, Y: 100}})
+	circle := objects.Circle{Center: primitives.Point{X: 50, Y: 0}, Radius: 10}
+	tests := []struct {
+		name        string
+		line        lines.LineLike
+		obj         objects.Object
+		wantInside  [][2]float64 // the x of the ends of each part
+		wantOutside [][2]float64
+	}{
+		{
+			name:        "segment across a circle",
+			line:        lines.LineSegment{P1: primitives.Point{X: 0, Y: 0}, P2: primitives.Point{X: 100, Y: 0}},
+			obj:         circle,
+			wantInside:  [][2]float64{{40, 60}},
+			wantOutside: [][2]float64{{0, 40}, {60, 100}},
+		},
+		{
+			name:        "segment outside",
+			line:        lines.LineSegment{P1: primitives.Point{X: 0, Y: 50}, P2: primitives.Point{X: 100, Y: 50}},
+			obj:         circle,
+			wantOutside: [][2]float64{{0, 100}},
+		},
+		{
+			name: "bezier across a box",
+			line: lines.NewPath(primitives.Point{X: 0, Y: 0}).AddPathChunk(lines.CubicBezierChunk{
+				Start: primitives.Point{X: 0, Y: 0},
+				P1:    primitives.Point{X: 30, Y: 60},
+				P2:    primitives.Point{X: 70, Y: -60},
+				End:   primitives.Point{X: 100, Y: 0},
+			}),
+			obj:         box,
+			wantInside:  [][2]float64{{40, 60}},
+			wantOutside: [][2]float64{{0, 40}, {60, 100}},
+		},
+		{
+			name:        "circle across a box",
+			line:        objects.Circle{Center: primitives.Point{X: 50, Y: 0}, Radius: 20},
+			obj:         box,
+			wantInside:  [][2]float64{{40, 60}, {60, 40}},
+			wantOutside: [][2]float64{{30, 40}, {60, 60}, {40, 30}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inside, outside := Clip(tt.line, tt.obj)
+			check := func(kind string, got []lines.LineLike, want [][2]float64) {
+				if len(got) != len(want) {
+					t.Fatalf("Clip() returned %d parts %s, want %d", len(got), kind, len(want))
+				}
+				for i, part := range got {
+					if math.Abs(part.Start().X-want[i][0]) > 1e-6 || math.Abs(part.End().X-want[i][1]) > 1e-6 {
+						t.Errorf("part %d %s goes from %v to %v, want x from %.0f to %.0f", i, kind, part.Start(), part.End(), want[i][0], want[i][1])
+					}
+				}
+			}
+			check("inside", inside, tt.wantInside)
+			check("outside", outside, tt.wantOutside)
+		})
+	}
+}
+
+func TestVisible(t *testing.T) {
+	back := objects.Circle{Center: primitives.Point{X: 0, Y: 0}, Radius: 50}
+	front := objects.Circle{Center: primitives.Point{X: 50, Y: 0}, Radius: 50}
+	far := objects.Circle{Center: primitives.Point{X: 500, Y: 0}, Radius: 50}
+	visible := Visible([]Shape{
+		{Outline: back, Strokes: []lines.LineLike{back}},
+		{Outline: front, Strokes: []lines.LineLike{front, nil}},
+		{Outline: far, Strokes: []lines.LineLike{far}},
+	})
+	// the visible part of the back circle goes around its start, so it is split in two
+	if len(visible) != 4 {
+		t.Fatalf("Visible() returned %d strokes, want 4", len(visible))
+	}
+	// the front circle hides the third of the back circle that is within 60 degrees of its center
+	if got, want := (visible[0].Len()+visible[1].Len())/back.Path().Len(), 2.0/3; math.Abs(got-want) > 1e-6 {
+		t.Errorf("Visible() kept %.4f of the back circle, want %.4f", got, want)
+	}
+	if visible[2] != lines.LineLike(front) || visible[3] != lines.LineLike(far) {
+		t.Errorf("Visible() changed the strokes in front, got %v and %v", visible[2], visible[3])
+	}
+}
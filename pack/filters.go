@@ -2,6 +2,9 @@ package pack
 
 import (
 	"fmt"
+	"maps"
+	"os"
+	"slices"
 
 	// "golang.org/x/exp/maps"
 
@@ -102,7 +105,8 @@ func filterTwoPagesAddToLessThanContainer(states []*searchState, container primi
 		// fmt.Printf("page stats %v, %f\n", pageStats, pageStats[0].bboxArea/container.Area())
 		if len(pageStats) > 1 {
 			total := 0.0
-			for pageID, stat := range pageStats {
+			for _, pageID := range slices.Sorted(maps.Keys(pageStats)) {
+				stat := pageStats[pageID]
 				if pageID > 0 && total+stat.bboxArea/container.Area() < 1.0 {
 					shouldRemove = true
 				}
@@ -128,7 +132,8 @@ func filterWithSameFootprintButMoreArea(states []*searchState) []*searchState {
 		// TODO: see if the same set of objects on this page take up more area
 		key := ""
 		totalArea := 0.0
-		for page, stat := range stats {
+		for _, page := range slices.Sorted(maps.Keys(stats)) {
+			stat := stats[page]
 			st, err := stat.boxes.MarshalJSON()
 			if err != nil {
 				panic("Couldn't marshal")
@@ -151,8 +156,8 @@ func filterWithSameFootprintButMoreArea(states []*searchState) []*searchState {
 		}
 	}
 	states = []*searchState{}
-	for _, val := range stateMap {
-		states = append(states, val.searchState)
+	for _, key := range slices.Sorted(maps.Keys(stateMap)) {
+		states = append(states, stateMap[key].searchState)
 	}
 	// fmt.Printf("to %d ", len(stateMap))
 	// return maps.Values(stateMap)
@@ -184,8 +189,8 @@ func filterSameBoxesWithMoreArea(states []*searchState) []*searchState {
 		}
 	}
 	states = make([]*searchState, 0, len(stateMap))
-	for _, value := range stateMap {
-		states = append(states, value.searchState)
+	for _, key := range slices.Sorted(maps.Keys(stateMap)) {
+		states = append(states, stateMap[key].searchState)
 	}
 	return states
 }
@@ -194,22 +199,22 @@ func consolidateSearchStates(states []*searchState, container primitives.BBox) [
 	if len(states) < 2 {
 		return states
 	}
-	fmt.Printf("Consolidating\n  from %d... \n", len(states))
+	fmt.Fprintf(os.Stderr, "Consolidating\n  from %d... \n", len(states))
 
 	states = filterWithSameFootprintButMoreArea(states)
-	fmt.Printf("  to %d (same footprint, more area)\n", len(states))
+	fmt.Fprintf(os.Stderr, "  to %d (same footprint, more area)\n", len(states))
 
 	states = filterWithTooManyPages(states)
-	fmt.Printf("  to %d (too many pages)\n", len(states))
+	fmt.Fprintf(os.Stderr, "  to %d (too many pages)\n", len(states))
 
 	// states = filterFirstPageTooSmall(states, container)
 	// fmt.Printf("  to %d (first page too small)\n", len(states))
 
 	states = filterTwoPagesAddToLessThanContainer(states, container)
-	fmt.Printf("  to %d (multiple pages can be combined into one)\n", len(states))
+	fmt.Fprintf(os.Stderr, "  to %d (multiple pages can be combined into one)\n", len(states))
 
 	states = filterWithIncreasingBBoxesOnPages(states)
-	fmt.Printf("  to %d (pages must be decreasing)\n", len(states))
+	fmt.Fprintf(os.Stderr, "  to %d (pages must be decreasing)\n", len(states))
 
 	// TODO: reinstate this back once it does a statistical approach
 	// states = filterWithTooMuchUnusedSpace(states)
@@ -2,6 +2,7 @@ package pack
 
 import (
 	"fmt"
+	"os"
 	"slices"
 
 	"golang.org/x/exp/maps"
@@ -69,7 +70,7 @@ func packingAlgo(bxs []primitives.BBox, container primitives.BBox, padding float
 			index: i,
 		}
 		if b.Width() > container.Width() || b.Height() > container.Height() {
-			fmt.Printf("Box %v is too big for the container, will ignore\n", b)
+			fmt.Fprintf(os.Stderr, "Box %v is too big for the container, will ignore\n", b)
 			unprocessableBoxes.Set(uint32(i))
 			continue
 		}
@@ -92,7 +93,7 @@ func packingAlgo(bxs []primitives.BBox, container primitives.BBox, padding float
 	pass := 1
 	for len(searchStates) > 0 {
 		newStates := []*searchState{} // append to this slice, then swap this out with searchStates at the end
-		fmt.Printf("Pass %d: have %d search states\n", pass, len(searchStates))
+		fmt.Fprintf(os.Stderr, "Pass %d: have %d search states\n", pass, len(searchStates))
 		if len(searchStates) > MAX_STATES {
 			panic("Exceeded max allowed states, aborting")
 		}
@@ -200,7 +201,7 @@ func packingAlgo(bxs []primitives.BBox, container primitives.BBox, padding float
 				})
 			}
 			if len(newStates) > MAX_STATES {
-				fmt.Printf("Stopping pass early since we already have %d potential cases\n", len(newStates))
+				fmt.Fprintf(os.Stderr, "Stopping pass early since we already have %d potential cases\n", len(newStates))
 				newStates = newStates[:MAX_STATES]
 				break // stop adding more candidates if max states is exceeded
 			}
@@ -215,17 +216,17 @@ func packingAlgo(bxs []primitives.BBox, container primitives.BBox, padding float
 			DebugPositions: []PagedVector{},
 		}
 	}
-	fmt.Printf("Got %d possible solutions\n", len(finalStates))
+	fmt.Fprintf(os.Stderr, "Got %d possible solutions\n", len(finalStates))
 	solution := slices.MinFunc(finalStates, stateComparatorFunc)
-	fmt.Printf("Best solution has %d unplaceable, %d placeable boxes\n", solution.unprocessables.Count(), len(solution.processed))
+	fmt.Fprintf(os.Stderr, "Best solution has %d unplaceable, %d placeable boxes\n", solution.unprocessables.Count(), len(solution.processed))
 
-	fmt.Printf("Boxes are:\n")
+	fmt.Fprintf(os.Stderr, "Boxes are:\n")
 	for i, v := range solution.processed {
-		fmt.Printf("\t%d: %v\n", v.Page, boxes[i].Translate(v.Vector))
+		fmt.Fprintf(os.Stderr, "\t%d: %v\n", v.Page, boxes[i].Translate(v.Vector))
 	}
-	fmt.Printf("\nPositions are:\n")
+	fmt.Fprintf(os.Stderr, "\nPositions are:\n")
 	for _, pos := range solution.positions {
-		fmt.Printf("\t%d: %v\n", pos.Page, pos.Vector)
+		fmt.Fprintf(os.Stderr, "\t%d: %v\n", pos.Page, pos.Vector)
 	}
 	return PackingSolution{
 		Pages:          solution.Pages(),
@@ -172,7 +172,8 @@ type pageStat struct {
 func (s searchState) PageAreaStats() map[int]pageStat {
 	byPage := map[int]pageStat{}
 
-	for i, v := range s.processed {
+	for _, i := range s.processedIDs() {
+		v := s.processed[i]
 		page := v.Page
 		if _, ok := byPage[page]; !ok {
 			byPage[page] = pageStat{
@@ -200,8 +201,10 @@ func (s searchState) PageAreaStats() map[int]pageStat {
 func (s searchState) BBoxAreaSum() float64 {
 	total := 0.0
 	stats := s.PageAreaStats()
-	for _, stat := range stats {
-		total += stat.bboxArea
+	pages := maps.Keys(stats)
+	sort.Ints(pages)
+	for _, page := range pages {
+		total += stats[page].bboxArea
 	}
 	return total
 }
@@ -209,8 +212,16 @@ func (s searchState) BBoxAreaSum() float64 {
 // return the total area of each individual placed box
 func (s searchState) ProcessedAreaSum() float64 {
 	total := 0.0
-	for i := range s.processed {
+	for _, i := range s.processedIDs() {
 		total += s.boxes[i].Area()
 	}
 	return total
 }
+
+// processedIDs returns the indices of the placed boxes in order, so that the sums over them, which break ties
+// between states, come out the same on every run
+func (s searchState) processedIDs() []int {
+	ids := maps.Keys(s.processed)
+	sort.Ints(ids)
+	return ids
+}
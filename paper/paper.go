@@ -0,0 +1,142 @@
+package paper
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/libeks/go-plotter-svg/primitives"
+	"github.com/libeks/go-plotter-svg/units"
+)
+
+// Size is a sheet of paper, with its dimensions given in portrait orientation
+type Size struct {
+	Name   string
+	Width  float64    // in internal units
+	Height float64    // in internal units
+	Unit   units.Unit // the unit the size is usually given in
+}
+
+func mm(name string, width, height float64) Size {
+	return Size{name, units.Millimeters.To(width), units.Millimeters.To(height), units.Millimeters}
+}
+
+func in(name string, width, height float64) Size {
+	return Size{name, units.Inches.To(width), units.Inches.To(height), units.Inches}
+}
+
+var (
+	A3        = mm("a3", 297, 420)
+	A4        = mm("a4", 210, 297)
+	A5        = mm("a5", 148, 210)
+	Letter    = in("letter", 8.5, 11)
+	Card5x7   = in("5x7", 5, 7)
+	Sheet9x12 = in("9x12", 9, 12)
+
+	Sizes = []Size{A3, A4, A5, Letter, Card5x7, Sheet9x12}
+)
+
+// Lookup finds the paper size by its name, ignoring case
+func Lookup(name string) (Size, error) {
+	for _, size := range Sizes {
+		if strings.EqualFold(size.Name, name) {
+			return size, nil
+		}
+	}
+	names := []string{}
+	for _, size := range Sizes {
+		names = append(names, size.Name)
+	}
+	slices.Sort(names)
+	return Size{}, fmt.Errorf("unknown paper size '%s', expected one of %s", name, strings.Join(names, ", "))
+}
+
+type Orientation int
+
+const (
+	Landscape Orientation = iota
+	Portrait
+)
+
+func ParseOrientation(s string) (Orientation, error) {
+	switch strings.ToLower(s) {
+	case "landscape":
+		return Landscape, nil
+	case "portrait":
+		return Portrait, nil
+	}
+	return Landscape, fmt.Errorf("unknown orientation '%s', expected landscape or portrait", s)
+}
+
+func (o Orientation) String() string {
+	if o == Portrait {
+		return "portrait"
+	}
+	return "landscape"
+}
+
+// Layout is how a sheet of paper is laid out for plotting
+type Layout struct {
+	Size        Size
+	Orientation Orientation
+	Margin      float64    // distance in internal units between the edges of the paper and the drawing
+	Unit        units.Unit // unit of the SVG width and height
+}
+
+// Default is the 9"x12" landscape sheet that scenes were originally designed for
+func Default() Layout {
+	return Layout{
+		Size:        Sheet9x12,
+		Orientation: Landscape,
+		Margin:      500,
+		Unit:        units.Inches,
+	}
+}
+
+// Dimensions returns the width and height of the page in internal units, taking the orientation into account
+func (p Layout) Dimensions() (float64, float64) {
+	if p.Orientation == Landscape {
+		return max(p.Size.Width, p.Size.Height), min(p.Size.Width, p.Size.Height)
+	}
+	return min(p.Size.Width, p.Size.Height), max(p.Size.Width, p.Size.Height)
+}
+
+// BBox spans the whole page, in internal units
+func (p Layout) BBox() primitives.BBox {
+	w, h := p.Dimensions()
+	return primitives.BBox{UpperLeft: primitives.Origin, LowerRight: primitives.Point{X: w, Y: h}}
+}
+
+// Drawable is the part of the page inside of the margins
+func (p Layout) Drawable() primitives.BBox {
+	return p.BBox().WithPadding(p.Margin)
+}
+
+// SceneBox is where scenes are drawn on the page, inside of the margins and below the registration guides
+func (p Layout) SceneBox() primitives.BBox {
+	outerBox := p.BBox()
+	outerBox.UpperLeft.Y = 800 // leave space at the top for guides
+	return outerBox.WithPadding(p.Margin)
+}
+
+// SVGWidth is the width of the page in its unit, e.g. "297mm"
+func (p Layout) SVGWidth() string {
+	w, _ := p.Dimensions()
+	return p.Unit.Format(w)
+}
+
+// SVGHeight is the height of the page in its unit, e.g. "210mm"
+func (p Layout) SVGHeight() string {
+	_, h := p.Dimensions()
+	return p.Unit.Format(h)
+}
+
+// ViewBox maps the internal units onto the page
+func (p Layout) ViewBox() string {
+	w, h := p.Dimensions()
+	return fmt.Sprintf("0 0 %s %s", units.FormatNumber(w), units.FormatNumber(h))
+}
+
+func (p Layout) String() string {
+	return fmt.Sprintf("%s %s (%s x %s)", p.Size.Name, p.Orientation, p.SVGWidth(), p.SVGHeight())
+}
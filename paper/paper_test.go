@@ -0,0 +1,41 @@
+package paper
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/libeks/go-plotter-svg/units"
+)
+
+func TestLayout(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout Layout
+		want   []string // width, height, viewBox
+	}{
+		{
+			name:   "default",
+			layout: Default(),
+			want:   []string{"12in", "9in", "0 0 13333.33 10000"},
+		},
+		{
+			name:   "a4 portrait",
+			layout: Layout{Size: A4, Orientation: Portrait, Unit: units.Millimeters},
+			want:   []string{"210mm", "297mm", "0 0 9186.35 12992.13"},
+		},
+		{
+			name:   "5x7 landscape in pixels",
+			layout: Layout{Size: Card5x7, Orientation: Landscape, Unit: units.Pixels},
+			want:   []string{"672px", "480px", "0 0 7777.78 5555.56"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{tt.layout.SVGWidth(), tt.layout.SVGHeight(), tt.layout.ViewBox()}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Unexpected diff %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package pdf
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/libeks/go-plotter-svg/colors"
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/primitives"
+	"github.com/libeks/go-plotter-svg/scenes"
+	"github.com/libeks/go-plotter-svg/units"
+)
+
+const (
+	pointsPerInch = 72.0 // the unit of PDF user space
+
+	// objects are numbered: catalog, page tree, document info, the optional content groups, then a page
+	// and its content stream for each page
+	catalogID    = 1
+	pagesID      = 2
+	infoID       = 3
+	firstGroupID = 4
+)
+
+type Config struct {
+	Width        float64 // page width in internal units, like the SVG viewBox
+	Height       float64 // page height in internal units, like the SVG viewBox
+	UnitsPerInch float64 // how many internal units fit in an inch
+	Tolerance    float64 // maximum deviation in internal units when flattening chunks that have no Bezier form
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Width:        10000 * (12.0 / 9.0),
+		Height:       10000,
+		UnitsPerInch: units.Inch,
+		Tolerance:    1,
+	}
+}
+
+// PDF writes a whole document into a single PDF file, with one page per scenes.Page. Every layer name becomes
+// an optional content group, so the layers can be toggled in a PDF viewer.
+type PDF struct {
+	Fname string
+	Config
+	scenes.Document
+}
+
+func (p PDF) WritePDF() error {
+	fmt.Printf("trying to open file %s\n", p.Fname)
+	f, err := os.OpenFile(p.Fname, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := p.Write(f); err != nil {
+		return err
+	}
+	fmt.Printf("Finished rendering to file %s\n", p.Fname)
+	return nil
+}
+
+// Write writes the document as a PDF 1.5 file. Lines keep their curves, circle arcs are written as Bezier
+// curves, and everything is drawn at the physical size of the page.
+func (p PDF) Write(w io.Writer) error {
+	pages := make([]scenes.Page, p.Document.NumPages())
+	for i := range pages {
+		pages[i] = p.Document.Page(i)
+	}
+
+	// the optional content groups are shared between pages, so that hiding a layer hides it everywhere
+	groups := map[string]int{} // layer name to index of the group
+	groupNames := []string{}
+	for _, page := range pages {
+		for _, layer := range page.GetLayers() {
+			if _, ok := groups[layer.Name()]; !ok {
+				groups[layer.Name()] = len(groupNames)
+				groupNames = append(groupNames, layer.Name())
+			}
+		}
+	}
+
+	groupID := func(i int) int { return firstGroupID + i }
+	pageID := func(i int) int { return firstGroupID + len(groupNames) + 2*i }
+	contentID := func(i int) int { return pageID(i) + 1 }
+
+	f := &file{w: bufio.NewWriter(w)}
+	f.header()
+
+	groupRefs := make([]string, len(groupNames))
+	for i := range groupNames {
+		groupRefs[i] = fmt.Sprintf("%d 0 R", groupID(i))
+	}
+	f.object(catalogID, fmt.Sprintf(
+		"<< /Type /Catalog /Pages %d 0 R /OCProperties << /OCGs [%s] /D << /Order [%s] /ON [%s] >> >> >>",
+		pagesID, strings.Join(groupRefs, " "), strings.Join(groupRefs, " "), strings.Join(groupRefs, " "),
+	))
+	pageRefs := make([]string, len(pages))
+	for i := range pages {
+		pageRefs[i] = fmt.Sprintf("%d 0 R", pageID(i))
+	}
+	f.object(pagesID, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(pageRefs, " "), len(pages)))
+	f.object(infoID, "<< /Producer (go-plotter-svg) >>")
+	for i, name := range groupNames {
+		f.object(groupID(i), fmt.Sprintf("<< /Type /OCG /Name %s >>", literal(name)))
+	}
+
+	width := p.Width / p.UnitsPerInch * pointsPerInch
+	height := p.Height / p.UnitsPerInch * pointsPerInch
+	for i, page := range pages {
+		content, properties, err := p.content(page, groups)
+		if err != nil {
+			return fmt.Errorf("page %d: %w", i, err)
+		}
+		f.object(pageID(i), fmt.Sprintf(
+			"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources << /Properties << %s >> >> /Contents %d 0 R >>",
+			pagesID, number(width), number(height), properties, contentID(i),
+		))
+		if err := f.stream(contentID(i), content); err != nil {
+			return err
+		}
+	}
+	f.trailer(catalogID, infoID)
+	return f.w.Flush()
+}
+
+// content draws the layers of the page, in internal units, and returns the page's property resources
+// that map each group used on the page to its object
+func (p PDF) content(page scenes.Page, groups map[string]int) ([]byte, string, error) {
+	b := &bytes.Buffer{}
+	// scale the internal units to points, and flip the y-axis, since PDF's y-axis points up
+	scale := pointsPerInch / p.UnitsPerInch
+	fmt.Fprintf(b, "q %s 0 0 %s 0 %s cm\n1 J 1 j\n", number(scale), number(-scale), number(p.Height*scale))
+	used := map[int]bool{}
+	properties := []string{}
+	for _, layer := range page.GetLayers() {
+		c, err := colors.Parse(layer.Color())
+		if err != nil {
+			return nil, "", fmt.Errorf("layer '%s': %w", layer.Name(), err)
+		}
+		group := groups[layer.Name()]
+		if !used[group] {
+			used[group] = true
+			properties = append(properties, fmt.Sprintf("/OC%d %d 0 R", group, firstGroupID+group))
+		}
+		fmt.Fprintf(b, "/OC /OC%d BDC q\n", group)
+		fmt.Fprintf(b, "%s %s %s RG %s w\n",
+			number(float64(c.R)/255), number(float64(c.G)/255), number(float64(c.B)/255), number(layer.StrokeWidth()),
+		)
+		offset := layer.Offset()
+		for _, linelike := range layer.LineLikes() {
+			if linelike == nil || linelike.IsEmpty() {
+				continue
+			}
+			p.path(b, lines.ToPath(linelike.Translate(offset)))
+		}
+		fmt.Fprintf(b, "Q EMC\n")
+	}
+	fmt.Fprintf(b, "Q\n")
+	return b.Bytes(), strings.Join(properties, " "), nil
+}
+
+// path strokes a single path, keeping straight lines and Bezier curves as they are
+func (p PDF) path(b *bytes.Buffer, path lines.Path) {
+	fmt.Fprintf(b, "%s m\n", coords(path.Start()))
+	for _, chunk := range path.Chunks() {
+		switch c := chunk.(type) {
+		case lines.LineChunk:
+			fmt.Fprintf(b, "%s l\n", coords(c.End))
+		case lines.ArcChunk:
+			for _, bezier := range lines.ArcToBeziers(c) {
+				cubic(b, bezier)
+			}
+		case lines.QuadraticBezierChunk:
+			// raise the degree, PDF only has cubic curves
+			cubic(b, lines.CubicBezierChunk{
+				Start: c.Start,
+				P1:    c.Start.Add(c.P1.Subtract(c.Start).Mult(2.0 / 3)),
+				P2:    c.End.Add(c.P1.Subtract(c.End).Mult(2.0 / 3)),
+				End:   c.End,
+			})
+		case lines.CubicBezierChunk:
+			cubic(b, c)
+		default:
+			for _, pt := range lines.FlattenChunk(chunk, p.Tolerance) {
+				fmt.Fprintf(b, "%s l\n", coords(pt))
+			}
+		}
+	}
+	fmt.Fprintf(b, "S\n")
+}
+
+func cubic(b *bytes.Buffer, c lines.CubicBezierChunk) {
+	fmt.Fprintf(b, "%s %s %s c\n", coords(c.P1), coords(c.P2), coords(c.End))
+}
+
+func coords(pt primitives.Point) string {
+	return fmt.Sprintf("%s %s", number(pt.X), number(pt.Y))
+}
+
+// number formats a real number without trailing zeros, since PDF doesn't allow exponents
+func number(x float64) string {
+	s := strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.4f", x), "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
+
+// literal writes s as a PDF literal string
+func literal(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`, "\r", `\r`, "\n", `\n`)
+	return "(" + replacer.Replace(s) + ")"
+}
+
+// file keeps track of where each object starts, for the cross-reference table
+type file struct {
+	w       *bufio.Writer
+	offset  int
+	offsets map[int]int // object number to byte offset
+}
+
+func (f *file) write(s string) {
+	n, _ := f.w.WriteString(s)
+	f.offset += n
+}
+
+func (f *file) header() {
+	f.offsets = map[int]int{}
+	// the binary comment tells readers that the file contains binary data
+	f.write("%PDF-1.5\n%\xe2\xe3\xcf\xd3\n")
+}
+
+func (f *file) object(id int, dict string) {
+	f.offsets[id] = f.offset
+	f.write(fmt.Sprintf("%d 0 obj\n%s\nendobj\n", id, dict))
+}
+
+func (f *file) stream(id int, data []byte) error {
+	compressed := &bytes.Buffer{}
+	z := zlib.NewWriter(compressed)
+	if _, err := z.Write(data); err != nil {
+		return err
+	}
+	if err := z.Close(); err != nil {
+		return err
+	}
+	f.offsets[id] = f.offset
+	f.write(fmt.Sprintf("%d 0 obj\n<< /Length %d /Filter /FlateDecode >>\nstream\n", id, compressed.Len()))
+	f.write(compressed.String())
+	f.write("\nendstream\nendobj\n")
+	return nil
+}
+
+func (f *file) trailer(rootID, infoID int) {
+	start := f.offset
+	size := len(f.offsets) + 1
+	f.write(fmt.Sprintf("xref\n0 %d\n0000000000 65535 f \n", size))
+	for id := 1; id < size; id++ {
+		f.write(fmt.Sprintf("%010d 00000 n \n", f.offsets[id]))
+	}
+	f.write(fmt.Sprintf("trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", size, rootID, infoID, start))
+}
@@ -0,0 +1,79 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/primitives"
+	"github.com/libeks/go-plotter-svg/scenes"
+)
+
+func TestWrite(t *testing.T) {
+	line := lines.LineSegment{P1: primitives.Point{X: 0, Y: 0}, P2: primitives.Point{X: 1000, Y: 0}}
+	doc := scenes.Document{}.
+		AddPage(scenes.Page{}.AddLayer(scenes.NewLayer("red").WithLineLike([]lines.LineLike{line}).WithColor("red"))).
+		AddPage(scenes.Page{}.
+			AddLayer(scenes.NewLayer("red").WithLineLike([]lines.LineLike{line}).WithColor("red")).
+			AddLayer(scenes.NewLayer("blue (fine)").WithLineLike([]lines.LineLike{line}).WithColor("blue").WithOffset(10, 20)),
+		)
+	config := Config{Width: 1200, Height: 900, UnitsPerInch: 100, Tolerance: 1}
+	b := &bytes.Buffer{}
+	if err := (PDF{Config: config, Document: doc}).Write(b); err != nil {
+		t.Fatalf("Write() returned error %v", err)
+	}
+	out := b.String()
+
+	// every entry in the cross-reference table points at the start of its object
+	xref := out[strings.LastIndex(out, "xref\n"):]
+	for i, match := range regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(xref, -1) {
+		offset, _ := strconv.Atoi(match[1])
+		if want := fmt.Sprintf("%d 0 obj", i+1); !strings.HasPrefix(out[offset:], want) {
+			t.Errorf("xref entry %d points at %q, want %q", i+1, out[offset:offset+len(want)], want)
+		}
+	}
+
+	got := []string{}
+	for _, pattern := range []string{`/Count \d+`, `/MediaBox \[[^\]]*\]`, `/Type /OCG /Name \((?:[^()\\]|\\.)*\)`} {
+		got = append(got, regexp.MustCompile(pattern).FindAllString(out, -1)...)
+	}
+	want := []string{
+		"/Count 2",
+		"/MediaBox [0 0 864 648]",
+		"/MediaBox [0 0 864 648]",
+		"/Type /OCG /Name (red)",
+		`/Type /OCG /Name (blue \(fine\))`,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected diff %v", diff)
+	}
+
+	// the second page draws both groups, with the offset applied to the second one
+	streams := regexp.MustCompile(`(?s)stream\n(.*?)\nendstream`).FindAllStringSubmatch(out, -1)
+	if len(streams) != 2 {
+		t.Fatalf("got %d content streams, want 2", len(streams))
+	}
+	r, err := zlib.NewReader(strings.NewReader(streams[1][1]))
+	if err != nil {
+		t.Fatalf("zlib.NewReader() returned error %v", err)
+	}
+	content, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("reading the content stream returned error %v", err)
+	}
+	wantContent := "q 0.72 0 0 -0.72 0 648 cm\n1 J 1 j\n" +
+		"/OC /OC0 BDC q\n1 0 0 RG 3 w\n0 0 m\n1000 0 l\nS\nQ EMC\n" +
+		"/OC /OC1 BDC q\n0 0 1 RG 3 w\n10 20 m\n1010 20 l\nS\nQ EMC\n" +
+		"Q\n"
+	if diff := cmp.Diff(wantContent, string(content)); diff != "" {
+		t.Errorf("Unexpected diff in content %v", diff)
+	}
+}
@@ -1,5 +1,10 @@
 package pen
 
+import (
+	"fmt"
+	"strings"
+)
+
 type Pen struct {
 	Name    string
 	Spacing float64
@@ -32,3 +37,28 @@ var (
 
 	CrayolaSuperTips = Pen{"Crayola SuperTips", 25, -15, -5}
 )
+
+// Pens are all the pens above
+var Pens = []Pen{
+	Micron005, Micron01, Micron05, Micron10,
+	PilotG207,
+	TonborABTProThin, TonborABTProThick,
+	SharpieHighliter, SharpieCreativeMarker, UniballEcoJapanPen, WexfordGelInkPen, BicBU3Grip, SharpiePen,
+	BicIntensityFineTip, BicIntensityBrushTip,
+	ZebraSarasaPen,
+	CrayolaSuperTips,
+}
+
+// Lookup finds the pen by its name, ignoring case
+func Lookup(name string) (Pen, error) {
+	for _, p := range Pens {
+		if strings.EqualFold(p.Name, name) {
+			return p, nil
+		}
+	}
+	names := []string{}
+	for _, p := range Pens {
+		names = append(names, p.Name)
+	}
+	return Pen{}, fmt.Errorf("unknown pen '%s', expected one of %s", name, strings.Join(names, ", "))
+}
@@ -0,0 +1,137 @@
+package preview
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+
+	"golang.org/x/image/vector"
+
+	"github.com/libeks/go-plotter-svg/colors"
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/primitives"
+	"github.com/libeks/go-plotter-svg/scenes"
+	"github.com/libeks/go-plotter-svg/units"
+)
+
+// Preview rasterizes a document into PNG files, drawing each line at the width and color of its pen
+type Preview struct {
+	Fname  string
+	DPI    float64
+	Width  float64 // page width in internal units, like the SVG viewBox
+	Height float64 // page height in internal units, like the SVG viewBox
+	scenes.Document
+}
+
+// WritePNG writes each page of the document to its own file, named as in Document.PageFileNames
+func (p Preview) WritePNG() error {
+	for i, fname := range p.Document.PageFileNames(p.Fname) {
+		if err := p.writePageFile(fname, p.Document.Page(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p Preview) writePageFile(fname string, page scenes.Page) error {
+	img, err := p.Render(page)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("trying to open file %s\n", fname)
+	f, err := os.OpenFile(fname, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		return err
+	}
+	fmt.Printf("Finished rendering to file %s\n", fname)
+	return nil
+}
+
+// Render draws the page onto a white image, with the size of the page at p.DPI
+func (p Preview) Render(page scenes.Page) (*image.RGBA, error) {
+	scale := p.DPI / units.Inch // pixels per internal unit
+	bounds := image.Rect(0, 0, int(math.Ceil(p.Width*scale)), int(math.Ceil(p.Height*scale)))
+	img := image.NewRGBA(bounds)
+	draw.Draw(img, bounds, image.NewUniform(color.White), image.Point{}, draw.Src)
+	r := vector.NewRasterizer(bounds.Dx(), bounds.Dy())
+	for _, layer := range page.GetLayers() {
+		c, err := colors.Parse(layer.Color())
+		if err != nil {
+			return nil, fmt.Errorf("layer '%s': %w", layer.Name(), err)
+		}
+		s := stroker{
+			r:         r,
+			scale:     scale,
+			halfWidth: layer.StrokeWidth() * scale / 2,
+		}
+		r.Reset(bounds.Dx(), bounds.Dy())
+		offset := layer.Offset()
+		for _, linelike := range layer.LineLikes() {
+			if linelike == nil || linelike.IsEmpty() {
+				continue
+			}
+			// a quarter pixel is as precise as anyone can tell
+			s.stroke(lines.Flatten(linelike.Translate(offset), 0.25/scale))
+		}
+		r.Draw(img, bounds, image.NewUniform(c), image.Point{})
+	}
+	return img, nil
+}
+
+// stroker approximates a pen stroke by filling a quadrilateral for every segment, and a disc at every joint.
+// All the shapes wind the same way, so the rasterizer doesn't let overlapping shapes cancel out.
+type stroker struct {
+	r         *vector.Rasterizer
+	scale     float64
+	halfWidth float64 // in pixels
+}
+
+func (s stroker) toPixels(pt primitives.Point) primitives.Point {
+	return primitives.Point{X: pt.X * s.scale, Y: pt.Y * s.scale}
+}
+
+func (s stroker) stroke(points []primitives.Point) {
+	for i, pt := range points {
+		pt = s.toPixels(pt)
+		// joints of lines thinner than a pixel aren't visible, and there are a lot of them in hatched fills
+		if s.halfWidth >= 0.5 || i == 0 || i == len(points)-1 {
+			s.disc(pt)
+		}
+		if i == 0 {
+			continue
+		}
+		prev := s.toPixels(points[i-1])
+		direction := pt.Subtract(prev)
+		if direction.Len() == 0 {
+			continue
+		}
+		normal := primitives.Vector{X: -direction.Y, Y: direction.X}.Mult(s.halfWidth / direction.Len())
+		s.polygon([]primitives.Point{prev.Add(normal.Mult(-1)), pt.Add(normal.Mult(-1)), pt.Add(normal), prev.Add(normal)})
+	}
+}
+
+func (s stroker) disc(center primitives.Point) {
+	n := max(int(math.Ceil(2*math.Pi*s.halfWidth)), 8)
+	points := make([]primitives.Point, n)
+	for i := range n {
+		angle := 2 * math.Pi * float64(i) / float64(n)
+		points[i] = center.Add(primitives.Vector{X: math.Cos(angle), Y: math.Sin(angle)}.Mult(s.halfWidth))
+	}
+	s.polygon(points)
+}
+
+func (s stroker) polygon(points []primitives.Point) {
+	s.r.MoveTo(float32(points[0].X), float32(points[0].Y))
+	for _, pt := range points[1:] {
+		s.r.LineTo(float32(pt.X), float32(pt.Y))
+	}
+	s.r.ClosePath()
+}
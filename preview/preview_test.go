@@ -0,0 +1,48 @@
+package preview
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/primitives"
+	"github.com/libeks/go-plotter-svg/scenes"
+	"github.com/libeks/go-plotter-svg/units"
+)
+
+func TestWritePNG(t *testing.T) {
+	// a thick red line across the middle of a page of 2x1 inches
+	line := lines.LineSegment{P1: primitives.Point{X: 0, Y: units.Inch / 2}, P2: primitives.Point{X: 2 * units.Inch, Y: units.Inch / 2}}
+	doc := scenes.Document{}.AddLayer(scenes.NewLayer("red").WithLineLike([]lines.LineLike{line}).WithColor("red").WithWidth(units.Inch / 4))
+	fname := filepath.Join(t.TempDir(), "preview.png")
+	if err := (Preview{Fname: fname, DPI: 90, Width: 2 * units.Inch, Height: units.Inch, Document: doc}).WritePNG(); err != nil {
+		t.Fatalf("WritePNG() returned error %v", err)
+	}
+	f, err := os.Open(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatalf("Decoding the PNG returned error %v", err)
+	}
+	if diff := cmp.Diff(image.Rect(0, 0, 180, 90), img.Bounds()); diff != "" {
+		t.Errorf("Unexpected size %v", diff)
+	}
+	pixels := map[string]image.Point{"on the line": {90, 45}, "above the line": {90, 10}}
+	want := map[string]color.RGBA{"on the line": {255, 0, 0, 255}, "above the line": {255, 255, 255, 255}}
+	got := map[string]color.RGBA{}
+	for name, pt := range pixels {
+		got[name] = color.RGBAModel.Convert(img.At(pt.X, pt.Y)).(color.RGBA)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected diff %v", diff)
+	}
+}
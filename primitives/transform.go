@@ -0,0 +1,93 @@
+package primitives
+
+import (
+	"fmt"
+	"math"
+)
+
+// Transform is an affine transformation, with the same meaning as the SVG matrix(a b c d e f):
+//
+//	x' = A*x + C*y + E
+//	y' = B*x + D*y + F
+type Transform struct {
+	A, B, C, D, E, F float64
+}
+
+var Identity = Transform{A: 1, D: 1}
+
+func Translation(v Vector) Transform {
+	return Transform{A: 1, D: 1, E: v.X, F: v.Y}
+}
+
+func Scaling(sx, sy float64) Transform {
+	return Transform{A: sx, D: sy}
+}
+
+// Rotation rotates by rad radians, which is clockwise on screen since the y-axis points down
+func Rotation(rad float64) Transform {
+	cos, sin := math.Cos(rad), math.Sin(rad)
+	return Transform{A: cos, B: sin, C: -sin, D: cos}
+}
+
+func SkewX(rad float64) Transform {
+	return Transform{A: 1, C: math.Tan(rad), D: 1}
+}
+
+func SkewY(rad float64) Transform {
+	return Transform{A: 1, B: math.Tan(rad), D: 1}
+}
+
+func (t Transform) String() string {
+	return fmt.Sprintf("Transform(%.3f %.3f %.3f %.3f %.3f %.3f)", t.A, t.B, t.C, t.D, t.E, t.F)
+}
+
+// Multiply returns the transformation that applies u first, then t. This is the order in which an SVG
+// transform list like "translate(...) scale(...)" is applied.
+func (t Transform) Multiply(u Transform) Transform {
+	return Transform{
+		A: t.A*u.A + t.C*u.B,
+		B: t.B*u.A + t.D*u.B,
+		C: t.A*u.C + t.C*u.D,
+		D: t.B*u.C + t.D*u.D,
+		E: t.A*u.E + t.C*u.F + t.E,
+		F: t.B*u.E + t.D*u.F + t.F,
+	}
+}
+
+func (t Transform) Apply(p Point) Point {
+	return Point{
+		X: t.A*p.X + t.C*p.Y + t.E,
+		Y: t.B*p.X + t.D*p.Y + t.F,
+	}
+}
+
+// ApplyVector transforms a direction, ignoring the translation
+func (t Transform) ApplyVector(v Vector) Vector {
+	return Vector{
+		X: t.A*v.X + t.C*v.Y,
+		Y: t.B*v.X + t.D*v.Y,
+	}
+}
+
+func (t Transform) Determinant() float64 {
+	return t.A*t.D - t.B*t.C
+}
+
+// IsSimilarity returns true if the transformation preserves angles, i.e. it only rotates, scales uniformly,
+// reflects and translates. Circles stay circles under such a transformation.
+func (t Transform) IsSimilarity() bool {
+	x := Vector{X: t.A, Y: t.B}
+	y := Vector{X: t.C, Y: t.D}
+	scale := max(x.Len(), y.Len())
+	return math.Abs(x.Len()-y.Len()) <= 1e-9*scale && math.Abs(x.Dot(y)) <= 1e-9*scale*scale
+}
+
+// Scale is the factor by which the transformation scales lengths, only meaningful for similarities
+func (t Transform) Scale() float64 {
+	return math.Sqrt(math.Abs(t.Determinant()))
+}
+
+// Angle is the angle by which the transformation rotates the x-axis
+func (t Transform) Angle() float64 {
+	return math.Atan2(t.B, t.A)
+}
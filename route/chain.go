@@ -0,0 +1,106 @@
+package route
+
+import (
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/primitives"
+)
+
+// ChainResult reports how Chain went
+type ChainResult struct {
+	Before int // lines before chaining, which is how many times the pen went down
+	After  int
+}
+
+// Chain joins lines whose ends are no further than tolerance apart into continuous Paths, reversing lines where
+// that lets them join, so that the pen doesn't lift between them. Gaps between joined lines are bridged with a
+// straight line. Each chain grows from the first of its lines in the original order, first from its end and
+// then from its start, always to the nearest end that's close enough, and stops once it closes on itself. Lines
+// that don't touch any others are returned as they are, and nil lines are left out.
+func Chain(lns []lines.LineLike, tolerance float64) ([]lines.LineLike, ChainResult) {
+	original := make([]lines.LineLike, 0, len(lns))
+	for _, line := range lns {
+		if line != nil {
+			original = append(original, line)
+		}
+	}
+	points, ids := make([]primitives.Point, 0, 2*len(original)), make([]int, 0, 2*len(original))
+	for i, line := range original {
+		points = append(points, line.Start(), line.End()) // the start of line i is point 2i, and its end 2i+1
+		ids = append(ids, 2*i, 2*i+1)
+	}
+	index := newGrid(points, ids)
+	used := make([]bool, len(original))
+	take := func(i int) {
+		used[i] = true
+		index.remove(2 * i)
+		index.remove(2*i + 1)
+	}
+	chained := []lines.LineLike{}
+	for i, line := range original {
+		if used[i] {
+			continue
+		}
+		take(i)
+		before, after := []lines.LineLike{}, []lines.LineLike{line}
+		closed := func() bool {
+			return len(before)+len(after) > 1 && after[len(after)-1].End().Subtract(start(before, after)).Len() <= tolerance
+		}
+		for !closed() {
+			id := index.nearestWithin(after[len(after)-1].End(), tolerance)
+			if id < 0 {
+				break
+			}
+			take(id / 2)
+			next := original[id/2]
+			if id%2 == 1 {
+				next = next.Reverse()
+			}
+			after = append(after, next)
+		}
+		for !closed() {
+			id := index.nearestWithin(start(before, after), tolerance)
+			if id < 0 {
+				break
+			}
+			take(id / 2)
+			prev := original[id/2]
+			if id%2 == 0 {
+				prev = prev.Reverse()
+			}
+			before = append(before, prev)
+		}
+		chain := make([]lines.LineLike, 0, len(before)+len(after))
+		for k := len(before) - 1; k >= 0; k-- {
+			chain = append(chain, before[k])
+		}
+		chained = append(chained, join(append(chain, after...)))
+	}
+	return chained, ChainResult{Before: len(original), After: len(chained)}
+}
+
+// start is where a chain begins, given the lines that went before its first line, last first, and the rest
+func start(before, after []lines.LineLike) primitives.Point {
+	if len(before) > 0 {
+		return before[len(before)-1].Start()
+	}
+	return after[0].Start()
+}
+
+// join makes a single Path out of the chunks of the lines, bridging any gap between them with a straight line
+func join(chain []lines.LineLike) lines.LineLike {
+	if len(chain) == 1 {
+		return chain[0]
+	}
+	path := lines.NewPath(chain[0].Start())
+	end := chain[0].Start()
+	for _, line := range chain {
+		if line.Start() != end {
+			path = path.AddPathChunk(lines.LineChunk{Start: end, End: line.Start()})
+		}
+		for _, chunk := range lines.ToPath(line).Chunks() {
+			path = path.AddPathChunk(chunk)
+		}
+		end = line.End()
+	}
+	return path
+}
@@ -0,0 +1,99 @@
+package route
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/primitives"
+)
+
+func TestChain(t *testing.T) {
+	tests := []struct {
+		name      string
+		lines     []lines.LineLike
+		tolerance float64
+		want      [][]primitives.Point // the points of each line
+	}{
+		{
+			name:      "a polyline in pieces",
+			lines:     []lines.LineLike{segment(10, 0, 20, 0), segment(0, 0, 10, 0), segment(20, 0, 20, 10)},
+			tolerance: 0.1,
+			want:      [][]primitives.Point{{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 20, Y: 0}, {X: 20, Y: 10}}},
+		},
+		{
+			name:      "reversed pieces",
+			lines:     []lines.LineLike{segment(10, 0, 0, 0), segment(10, 0, 20, 0), segment(30, 0, 20, 0)},
+			tolerance: 0.1,
+			want:      [][]primitives.Point{{{X: 30, Y: 0}, {X: 20, Y: 0}, {X: 10, Y: 0}, {X: 0, Y: 0}}},
+		},
+		{
+			name:      "lines that don't touch",
+			lines:     []lines.LineLike{segment(0, 0, 10, 0), nil, segment(20, 0, 30, 0)},
+			tolerance: 0.1,
+			want:      [][]primitives.Point{{{X: 0, Y: 0}, {X: 10, Y: 0}}, {{X: 20, Y: 0}, {X: 30, Y: 0}}},
+		},
+		{
+			name:      "gaps within the tolerance are bridged",
+			lines:     []lines.LineLike{segment(0, 0, 10, 0), segment(11, 0, 20, 0)},
+			tolerance: 2,
+			want:      [][]primitives.Point{{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 11, Y: 0}, {X: 20, Y: 0}}},
+		},
+		{
+			name: "closed chains stop",
+			lines: []lines.LineLike{
+				segment(0, 0, 10, 0), segment(10, 0, 10, 10), segment(10, 10, 0, 10), segment(0, 10, 0, 0),
+				segment(0, 0, -10, 0),
+			},
+			tolerance: 0.1,
+			want: [][]primitives.Point{
+				{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}, {X: 0, Y: 10}, {X: 0, Y: 0}},
+				{{X: 0, Y: 0}, {X: -10, Y: 0}},
+			},
+		},
+		{
+			name:      "the nearest end wins",
+			lines:     []lines.LineLike{segment(0, 0, 10, 0), segment(10.5, 0, 20, 0), segment(10, 0, 10, 10)},
+			tolerance: 1,
+			want: [][]primitives.Point{
+				{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}},
+				{{X: 10.5, Y: 0}, {X: 20, Y: 0}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chained, result := Chain(tt.lines, tt.tolerance)
+			got := [][]primitives.Point{}
+			for _, line := range chained {
+				got = append(got, lines.ToPath(line).Points())
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Unexpected diff %v", diff)
+			}
+			if result.After != len(tt.want) {
+				t.Errorf("Chain() reported %d lines, want %d", result.After, len(tt.want))
+			}
+		})
+	}
+}
+
+// chaining the pieces of a path, some of them reversed, gives back a path of the same length
+func TestChainKeepsLength(t *testing.T) {
+	pieces := []lines.LineLike{}
+	path := lines.ToPath(segment(0, 0, 100, 0)).Join(lines.ToPath(segment(100, 0, 100, 100)))
+	for _, t := range []float64{0.2, 0.5, 0.9} {
+		var piece lines.Path
+		piece, path = path.Bisect(t)
+		pieces = append(pieces, piece.Reverse())
+	}
+	pieces = append(pieces, path)
+	chained, _ := Chain(pieces, 0.01)
+	if len(chained) != 1 {
+		t.Fatalf("Chain() returned %d lines, want 1", len(chained))
+	}
+	if got := chained[0].Len(); got < 200-1e-6 || got > 200+1e-6 {
+		t.Errorf("Chain() returned a line of length %f, want 200", got)
+	}
+}
@@ -0,0 +1,166 @@
+package route
+
+import (
+	"math"
+
+	"github.com/libeks/go-plotter-svg/primitives"
+)
+
+// pointsPerCell is roughly how many points share a cell of the grid
+const pointsPerCell = 2
+
+// grid is a spatial index of points, bucketed into square cells, so that the points nearest to a location are
+// found by searching the cells around it, ring by ring
+type grid struct {
+	points []primitives.Point // all the points, indexed by id, whether they're in the grid or not
+	corner primitives.Point   // upper left corner of the first cell
+	size   float64            // side of a cell
+	nx, ny int
+	cells  [][]int // ids of the points in each cell, row by row
+}
+
+// newGrid indexes the points with the given ids
+func newGrid(points []primitives.Point, ids []int) *grid {
+	g := &grid{points: points, size: 1, nx: 1, ny: 1}
+	if len(ids) > 0 {
+		minX, minY := math.Inf(1), math.Inf(1)
+		maxX, maxY := math.Inf(-1), math.Inf(-1)
+		for _, id := range ids {
+			p := points[id]
+			minX, minY = min(minX, p.X), min(minY, p.Y)
+			maxX, maxY = max(maxX, p.X), max(maxY, p.Y)
+		}
+		w, h := maxX-minX, maxY-minY
+		area := max(w*h, max(w, h)*max(w, h)/float64(len(ids)))
+		if area > 0 {
+			g.size = math.Sqrt(area * pointsPerCell / float64(len(ids)))
+		}
+		g.corner = primitives.Point{X: minX, Y: minY}
+		g.nx = int(w/g.size) + 1
+		g.ny = int(h/g.size) + 1
+	}
+	g.cells = make([][]int, g.nx*g.ny)
+	for _, id := range ids {
+		x, y := g.cell(points[id])
+		g.cells[y*g.nx+x] = append(g.cells[y*g.nx+x], id)
+	}
+	return g
+}
+
+// cell returns the coordinates of the cell that p falls in, which are outside the grid for points outside of it
+func (g *grid) cell(p primitives.Point) (int, int) {
+	return int(math.Floor((p.X - g.corner.X) / g.size)), int(math.Floor((p.Y - g.corner.Y) / g.size))
+}
+
+func (g *grid) remove(id int) {
+	x, y := g.cell(g.points[id])
+	cell := g.cells[y*g.nx+x]
+	for i, other := range cell {
+		if other == id {
+			cell[i] = cell[len(cell)-1]
+			g.cells[y*g.nx+x] = cell[:len(cell)-1]
+			return
+		}
+	}
+}
+
+// rings calls visit with the ids in the cells around p, one ring of cells at a time, until visit returns false
+// or there are no cells left. visit gets the distance from p that all the points of the later rings are beyond.
+func (g *grid) rings(p primitives.Point, visit func(ids []int, beyond float64) bool) {
+	cx, cy := g.cell(p)
+	first := max(0, -cx, cx-g.nx+1, -cy, cy-g.ny+1) // the rings before it are all outside of the grid
+	last := max(abs(cx), abs(cx-g.nx+1), abs(cy), abs(cy-g.ny+1))
+	for r := first; r <= last; r++ {
+		ids := []int{}
+		row := func(y int) {
+			if y >= 0 && y < g.ny {
+				for x := max(cx-r, 0); x <= min(cx+r, g.nx-1); x++ {
+					ids = append(ids, g.cells[y*g.nx+x]...)
+				}
+			}
+		}
+		column := func(x int) {
+			if x >= 0 && x < g.nx {
+				for y := max(cy-r+1, 0); y <= min(cy+r-1, g.ny-1); y++ {
+					ids = append(ids, g.cells[y*g.nx+x]...)
+				}
+			}
+		}
+		row(cy - r)
+		if r > 0 {
+			row(cy + r)
+			column(cx - r)
+			column(cx + r)
+		}
+		if !visit(ids, float64(r)*g.size) {
+			return
+		}
+	}
+}
+
+// nearest returns the id of the point closest to p, or -1 if the grid is empty
+func (g *grid) nearest(p primitives.Point) int {
+	best, bestDist := -1, math.Inf(1)
+	g.rings(p, func(ids []int, beyond float64) bool {
+		for _, id := range ids {
+			if d := g.points[id].Subtract(p).Len(); d < bestDist || (d == bestDist && id < best) {
+				best, bestDist = id, d
+			}
+		}
+		return best < 0 || bestDist > beyond
+	})
+	return best
+}
+
+// nearestWithin returns the id of the point closest to p that's no further than radius from it, or -1 if there's
+// no such point
+func (g *grid) nearestWithin(p primitives.Point, radius float64) int {
+	best, bestDist := -1, math.Inf(1)
+	g.rings(p, func(ids []int, beyond float64) bool {
+		for _, id := range ids {
+			if d := g.points[id].Subtract(p).Len(); d <= radius && (d < bestDist || (d == bestDist && id < best)) {
+				best, bestDist = id, d
+			}
+		}
+		return beyond <= radius && (best < 0 || bestDist > beyond)
+	})
+	return best
+}
+
+// nearestN returns the ids of up to n points closest to p, closest first, leaving out those that skip is true for
+func (g *grid) nearestN(p primitives.Point, n int, skip func(id int) bool) []int {
+	type candidate struct {
+		id   int
+		dist float64
+	}
+	best := []candidate{}
+	g.rings(p, func(ids []int, beyond float64) bool {
+		for _, id := range ids {
+			if skip(id) {
+				continue
+			}
+			c := candidate{id: id, dist: g.points[id].Subtract(p).Len()}
+			i := len(best)
+			for i > 0 && (best[i-1].dist > c.dist || (best[i-1].dist == c.dist && best[i-1].id > c.id)) {
+				i--
+			}
+			if i < n {
+				best = append(best[:i], append([]candidate{c}, best[i:]...)...)
+				best = best[:min(len(best), n)]
+			}
+		}
+		return len(best) < n || best[len(best)-1].dist > beyond
+	})
+	ret := make([]int, len(best))
+	for i, c := range best {
+		ret[i] = c.id
+	}
+	return ret
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
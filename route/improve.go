@@ -0,0 +1,245 @@
+package route
+
+import (
+	"time"
+
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/primitives"
+)
+
+const (
+	neighborCount = 8    // nearby line ends that moves are tried towards
+	maxRun        = 3    // longest run of lines that an Or-opt move takes elsewhere
+	minGain       = 1e-6 // moves that save less than this are not worth making, and could go back and forth forever
+	checkEvery    = 64   // positions that are tried between looking at the clock
+)
+
+// tour is an order of the lines, which moves change in place. Each line has two ends: end 2i is the start of
+// line i as it was given, and end 2i+1 its end. A flipped line is drawn from end 2i+1 to end 2i.
+type tour struct {
+	lns       []lines.LineLike
+	points    []primitives.Point // the position of each end
+	order     []int              // the line at each position
+	pos       []int              // the position of each line
+	flipped   []bool
+	reverse   bool    // whether lines may be flipped
+	neighbors [][]int // the ends nearest to each end, leaving out the other end of the same line
+}
+
+func newTour(lns []lines.LineLike, reverse bool) *tour {
+	t := &tour{
+		lns:     lns,
+		order:   make([]int, len(lns)),
+		pos:     make([]int, len(lns)),
+		flipped: make([]bool, len(lns)),
+		reverse: reverse,
+	}
+	ids := make([]int, 0, 2*len(lns))
+	for i, line := range lns {
+		t.points = append(t.points, line.Start(), line.End())
+		ids = append(ids, 2*i, 2*i+1)
+		t.order[i], t.pos[i] = i, i
+	}
+	index := newGrid(t.points, ids)
+	t.neighbors = make([][]int, len(t.points))
+	for id, p := range t.points {
+		t.neighbors[id] = index.nearestN(p, neighborCount, func(other int) bool { return other/2 == id/2 })
+	}
+	return t
+}
+
+// lines returns the lines in the order of the tour, reversing those that are flipped
+func (t *tour) lines() []lines.LineLike {
+	ret := make([]lines.LineLike, len(t.order))
+	for i, line := range t.order {
+		ret[i] = t.lns[line]
+		if t.flipped[line] {
+			ret[i] = ret[i].Reverse()
+		}
+	}
+	return ret
+}
+
+// entryEnd and exitEnd are the ends of a line where the pen goes down and up
+func (t *tour) entryEnd(line int) int {
+	if t.flipped[line] {
+		return 2*line + 1
+	}
+	return 2 * line
+}
+
+func (t *tour) exitEnd(line int) int {
+	return t.entryEnd(line) ^ 1
+}
+
+// entry and exit are where the pen goes down and up at a position, which is the origin before the first position
+// and after the last one
+func (t *tour) entry(pos int) primitives.Point {
+	if pos < 0 || pos >= len(t.order) {
+		return primitives.Origin
+	}
+	return t.points[t.entryEnd(t.order[pos])]
+}
+
+func (t *tour) exit(pos int) primitives.Point {
+	if pos < 0 || pos >= len(t.order) {
+		return primitives.Origin
+	}
+	return t.points[t.exitEnd(t.order[pos])]
+}
+
+func dist(a, b primitives.Point) float64 {
+	return b.Subtract(a).Len()
+}
+
+// improve makes moves until none of them help, or the deadline passes, if it isn't zero. It returns the number of
+// passes over the tour, and whether it stopped because no move helped.
+func (t *tour) improve(deadline time.Time) (int, bool) {
+	passes := 0
+	for {
+		passes++
+		improved := false
+		for i := 0; i <= len(t.order); i++ {
+			if !deadline.IsZero() && i%checkEvery == 0 && time.Now().After(deadline) {
+				return passes, false
+			}
+			if t.reverse && t.twoOpt(i) {
+				improved = true
+			}
+			for run := 1; run <= maxRun; run++ {
+				if t.orOpt(i, run) {
+					improved = true
+				}
+			}
+		}
+		if !improved {
+			return passes, true
+		}
+	}
+}
+
+// twoOpt tries to replace the move into position i, from the exit of the line before it, by reversing a run of
+// lines that starts or ends there, so that the move goes to a nearby line end instead
+func (t *tour) twoOpt(i int) bool {
+	n := len(t.order)
+	a, b := t.exit(i-1), t.entry(i)
+	if i > 0 {
+		// reverse positions i to j, which moves from a to the exit of j, and from the entry of i to after j
+		for _, end := range t.neighbors[t.exitEnd(t.order[i-1])] {
+			j := t.pos[end/2]
+			if j < i || end != t.exitEnd(t.order[j]) {
+				continue
+			}
+			gain := dist(a, b) + dist(t.exit(j), t.entry(j+1)) - dist(a, t.exit(j)) - dist(b, t.entry(j+1))
+			if gain > minGain {
+				t.reverseRun(i, j)
+				return true
+			}
+		}
+	}
+	if i < n {
+		// reverse positions j to i-1, which moves from before j to the exit of i-1, and from the entry of j to b
+		for _, end := range t.neighbors[t.entryEnd(t.order[i])] {
+			j := t.pos[end/2]
+			if j >= i || end != t.entryEnd(t.order[j]) {
+				continue
+			}
+			gain := dist(t.exit(j-1), t.entry(j)) + dist(a, b) - dist(t.exit(j-1), a) - dist(t.entry(j), b)
+			if gain > minGain {
+				t.reverseRun(j, i-1)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// reverseRun reverses the order of positions i to j, and flips each of their lines
+func (t *tour) reverseRun(i, j int) {
+	for ; i <= j; i, j = i+1, j-1 {
+		t.order[i], t.order[j] = t.order[j], t.order[i]
+		t.pos[t.order[i]], t.pos[t.order[j]] = i, j
+		t.flipped[t.order[i]] = !t.flipped[t.order[i]]
+		if i != j {
+			t.flipped[t.order[j]] = !t.flipped[t.order[j]]
+		}
+	}
+}
+
+// orOpt tries to take the run of lines at positions i to i+run-1 out of the tour, and put it back between two
+// positions elsewhere, next to a nearby line end, reversing the run if that's allowed and shorter
+func (t *tour) orOpt(i, run int) bool {
+	last := i + run - 1
+	if last >= len(t.order) {
+		return false
+	}
+	first, final := t.order[i], t.order[last]
+	s, e := t.entry(i), t.exit(last)
+	removed := dist(t.exit(i-1), s) + dist(e, t.entry(last+1)) - dist(t.exit(i-1), t.entry(last+1))
+	if removed <= minGain {
+		return false
+	}
+	type insertion struct {
+		after    int // the position that the run goes after, -1 for the start of the tour
+		reversed bool
+	}
+	candidates := []insertion{}
+	for _, end := range t.neighbors[t.entryEnd(first)] {
+		line := end / 2
+		if end == t.exitEnd(line) {
+			candidates = append(candidates, insertion{after: t.pos[line]})
+		} else if t.reverse {
+			candidates = append(candidates, insertion{after: t.pos[line] - 1, reversed: true})
+		}
+	}
+	for _, end := range t.neighbors[t.exitEnd(final)] {
+		line := end / 2
+		if end == t.entryEnd(line) {
+			candidates = append(candidates, insertion{after: t.pos[line] - 1})
+		} else if t.reverse {
+			candidates = append(candidates, insertion{after: t.pos[line], reversed: true})
+		}
+	}
+	for _, c := range candidates {
+		if c.after >= i-1 && c.after <= last {
+			continue // the run would end up where it is, or inside of itself
+		}
+		before, after := t.exit(c.after), t.entry(c.after+1)
+		added := dist(before, s) + dist(e, after)
+		if c.reversed {
+			added = dist(before, e) + dist(s, after)
+		}
+		if removed-(added-dist(before, after)) > minGain {
+			t.moveRun(i, last, c.after, c.reversed)
+			return true
+		}
+	}
+	return false
+}
+
+// moveRun moves positions i to last so that they come right after position after, which is outside of them
+func (t *tour) moveRun(i, last, after int, reversed bool) {
+	run := append([]int{}, t.order[i:last+1]...)
+	if reversed {
+		for k, j := 0, len(run)-1; k < j; k, j = k+1, j-1 {
+			run[k], run[j] = run[j], run[k]
+		}
+		for _, line := range run {
+			t.flipped[line] = !t.flipped[line]
+		}
+	}
+	from, to := i, after
+	if after > last {
+		// the lines between the run and its new place shift back
+		copy(t.order[i:], t.order[last+1:after+1])
+		copy(t.order[after-len(run)+1:], run)
+	} else {
+		// the lines between its new place and the run shift forward
+		from, to = after+1, last
+		copy(t.order[after+1+len(run):], t.order[after+1:i])
+		copy(t.order[after+1:], run)
+	}
+	for p := from; p <= to; p++ {
+		t.pos[t.order[p]] = p
+	}
+}
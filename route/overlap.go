@@ -0,0 +1,293 @@
+package route
+
+import (
+	"math"
+	"slices"
+
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/primitives"
+)
+
+// collinearAngle is the size of the buckets of directions that straight chunks are indexed by, in radians. Chunks
+// whose directions differ by less than this are always compared, others may not be.
+const collinearAngle = 0.01
+
+// OverlapResult reports how RemoveOverlaps went, in the units of the lines
+type OverlapResult struct {
+	Length  float64 // of the straight and circular chunks, before removing overlaps
+	Removed float64
+}
+
+// RemoveOverlaps removes the parts of straight chunks and circular arcs that retrace a chunk that comes before
+// them, within tolerance, so that each stroke is only drawn once. Lines that lose a part are split into Paths of
+// the parts that are left, dropping the leftovers of cut chunks that are no longer than tolerance. Other chunks, such as Beziers, are
+// kept as they are, and so are lines that don't overlap anything. Nil lines are left out.
+func RemoveOverlaps(lns []lines.LineLike, tolerance float64) ([]lines.LineLike, OverlapResult) {
+	o := newOverlaps(lns, tolerance)
+	ret := []lines.LineLike{}
+	for _, line := range lns {
+		if line == nil {
+			continue
+		}
+		if pieces, changed := o.pieces(line); changed {
+			ret = append(ret, pieces...)
+		} else {
+			ret = append(ret, line)
+		}
+	}
+	return ret, o.result
+}
+
+// overlaps indexes the chunks that have been drawn so far. Straight chunks are bucketed by their direction and
+// their distance from the origin along their normal, and arcs by their center and radius.
+type overlaps struct {
+	tolerance    float64
+	offsetBucket float64
+	segments     map[[2]int][]lines.LineChunk
+	arcs         map[[3]int][]arc
+	result       OverlapResult
+}
+
+// arc goes from the angle from to from+sweep around its center, where sweep is positive
+type arc struct {
+	center primitives.Point
+	radius float64
+	from   float64
+	sweep  float64
+}
+
+// interval is a part of a chunk, as distances along it from its start
+type interval struct {
+	from, to float64
+}
+
+func newOverlaps(lns []lines.LineLike, tolerance float64) *overlaps {
+	// chunks in the same bucket of directions drift apart further from the origin, so the buckets of offsets have
+	// to be wide enough to hold both of two overlapping chunks anywhere among the lines
+	extent := 0.0
+	for _, line := range lns {
+		if line == nil {
+			continue
+		}
+		for _, chunk := range lines.ToPath(line).Chunks() {
+			extent = max(extent, primitives.Vector(chunk.Startpoint()).Len(), primitives.Vector(chunk.Endpoint()).Len())
+		}
+	}
+	return &overlaps{
+		tolerance:    tolerance,
+		offsetBucket: 2*tolerance + collinearAngle*extent,
+		segments:     map[[2]int][]lines.LineChunk{},
+		arcs:         map[[3]int][]arc{},
+	}
+}
+
+// pieces returns what's left of the line once the overlapping parts of its chunks are taken out, and whether
+// anything was taken out
+func (o *overlaps) pieces(line lines.LineLike) ([]lines.LineLike, bool) {
+	ret := []lines.LineLike{}
+	var path lines.Path
+	open, changed := false, false
+	for _, chunk := range lines.ToPath(line).Chunks() {
+		pieces, removed := o.chunk(chunk)
+		changed = changed || removed
+		for _, piece := range pieces {
+			if !open || path.End().Subtract(piece.Startpoint()).Len() > closedThreshold {
+				if open {
+					ret = append(ret, path)
+				}
+				path, open = lines.NewPath(piece.Startpoint()), true
+			}
+			path = path.AddPathChunk(piece)
+		}
+	}
+	if open {
+		ret = append(ret, path)
+	}
+	return ret, changed
+}
+
+func (o *overlaps) chunk(chunk lines.PathChunk) ([]lines.PathChunk, bool) {
+	switch c := chunk.(type) {
+	case lines.LineChunk:
+		return o.segment(c)
+	case lines.ArcChunk:
+		return o.arc(c)
+	}
+	return []lines.PathChunk{chunk}, false
+}
+
+// carrier returns the direction of the line that the chunk lies on, as an angle in [0, π), and its signed
+// distance from the origin along its normal
+func carrier(c lines.LineChunk) (float64, float64) {
+	angle := c.End.Subtract(c.Start).Atan()
+	if angle < 0 {
+		angle += math.Pi
+	}
+	if angle >= math.Pi {
+		angle -= math.Pi
+	}
+	normal := primitives.UnitRight.RotateCCW(angle).Perp()
+	return angle, normal.Dot(primitives.Vector(c.Start))
+}
+
+var directionBuckets = int(math.Ceil(math.Pi / collinearAngle))
+
+func (o *overlaps) segmentKey(angle, offset float64) [2]int {
+	return [2]int{min(int(angle/collinearAngle), directionBuckets-1), int(math.Floor(offset / o.offsetBucket))}
+}
+
+// segmentKeys are the keys of the buckets that may hold chunks on the same line, including those on either
+// side of the horizontal, whose normals point the other way
+func (o *overlaps) segmentKeys(angle, offset float64) [][2]int {
+	key := o.segmentKey(angle, offset)
+	keys := [][2]int{}
+	for da := -1; da <= 1; da++ {
+		a, off := key[0]+da, offset
+		if a < 0 || a >= directionBuckets {
+			a, off = (a+directionBuckets)%directionBuckets, -offset
+		}
+		k := int(math.Floor(off / o.offsetBucket))
+		for dk := -1; dk <= 1; dk++ {
+			keys = append(keys, [2]int{a, k + dk})
+		}
+	}
+	return keys
+}
+
+func (o *overlaps) segment(c lines.LineChunk) ([]lines.PathChunk, bool) {
+	length := c.Length()
+	o.result.Length += length
+	if length == 0 {
+		return []lines.PathChunk{c}, false
+	}
+	u := c.End.Subtract(c.Start).Mult(1 / length)
+	n := u.Perp()
+	angle, offset := carrier(c)
+	covered := []interval{}
+	for _, key := range o.segmentKeys(angle, offset) {
+		for _, other := range o.segments[key] {
+			t1, t2 := other.Start.Subtract(c.Start).Dot(u), other.End.Subtract(c.Start).Dot(u)
+			h1, h2 := other.Start.Subtract(c.Start).Dot(n), other.End.Subtract(c.Start).Dot(n)
+			if t1 > t2 {
+				t1, t2, h1, h2 = t2, t1, h2, h1
+			}
+			from, to := max(t1, 0), min(t2, length)
+			if to-from <= o.tolerance {
+				continue // chunks that only touch, or cross, share less than this
+			}
+			// the other chunk has to be within tolerance of this one all along the part that they share
+			hFrom, hTo := h1+(h2-h1)*(from-t1)/(t2-t1), h1+(h2-h1)*(to-t1)/(t2-t1)
+			if math.Abs(hFrom) > o.tolerance || math.Abs(hTo) > o.tolerance {
+				continue
+			}
+			covered = append(covered, interval{from, to})
+		}
+	}
+	key := o.segmentKey(angle, offset)
+	o.segments[key] = append(o.segments[key], c)
+	left, removed := uncovered(covered, length, o.tolerance)
+	if removed == 0 {
+		return []lines.PathChunk{c}, false
+	}
+	o.result.Removed += removed
+	pieces := []lines.PathChunk{}
+	for _, part := range left {
+		pieces = append(pieces, lines.LineChunk{Start: c.Start.Add(u.Mult(part.from)), End: c.Start.Add(u.Mult(part.to))})
+	}
+	return pieces, true
+}
+
+func (o *overlaps) arcKey(a arc) [3]int {
+	return [3]int{
+		int(math.Floor(a.center.X / o.tolerance)),
+		int(math.Floor(a.center.Y / o.tolerance)),
+		int(math.Floor(a.radius / o.tolerance)),
+	}
+}
+
+func (o *overlaps) arc(c lines.ArcChunk) ([]lines.PathChunk, bool) {
+	a := arc{center: c.Center(), radius: c.Radius(), from: c.StartAngle(), sweep: c.Sweep()}
+	if a.sweep < 0 {
+		a.from, a.sweep = c.EndAngle(), -a.sweep
+	}
+	a.from = math.Mod(a.from, 2*math.Pi)
+	if a.from < 0 {
+		a.from += 2 * math.Pi
+	}
+	length := a.radius * a.sweep
+	o.result.Length += length
+	covered := []interval{}
+	key := o.arcKey(a)
+	for dx := -1; dx <= 1; dx++ {
+		for dy := -1; dy <= 1; dy++ {
+			for dr := -1; dr <= 1; dr++ {
+				for _, other := range o.arcs[[3]int{key[0] + dx, key[1] + dy, key[2] + dr}] {
+					if other.center.Subtract(a.center).Len() > o.tolerance || math.Abs(other.radius-a.radius) > o.tolerance {
+						continue
+					}
+					start := math.Mod(other.from-a.from, 2*math.Pi)
+					if start < 0 {
+						start += 2 * math.Pi
+					}
+					// the other arc may also wrap around past the angle that this one starts at
+					for _, s := range []float64{start - 2*math.Pi, start} {
+						from, to := max(s, 0), min(s+other.sweep, a.sweep)
+						if to-from > o.tolerance/a.radius {
+							covered = append(covered, interval{from * a.radius, to * a.radius})
+						}
+					}
+				}
+			}
+		}
+	}
+	o.arcs[key] = append(o.arcs[key], a)
+	left, removed := uncovered(covered, length, o.tolerance)
+	if removed == 0 {
+		return []lines.PathChunk{c}, false
+	}
+	o.result.Removed += removed
+	pieces := []lines.PathChunk{}
+	for _, part := range left {
+		var piece lines.PathChunk = lines.CircleArcChunk(a.center, a.radius, a.from+part.from/a.radius, a.from+part.to/a.radius, true)
+		if c.Sweep() < 0 {
+			piece = piece.Reverse()
+		}
+		pieces = append(pieces, piece)
+	}
+	if c.Sweep() < 0 {
+		slices.Reverse(pieces)
+	}
+	return pieces, true
+}
+
+// uncovered returns the parts of [0, length] outside of the covered intervals that are longer than tolerance,
+// and the length of the rest. A chunk that nothing covers is kept whole, however short it is.
+func uncovered(covered []interval, length, tolerance float64) ([]interval, float64) {
+	if len(covered) == 0 {
+		return []interval{{0, length}}, 0
+	}
+	slices.SortFunc(covered, func(a, b interval) int {
+		if a.from < b.from {
+			return -1
+		}
+		if a.from > b.from {
+			return 1
+		}
+		return 0
+	})
+	left, kept, pos := []interval{}, 0.0, 0.0
+	keep := func(to float64) {
+		if to-pos > tolerance {
+			left = append(left, interval{pos, to})
+			kept += to - pos
+		}
+	}
+	for _, c := range covered {
+		if c.from > pos {
+			keep(c.from)
+		}
+		pos = max(pos, c.to)
+	}
+	keep(length)
+	return left, length - kept
+}
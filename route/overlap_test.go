@@ -0,0 +1,170 @@
+package route
+
+import (
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/objects"
+	"github.com/libeks/go-plotter-svg/primitives"
+)
+
+func TestRemoveOverlaps(t *testing.T) {
+	tests := []struct {
+		name        string
+		lines       []lines.LineLike
+		tolerance   float64
+		want        [][]primitives.Point // the points of each line
+		wantRemoved float64
+	}{
+		{
+			name:        "a duplicate",
+			lines:       []lines.LineLike{segment(0, 0, 10, 0), segment(0, 0, 10, 0)},
+			tolerance:   0.1,
+			want:        [][]primitives.Point{{{X: 0, Y: 0}, {X: 10, Y: 0}}},
+			wantRemoved: 10,
+		},
+		{
+			name:        "a reversed duplicate within the tolerance",
+			lines:       []lines.LineLike{segment(0, 0, 10, 0), segment(10, 0.5, 0, 0.5)},
+			tolerance:   1,
+			want:        [][]primitives.Point{{{X: 0, Y: 0}, {X: 10, Y: 0}}},
+			wantRemoved: 10,
+		},
+		{
+			name:        "a partial overlap",
+			lines:       []lines.LineLike{segment(0, 0, 10, 0), segment(20, 0, 5, 0)},
+			tolerance:   0.1,
+			want:        [][]primitives.Point{{{X: 0, Y: 0}, {X: 10, Y: 0}}, {{X: 20, Y: 0}, {X: 10, Y: 0}}},
+			wantRemoved: 5,
+		},
+		{
+			name:        "an overlap in the middle",
+			lines:       []lines.LineLike{segment(4, 0, 6, 0), segment(0, 0, 10, 0)},
+			tolerance:   0.1,
+			want:        [][]primitives.Point{{{X: 4, Y: 0}, {X: 6, Y: 0}}, {{X: 0, Y: 0}, {X: 4, Y: 0}}, {{X: 6, Y: 0}, {X: 10, Y: 0}}},
+			wantRemoved: 2,
+		},
+		{
+			name:      "parallel lines further apart than the tolerance",
+			lines:     []lines.LineLike{segment(0, 0, 10, 0), segment(0, 2, 10, 2)},
+			tolerance: 1,
+			want:      [][]primitives.Point{{{X: 0, Y: 0}, {X: 10, Y: 0}}, {{X: 0, Y: 2}, {X: 10, Y: 2}}},
+		},
+		{
+			name:      "lines that cross or touch",
+			lines:     []lines.LineLike{segment(0, 0, 10, 0), segment(5, -5, 5, 5), segment(10, 0, 20, 0)},
+			tolerance: 0.1,
+			want:      [][]primitives.Point{{{X: 0, Y: 0}, {X: 10, Y: 0}}, {{X: 5, Y: -5}, {X: 5, Y: 5}}, {{X: 10, Y: 0}, {X: 20, Y: 0}}},
+		},
+		{
+			name:        "leftovers no longer than the tolerance",
+			lines:       []lines.LineLike{segment(0, 0, 10, 0), segment(-0.5, 0, 10, 0)},
+			tolerance:   1,
+			want:        [][]primitives.Point{{{X: 0, Y: 0}, {X: 10, Y: 0}}},
+			wantRemoved: 10.5,
+		},
+		{
+			name:      "short strokes that overlap nothing",
+			lines:     []lines.LineLike{segment(0, 0, 0.5, 0), segment(0, 5, 0.5, 5)},
+			tolerance: 1,
+			want:      [][]primitives.Point{{{X: 0, Y: 0}, {X: 0.5, Y: 0}}, {{X: 0, Y: 5}, {X: 0.5, Y: 5}}},
+		},
+		{
+			name: "a path that doubles back",
+			lines: []lines.LineLike{
+				lines.NewPath(primitives.Point{X: 0, Y: 0}).
+					AddPathChunk(lines.LineChunk{Start: primitives.Point{X: 0, Y: 0}, End: primitives.Point{X: 10, Y: 0}}).
+					AddPathChunk(lines.LineChunk{Start: primitives.Point{X: 10, Y: 0}, End: primitives.Point{X: 5, Y: 0}}).
+					AddPathChunk(lines.LineChunk{Start: primitives.Point{X: 5, Y: 0}, End: primitives.Point{X: 5, Y: 5}}),
+			},
+			tolerance:   0.1,
+			want:        [][]primitives.Point{{{X: 0, Y: 0}, {X: 10, Y: 0}}, {{X: 5, Y: 0}, {X: 5, Y: 5}}},
+			wantRemoved: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, result := RemoveOverlaps(tt.lines, tt.tolerance)
+			got := [][]primitives.Point{}
+			for _, line := range kept {
+				got = append(got, lines.ToPath(line).Points())
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Unexpected diff %v", diff)
+			}
+			if math.Abs(result.Removed-tt.wantRemoved) > 1e-9 {
+				t.Errorf("RemoveOverlaps() removed %f, want %f", result.Removed, tt.wantRemoved)
+			}
+		})
+	}
+}
+
+func TestRemoveOverlapsOfArcs(t *testing.T) {
+	center := primitives.Point{X: 100, Y: 100}
+	circle := objects.Circle{Center: center, Radius: 50}
+	half := 50 * math.Pi
+	tests := []struct {
+		name        string
+		lines       []lines.LineLike
+		wantRemoved float64
+		wantKept    float64 // the length of the arcs that are left, out of all of them
+	}{
+		{
+			name:     "a circle on its own",
+			lines:    []lines.LineLike{circle},
+			wantKept: 2 * half,
+		},
+		{
+			name:        "the same circle twice",
+			lines:       []lines.LineLike{circle, circle.Reverse()},
+			wantRemoved: 2 * half,
+			wantKept:    2 * half,
+		},
+		{
+			name: "an arc across the start of the circle",
+			lines: []lines.LineLike{
+				circle,
+				lines.NewPath(primitives.Point{}).AddPathChunk(lines.CircleArcChunk(center, 50.2, -math.Pi/2, math.Pi/2, true)),
+			},
+			wantRemoved: 50.2 * math.Pi,
+			wantKept:    2 * half,
+		},
+		{
+			name: "concentric circles",
+			lines: []lines.LineLike{
+				circle,
+				objects.Circle{Center: center, Radius: 52},
+			},
+			wantKept: 2*half + 2*52*math.Pi,
+		},
+		{
+			name: "a short arc on its own",
+			lines: []lines.LineLike{
+				lines.NewPath(primitives.Point{}).AddPathChunk(lines.CircleArcChunk(center, 50, 0, 0.005, true)),
+			},
+			wantKept: 50 * 0.005,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, result := RemoveOverlaps(tt.lines, 0.5)
+			if math.Abs(result.Removed-tt.wantRemoved) > 1e-6 {
+				t.Errorf("RemoveOverlaps() removed %f, want %f", result.Removed, tt.wantRemoved)
+			}
+			if got := result.Length - result.Removed; math.Abs(got-tt.wantKept) > 1e-6 {
+				t.Errorf("RemoveOverlaps() kept %f, want %f", got, tt.wantKept)
+			}
+			for _, line := range kept {
+				for _, chunk := range lines.ToPath(line).Chunks() {
+					arc := chunk.(lines.ArcChunk)
+					if arc.Radius() < 0 || math.Abs(arc.Sweep()) > 2*math.Pi {
+						t.Errorf("RemoveOverlaps() returned a bad arc %v", arc)
+					}
+				}
+			}
+		})
+	}
+}
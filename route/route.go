@@ -0,0 +1,156 @@
+// Package route orders the lines of a layer to cut down on the distance that the pen travels while it's up
+package route
+
+import (
+	"math"
+	"time"
+
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/primitives"
+)
+
+const (
+	closedThreshold = 0.1 // a line whose ends are closer than this is a closed curve
+	rotationSamples = 64  // points along a closed curve that are tried as its new start
+)
+
+// DefaultBudget is how long -optimize spends on improving the order of a layer, unless told otherwise
+const DefaultBudget = 10 * time.Second
+
+// Options control how Order may change the lines
+type Options struct {
+	Reverse      bool          // lines may be drawn from their end to their start
+	RotateClosed bool          // closed curves may start anywhere along them, such as a Circle or a closed Path
+	Budget       time.Duration // how long to spend on improving the greedy order, zero to improve it until no move helps
+}
+
+// Result reports how Order went. Travel is the distance that the pen moves up, in the units of the lines,
+// from the origin to the first line, between the lines, and from the last line back to the origin.
+type Result struct {
+	Lines     int
+	Before    float64 // travel in the original order
+	After     float64
+	Passes    int  // rounds of 2-opt and Or-opt moves over the whole order
+	Converged bool // whether the improvement stopped because no move helped, rather than because of the budget
+	Duration  time.Duration
+}
+
+// Saved is the fraction of the travel that the new order saves
+func (r Result) Saved() float64 {
+	if r.Before == 0 {
+		return 0
+	}
+	return 1 - r.After/r.Before
+}
+
+// Travel is the distance that the pen moves up when drawing the lines in order, starting and ending at the origin
+func Travel(lns []lines.LineLike) float64 {
+	total := 0.0
+	pt := primitives.Origin
+	for _, line := range lns {
+		if line != nil {
+			total += line.Start().Subtract(pt).Len()
+			pt = line.End()
+		}
+	}
+	return total + primitives.Origin.Subtract(pt).Len()
+}
+
+// Order returns the lines in an order with less pen-up travel, leaving out nil lines. It starts from the greedy
+// order, which always goes to the nearest line that's left, and improves on it with 2-opt moves, which reverse
+// a run of lines, and Or-opt moves, which move a run of up to three lines elsewhere, for as long as the budget
+// allows. 2-opt moves are only tried when lines may be reversed. If the result is no better than the original
+// order, the lines are returned in their original order.
+func Order(lns []lines.LineLike, opts Options) ([]lines.LineLike, Result) {
+	start := time.Now()
+	original := make([]lines.LineLike, 0, len(lns))
+	for _, line := range lns {
+		if line != nil {
+			original = append(original, line)
+		}
+	}
+	result := Result{Lines: len(original), Before: Travel(original)}
+	ordered := greedy(original, opts.Reverse)
+	if opts.RotateClosed {
+		ordered = rotateClosed(ordered)
+	}
+	deadline := time.Time{}
+	if opts.Budget > 0 {
+		deadline = start.Add(opts.Budget)
+	}
+	t := newTour(ordered, opts.Reverse)
+	result.Passes, result.Converged = t.improve(deadline)
+	ordered = t.lines()
+	if opts.RotateClosed {
+		ordered = rotateClosed(ordered)
+	}
+	result.After = Travel(ordered)
+	if result.After >= result.Before {
+		ordered, result.After = original, result.Before
+	}
+	result.Duration = time.Since(start)
+	return ordered, result
+}
+
+// greedy starts at the origin, and keeps going to the nearest end of the lines that are left
+func greedy(lns []lines.LineLike, reverse bool) []lines.LineLike {
+	points, ids := make([]primitives.Point, 0, 2*len(lns)), []int{}
+	for i, line := range lns {
+		points = append(points, line.Start(), line.End()) // the start of line i is point 2i, and its end 2i+1
+		ids = append(ids, 2*i)
+		if reverse {
+			ids = append(ids, 2*i+1)
+		}
+	}
+	index := newGrid(points, ids)
+	ordered := make([]lines.LineLike, 0, len(lns))
+	pt := primitives.Origin
+	for range lns {
+		id := index.nearest(pt)
+		i := id / 2
+		index.remove(2 * i)
+		if reverse {
+			index.remove(2*i + 1)
+		}
+		line := lns[i]
+		if id%2 == 1 {
+			line = line.Reverse()
+		}
+		ordered = append(ordered, line)
+		pt = line.End()
+	}
+	return ordered
+}
+
+func isClosed(line lines.LineLike) bool {
+	return line.Len() > 0 && line.Start().Subtract(line.End()).Len() < closedThreshold
+}
+
+// rotateClosed starts each closed curve at the point that makes for the shortest moves from the end of the line
+// before it and to the start of the line after it
+func rotateClosed(lns []lines.LineLike) []lines.LineLike {
+	ret := make([]lines.LineLike, len(lns))
+	prev := primitives.Origin
+	for i, line := range lns {
+		ret[i] = line
+		if isClosed(line) {
+			next := primitives.Origin
+			if i+1 < len(lns) {
+				next = lns[i+1].Start()
+			}
+			bestT, best := 0.0, math.Inf(1)
+			for k := range rotationSamples {
+				t := float64(k) / rotationSamples
+				p := line.At(t)
+				if d := p.Subtract(prev).Len() + next.Subtract(p).Len(); d < best {
+					bestT, best = t, d
+				}
+			}
+			if bestT > 0 {
+				ret[i] = lines.ToPath(line).StartAt(bestT)
+			}
+		}
+		prev = ret[i].End()
+	}
+	return ret
+}
@@ -0,0 +1,182 @@
+package route
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/primitives"
+)
+
+func segment(x1, y1, x2, y2 float64) lines.LineLike {
+	return lines.LineSegment{P1: primitives.Point{X: x1, Y: y1}, P2: primitives.Point{X: x2, Y: y2}}
+}
+
+func TestOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		lines      []lines.LineLike
+		opts       Options
+		want       []lines.LineLike
+		wantBefore float64
+		wantAfter  float64
+	}{
+		{
+			name:       "nearest first",
+			lines:      []lines.LineLike{segment(20, 0, 30, 0), segment(0, 0, 10, 0), segment(40, 0, 50, 0)},
+			want:       []lines.LineLike{segment(0, 0, 10, 0), segment(20, 0, 30, 0), segment(40, 0, 50, 0)},
+			wantBefore: 20 + 30 + 30 + 50,
+			wantAfter:  10 + 10 + 50,
+		},
+		{
+			name:       "without reversing",
+			lines:      []lines.LineLike{segment(10, 0, 0, 0), segment(30, 0, 20, 0)},
+			want:       []lines.LineLike{segment(30, 0, 20, 0), segment(10, 0, 0, 0)},
+			wantBefore: 10 + 30 + 20,
+			wantAfter:  30 + 10,
+		},
+		{
+			name:       "already in the best order",
+			lines:      []lines.LineLike{segment(0, 0, 0, 10), segment(10, 10, 10, 0)},
+			want:       []lines.LineLike{segment(0, 0, 0, 10), segment(10, 10, 10, 0)},
+			wantBefore: 10 + 10,
+			wantAfter:  10 + 10,
+		},
+		{
+			name:       "reversing",
+			lines:      []lines.LineLike{segment(10, 0, 0, 0), segment(30, 0, 20, 0)},
+			opts:       Options{Reverse: true},
+			want:       []lines.LineLike{segment(0, 0, 10, 0), segment(20, 0, 30, 0)},
+			wantBefore: 10 + 30 + 20,
+			wantAfter:  10 + 30,
+		},
+		{
+			name:       "nil lines are left out",
+			lines:      []lines.LineLike{nil, segment(0, 0, 10, 0), nil},
+			want:       []lines.LineLike{segment(0, 0, 10, 0)},
+			wantBefore: 10,
+			wantAfter:  10,
+		},
+		{
+			name:  "no lines",
+			lines: nil,
+			want:  []lines.LineLike{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, result := Order(tt.lines, tt.opts)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Unexpected diff %v", diff)
+			}
+			if result.Before != tt.wantBefore || result.After != tt.wantAfter {
+				t.Errorf("Order() travel went from %.1f to %.1f, want %.1f to %.1f", result.Before, result.After, tt.wantBefore, tt.wantAfter)
+			}
+		})
+	}
+}
+
+func randomSegments(n int) []lines.LineLike {
+	r := rand.New(rand.NewSource(1))
+	lns := []lines.LineLike{}
+	for range n {
+		p := primitives.Point{X: r.Float64() * 10000, Y: r.Float64() * 10000}
+		lns = append(lns, lines.LineSegment{P1: p, P2: p.Add(primitives.UnitRight.RotateCCW(r.Float64() * math.Pi).Mult(100))})
+	}
+	return lns
+}
+
+func TestOrderImprovesOnGreedy(t *testing.T) {
+	lns := randomSegments(1000)
+	for _, reverse := range []bool{true, false} {
+		_, result := Order(lns, Options{Reverse: reverse})
+		if greedy := Travel(greedy(lns, reverse)); result.After >= greedy {
+			t.Errorf("Order() travel is %.1f with reverse %v, want less than the greedy %.1f", result.After, reverse, greedy)
+		}
+		if !result.Converged {
+			t.Errorf("Order() didn't converge with reverse %v", reverse)
+		}
+	}
+}
+
+func TestOrderRotatesClosedCurves(t *testing.T) {
+	circle := lines.FullCircle(primitives.Point{X: 100, Y: 100}, 50)
+	got, result := Order([]lines.LineLike{circle}, Options{RotateClosed: true})
+	// the closest point to the origin is the one towards it, at 45 degrees from the axes
+	want := primitives.Point{X: 100 - 50/math.Sqrt2, Y: 100 - 50/math.Sqrt2}
+	if d := got[0].Start().Subtract(want).Len(); d > 1 {
+		t.Errorf("Order() starts the circle at %v, want %v", got[0].Start(), want)
+	}
+	if d := got[0].Start().Subtract(got[0].End()).Len(); d > closedThreshold {
+		t.Errorf("Order() opened up the circle, its ends are %.2f apart", d)
+	}
+	if math.Abs(got[0].Len()-circle.Len()) > 1e-6 {
+		t.Errorf("Order() changed the length of the circle from %.2f to %.2f", circle.Len(), got[0].Len())
+	}
+	if result.After >= result.Before {
+		t.Errorf("Order() travel went from %.1f to %.1f", result.Before, result.After)
+	}
+}
+
+// every line comes back exactly once, either as it was or reversed, however far the improvement got
+func TestOrderKeepsLines(t *testing.T) {
+	lns := randomSegments(2000)
+	key := func(line lines.LineLike) [4]float64 {
+		a, b := line.Start(), line.End()
+		if a.X > b.X || (a.X == b.X && a.Y > b.Y) {
+			a, b = b, a
+		}
+		return [4]float64{a.X, a.Y, b.X, b.Y}
+	}
+	keys := func(lns []lines.LineLike) [][4]float64 {
+		ret := [][4]float64{}
+		for _, line := range lns {
+			ret = append(ret, key(line))
+		}
+		slices.SortFunc(ret, func(a, b [4]float64) int { return slices.Compare(a[:], b[:]) })
+		return ret
+	}
+	for _, opts := range []Options{{Reverse: true}, {Reverse: true, Budget: time.Nanosecond}, {}} {
+		got, result := Order(lns, opts)
+		if diff := cmp.Diff(keys(lns), keys(got)); diff != "" {
+			t.Errorf("Order(%+v) changed the lines %v", opts, diff)
+		}
+		if math.Abs(Travel(got)-result.After) > 1e-6 {
+			t.Errorf("Order(%+v) reported travel %.1f, but it's %.1f", opts, result.After, Travel(got))
+		}
+		if result.After >= result.Before {
+			t.Errorf("Order(%+v) travel went from %.1f to %.1f", opts, result.Before, result.After)
+		}
+	}
+}
+
+func TestGridNearest(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	points, ids := []primitives.Point{}, []int{}
+	for i := range 500 {
+		points = append(points, primitives.Point{X: r.Float64() * 1000, Y: r.Float64() * 300})
+		ids = append(ids, i)
+	}
+	g := newGrid(points, ids)
+	for range 100 {
+		p := primitives.Point{X: r.Float64()*1400 - 200, Y: r.Float64()*700 - 200}
+		want := slices.Clone(ids)
+		slices.SortStableFunc(want, func(a, b int) int {
+			if da, db := points[a].Subtract(p).Len(), points[b].Subtract(p).Len(); da != db {
+				return int(math.Copysign(1, da-db))
+			}
+			return 0
+		})
+		if got := g.nearest(p); got != want[0] {
+			t.Errorf("nearest(%v) = %d, want %d", p, got, want[0])
+		}
+		if diff := cmp.Diff(want[:5], g.nearestN(p, 5, func(int) bool { return false })); diff != "" {
+			t.Errorf("nearestN(%v) has diff %v", p, diff)
+		}
+	}
+}
@@ -26,10 +26,11 @@ func (s constant) GetValue(p primitives.Point) float64 {
 }
 
 type RandomDataSource struct {
+	Rand *rand.Rand
 }
 
 func (s RandomDataSource) GetValue(p primitives.Point) float64 {
-	return rand.Float64()
+	return s.Rand.Float64()
 }
 
 type HighCenterRelativeDataSource struct {
@@ -99,11 +100,12 @@ func (s InsideCircleSubDataSource) GetValue(p primitives.Point) float64 {
 }
 
 type RandomChooser struct {
+	Rand   *rand.Rand
 	Values []float64
 }
 
 func (s RandomChooser) GetValue(p primitives.Point) float64 {
-	return s.Values[rand.Intn(len(s.Values))]
+	return s.Values[s.Rand.Intn(len(s.Values))]
 }
 
 func PointDistance(p primitives.Point) pointDistance {
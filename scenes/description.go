@@ -0,0 +1,522 @@
+package scenes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/libeks/go-plotter-svg/collections"
+	"github.com/libeks/go-plotter-svg/curve"
+	"github.com/libeks/go-plotter-svg/fonts"
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/maze"
+	"github.com/libeks/go-plotter-svg/objects"
+	"github.com/libeks/go-plotter-svg/pen"
+	"github.com/libeks/go-plotter-svg/primitives"
+	"github.com/libeks/go-plotter-svg/samplers"
+)
+
+// Description is a scene written in JSON, which composes the built-in generators into layers, so that it can
+// be added to the library without recompiling. Points and radii are fractions of the scene box, so that a
+// description fits any paper, while spacings, widths and sizes are in internal units, like those of the pens.
+type Description struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Tags        []string           `json:"tags,omitempty"`
+	Expensive   bool               `json:"expensive,omitempty"` // leave the scene out of render -all
+	Square      bool               `json:"square,omitempty"`    // draw in the largest square that fits in the scene box
+	Guides      bool               `json:"guides,omitempty"`    // draw the registration guides of the layers
+	Layers      []LayerDescription `json:"layers"`
+}
+
+// LayerDescription is a layer of a Description, drawn by all of its generators
+type LayerDescription struct {
+	Name       string                 `json:"name"`
+	Color      string                 `json:"color,omitempty"`
+	Width      float64                `json:"width,omitempty"`
+	Pen        string                 `json:"pen,omitempty"` // name of one of pen.Pens, which also sets the offset of the layer
+	NoGuide    bool                   `json:"no_guide,omitempty"`
+	Optimize   bool                   `json:"optimize,omitempty"` // reorder the lines to cut down on pen-up travel
+	Generators []GeneratorDescription `json:"generators"`
+}
+
+// GeneratorDescription picks a generator by its type. Each generator uses some of the other fields, see
+// descriptionGenerators for which.
+type GeneratorDescription struct {
+	Type       string              `json:"type"`
+	Box        *[4]float64         `json:"box,omitempty"`   // part of the scene box to draw in, as x1, y1, x2, y2 fractions
+	Clip       *ClipDescription    `json:"clip,omitempty"`  // only keep the lines inside, by default the lines are kept inside the box
+	Angle      float64             `json:"angle,omitempty"` // in degrees, clockwise from the x-axis
+	Spacing    float64             `json:"spacing,omitempty"`
+	Center     *[2]float64         `json:"center,omitempty"` // the center of the box by default
+	N          int                 `json:"n,omitempty"`
+	Tiles      string              `json:"tiles,omitempty"`
+	Curve      string              `json:"curve,omitempty"`
+	Sampler    *SamplerDescription `json:"sampler,omitempty"`
+	Thresholds []float64           `json:"thresholds,omitempty"`
+	Show       []string            `json:"show,omitempty"`
+	Text       string              `json:"text,omitempty"`
+	Font       string              `json:"font,omitempty"`
+	Size       float64             `json:"size,omitempty"`
+	Points     [][2]float64        `json:"points,omitempty"`
+}
+
+// ClipDescription is a shape that generators clip their lines to. A composite keeps what's inside any of With,
+// but not inside any of Without.
+type ClipDescription struct {
+	Type    string            `json:"type"` // box, polygon, circle or composite
+	Box     *[4]float64       `json:"box,omitempty"`
+	Points  [][2]float64      `json:"points,omitempty"`
+	Center  *[2]float64       `json:"center,omitempty"`
+	Radius  float64           `json:"radius,omitempty"` // as a fraction of the smaller side of the scene box
+	With    []ClipDescription `json:"with,omitempty"`
+	Without []ClipDescription `json:"without,omitempty"`
+}
+
+// SamplerDescription is a field of values, for the marching squares generator
+type SamplerDescription struct {
+	Type   string       `json:"type"`             // perlin, or distance to the nearest of the points
+	Scale  float64      `json:"scale,omitempty"`  // of the perlin noise
+	Points [][2]float64 `json:"points,omitempty"` // of the distance
+}
+
+// generate draws the lines of a generator in the scene box b
+type generate func(b primitives.BBox, r *rand.Rand) []lines.LineLike
+
+type descriptionGenerator struct {
+	random bool // whether it takes random numbers from r
+	clips  bool // whether it supports clip
+	build  func(g GeneratorDescription) (generate, error)
+}
+
+var curveMappers = map[string]curve.CurveMapper{
+	"circular":        curve.MapCircularCurve,
+	"circular-circle": curve.MapCircularCircleCurve,
+	"curly":           curve.MapCurlyCurve,
+	"blocky":          curve.BlockyCurveMapper,
+	"straight":        curve.MapStraightLines,
+}
+
+// descriptionGenerators are the generators that descriptions can use, by type
+var descriptionGenerators = map[string]descriptionGenerator{
+	// the outline of the box
+	"frame": {build: func(g GeneratorDescription) (generate, error) {
+		return func(b primitives.BBox, _ *rand.Rand) []lines.LineLike {
+			return lines.LinesFromBBox(g.region(b))
+		}, nil
+	}},
+	// parallel lines at angle, spacing apart, see collections.LinearLineField
+	"line-field": {clips: true, build: func(g GeneratorDescription) (generate, error) {
+		if g.Spacing <= 0 {
+			return nil, fmt.Errorf("spacing must be positive")
+		}
+		return func(b primitives.BBox, _ *rand.Rand) []lines.LineLike {
+			region := g.region(b)
+			field := collections.LinearLineField(region, g.Angle*math.Pi/180, g.Spacing)
+			return lines.SegmentsToLineLikes(collections.LimitLinesToShape(field, g.Clip.object(b, region)))
+		}, nil
+	}},
+	// circles around center, spacing apart, see collections.ConcentricCircles
+	"concentric-circles": {clips: true, build: func(g GeneratorDescription) (generate, error) {
+		if g.Spacing <= 0 {
+			return nil, fmt.Errorf("spacing must be positive")
+		}
+		return func(b primitives.BBox, _ *rand.Rand) []lines.LineLike {
+			region := g.region(b)
+			circles := collections.ConcentricCircles(region, pointOrCenter(g.Center, b, region), g.Spacing)
+			return collections.LimitCirclesToShape(circles, g.Clip.object(b, region))
+		}, nil
+	}},
+	// the outline of the polygon of points, filled with lines at angle, spacing apart
+	"polygon-fill": {build: func(g GeneratorDescription) (generate, error) {
+		if len(g.Points) < 3 {
+			return nil, fmt.Errorf("a polygon needs at least 3 points, got %d", len(g.Points))
+		}
+		if g.Spacing <= 0 {
+			return nil, fmt.Errorf("spacing must be positive")
+		}
+		return func(b primitives.BBox, _ *rand.Rand) []lines.LineLike {
+			return collections.FillPolygonWithSpacing(polygon(g.Points, b), g.Spacing, g.Angle*math.Pi/180)
+		}, nil
+	}},
+	// a grid of n by n truchet tiles, picked at random out of the set of tiles, drawn with the curve
+	"truchet": {random: true, build: func(g GeneratorDescription) (generate, error) {
+		if g.N < 1 {
+			return nil, fmt.Errorf("n must be at least 1")
+		}
+		tiles, ok := truchetTileSets[g.Tiles]
+		if !ok {
+			return nil, fmt.Errorf("unknown tiles '%s', expected one of %s", g.Tiles, strings.Join(truchetTileSetNames(), ", "))
+		}
+		mapper, err := lookupCurveMapper(g.Curve)
+		if err != nil {
+			return nil, err
+		}
+		return func(b primitives.BBox, r *rand.Rand) []lines.LineLike {
+			grid := curve.NewTruchetGrid(g.region(b).Square(), g.N, tiles, samplers.RandomDataSource{Rand: r}, samplers.Constant(0.5), mapper)
+			return grid.GenerateCurves()
+		}, nil
+	}},
+	// the contours of the sampler at each of the thresholds, on a grid of n cells across
+	"marching-squares": {build: func(g GeneratorDescription) (generate, error) {
+		if g.N < 1 {
+			return nil, fmt.Errorf("n must be at least 1")
+		}
+		if len(g.Thresholds) == 0 {
+			return nil, fmt.Errorf("thresholds are missing")
+		}
+		if g.Sampler == nil {
+			return nil, fmt.Errorf("sampler is missing")
+		}
+		if err := g.Sampler.check(); err != nil {
+			return nil, err
+		}
+		return func(b primitives.BBox, _ *rand.Rand) []lines.LineLike {
+			region := g.region(b)
+			// the grid samples its source relative to the corner of the region, while samplers use scene coordinates
+			sampler := samplers.Displace(g.Sampler.source(b), primitives.Vector(region.UpperLeft))
+			curves := []lines.LineLike{}
+			for _, threshold := range g.Thresholds {
+				curves = append(curves, curve.NewMarchingGrid(region, g.N, sampler, threshold).GenerateCurves()...)
+			}
+			return curves
+		}, nil
+	}},
+	// a random maze of n by n cells, showing its walls, its path, or both
+	"maze": {random: true, build: func(g GeneratorDescription) (generate, error) {
+		if g.N < 1 {
+			return nil, fmt.Errorf("n must be at least 1")
+		}
+		show := g.Show
+		if len(show) == 0 {
+			show = []string{"walls"}
+		}
+		for _, part := range show {
+			if part != "walls" && part != "path" {
+				return nil, fmt.Errorf("show must list walls or path, got '%s'", part)
+			}
+		}
+		return func(b primitives.BBox, r *rand.Rand) []lines.LineLike {
+			render := maze.NewMaze(r, g.N).Render(g.region(b).Square())
+			ret := []lines.LineLike{}
+			if slices.Contains(show, "walls") {
+				ret = append(ret, render.Walls...)
+			}
+			if slices.Contains(show, "path") {
+				ret = append(ret, render.Path...)
+			}
+			return ret
+		}, nil
+	}},
+	// the outlines of the text in a TrueType font, centered in the box, see fonts.RenderText
+	"text": {build: func(g GeneratorDescription) (generate, error) {
+		if g.Text == "" {
+			return nil, fmt.Errorf("text is missing")
+		}
+		size := g.Size
+		if size <= 0 {
+			size = 1000
+		}
+		return func(b primitives.BBox, _ *rand.Rand) []lines.LineLike {
+			if g.Font == "" {
+				return fonts.RenderText(g.region(b), g.Text, fonts.WithSize(size), fonts.WithFitToBox()).CharCurves
+			}
+			return fonts.RenderText(g.region(b), g.Text, fonts.WithSize(size), fonts.WithFitToBox(), fonts.WithFont(g.Font)).CharCurves
+		}, nil
+	}},
+	// the text in single strokes, size tall, centered in the box, see fonts.StrokeText
+	"stroke-text": {build: func(g GeneratorDescription) (generate, error) {
+		if g.Text == "" {
+			return nil, fmt.Errorf("text is missing")
+		}
+		if g.Size <= 0 {
+			return nil, fmt.Errorf("size must be positive")
+		}
+		return func(b primitives.BBox, _ *rand.Rand) []lines.LineLike {
+			center := g.region(b).Center()
+			corner := center.Add(primitives.Vector{X: -fonts.StrokeTextWidth(g.Text, g.Size) / 2, Y: -g.Size / 2})
+			return fonts.StrokeText(g.Text, corner, g.Size)
+		}, nil
+	}},
+}
+
+func lookupCurveMapper(name string) (curve.CurveMapper, error) {
+	if name == "" {
+		return curve.MapCircularCurve, nil
+	}
+	if mapper, ok := curveMappers[name]; ok {
+		return mapper, nil
+	}
+	return curve.CurveMapper{}, fmt.Errorf("unknown curve '%s', expected one of %s", name, strings.Join(slices.Sorted(maps.Keys(curveMappers)), ", "))
+}
+
+// region is the part of the scene box b that the generator draws in
+func (g GeneratorDescription) region(b primitives.BBox) primitives.BBox {
+	if g.Box == nil {
+		return b
+	}
+	return relativeBox(*g.Box, b)
+}
+
+func relativeBox(box [4]float64, b primitives.BBox) primitives.BBox {
+	return primitives.BBox{UpperLeft: relativePoint([2]float64{box[0], box[1]}, b), LowerRight: relativePoint([2]float64{box[2], box[3]}, b)}
+}
+
+func relativePoint(p [2]float64, b primitives.BBox) primitives.Point {
+	return b.UpperLeft.Add(primitives.Vector{X: p[0] * b.Width(), Y: p[1] * b.Height()})
+}
+
+func pointOrCenter(p *[2]float64, b, region primitives.BBox) primitives.Point {
+	if p == nil {
+		return region.Center()
+	}
+	return relativePoint(*p, b)
+}
+
+func polygon(points [][2]float64, b primitives.BBox) objects.Polygon {
+	poly := objects.Polygon{}
+	for _, p := range points {
+		poly.Points = append(poly.Points, relativePoint(p, b))
+	}
+	return poly
+}
+
+func (c *ClipDescription) check() error {
+	if c == nil {
+		return nil
+	}
+	switch c.Type {
+	case "box":
+		if c.Box == nil {
+			return fmt.Errorf("a box clip needs a box")
+		}
+	case "polygon":
+		if len(c.Points) < 3 {
+			return fmt.Errorf("a polygon clip needs at least 3 points, got %d", len(c.Points))
+		}
+	case "circle":
+		if c.Radius <= 0 {
+			return fmt.Errorf("a circle clip needs a positive radius")
+		}
+	case "composite":
+		if len(c.With) == 0 {
+			return fmt.Errorf("a composite clip needs at least one shape in with")
+		}
+		for _, clip := range slices.Concat(c.With, c.Without) {
+			if err := clip.check(); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unknown clip '%s', expected one of box, polygon, circle, composite", c.Type)
+	}
+	return nil
+}
+
+// object is the shape of the clip in the scene box b, or the region of the generator without a clip
+func (c *ClipDescription) object(b, region primitives.BBox) objects.Object {
+	if c == nil {
+		return objects.PolygonFromBBox(region)
+	}
+	switch c.Type {
+	case "box":
+		return objects.PolygonFromBBox(relativeBox(*c.Box, b))
+	case "polygon":
+		return polygon(c.Points, b)
+	case "circle":
+		return objects.Circle{Center: pointOrCenter(c.Center, b, region), Radius: c.Radius * min(b.Width(), b.Height())}
+	}
+	composite := objects.NewComposite()
+	for _, clip := range c.With {
+		composite = composite.With(clip.object(b, region))
+	}
+	for _, clip := range c.Without {
+		composite = composite.Without(clip.object(b, region))
+	}
+	return composite
+}
+
+func (s *SamplerDescription) check() error {
+	switch s.Type {
+	case "perlin":
+		if s.Scale <= 0 {
+			return fmt.Errorf("a perlin sampler needs a positive scale")
+		}
+	case "distance":
+		if len(s.Points) == 0 {
+			return fmt.Errorf("a distance sampler needs at least one point")
+		}
+	default:
+		return fmt.Errorf("unknown sampler '%s', expected one of perlin, distance", s.Type)
+	}
+	return nil
+}
+
+func (s *SamplerDescription) source(b primitives.BBox) samplers.DataSource {
+	if s.Type == "perlin" {
+		return samplers.NewPerlinNoise(s.Scale, primitives.Vector{})
+	}
+	distances := []samplers.DataSource{}
+	for _, p := range s.Points {
+		distances = append(distances, samplers.PointDistance(relativePoint(p, b)))
+	}
+	return samplers.Min(distances...)
+}
+
+// compiledLayer is a layer of a description, with its generators checked and built
+type compiledLayer struct {
+	LayerDescription
+	pen        pen.Pen
+	generators []generate
+}
+
+// compile checks the description, returning the function that renders it, and whether it uses random numbers
+func (d Description) compile() (func(b primitives.BBox, r *rand.Rand) Document, bool, error) {
+	if d.Name == "" {
+		return nil, false, fmt.Errorf("the scene has no name")
+	}
+	if len(d.Layers) == 0 {
+		return nil, false, fmt.Errorf("scene '%s' has no layers", d.Name)
+	}
+	random := false
+	layers := []compiledLayer{}
+	for i, layer := range d.Layers {
+		if layer.Name == "" {
+			return nil, false, fmt.Errorf("layer #%d has no name", i)
+		}
+		compiled := compiledLayer{LayerDescription: layer}
+		if layer.Pen != "" {
+			var err error
+			if compiled.pen, err = pen.Lookup(layer.Pen); err != nil {
+				return nil, false, fmt.Errorf("layer '%s': %w", layer.Name, err)
+			}
+		}
+		for j, g := range layer.Generators {
+			generator, ok := descriptionGenerators[g.Type]
+			if !ok {
+				return nil, false, fmt.Errorf("layer '%s', generator #%d: unknown type '%s', expected one of %s", layer.Name, j, g.Type, strings.Join(slices.Sorted(maps.Keys(descriptionGenerators)), ", "))
+			}
+			if g.Clip != nil && !generator.clips {
+				return nil, false, fmt.Errorf("layer '%s', generator #%d: %s can't be clipped", layer.Name, j, g.Type)
+			}
+			err := g.Clip.check()
+			if err == nil {
+				var gen generate
+				gen, err = generator.build(g)
+				compiled.generators = append(compiled.generators, gen)
+			}
+			if err != nil {
+				return nil, false, fmt.Errorf("layer '%s', generator #%d (%s): %w", layer.Name, j, g.Type, err)
+			}
+			random = random || generator.random
+		}
+		layers = append(layers, compiled)
+	}
+	render := func(b primitives.BBox, r *rand.Rand) Document {
+		if d.Square {
+			b = b.Square()
+		}
+		doc := Document{}
+		if d.Guides {
+			doc = doc.WithGuides()
+		}
+		for _, l := range layers {
+			layer := NewLayer(l.Name)
+			for _, gen := range l.generators {
+				layer = layer.WithLineLike(gen(b, r))
+			}
+			if l.Pen != "" {
+				layer = layer.WithPen(l.pen)
+			}
+			if l.Color != "" {
+				layer = layer.WithColor(l.Color)
+			}
+			if l.Width > 0 {
+				layer = layer.WithWidth(l.Width)
+			}
+			if l.NoGuide {
+				layer = layer.WithNoGuide()
+			}
+			if l.Optimize {
+				layer = layer.MinimizePath(true)
+			}
+			doc = doc.AddLayer(layer)
+		}
+		return doc
+	}
+	return render, random, nil
+}
+
+// ReadDescription reads a scene description from a JSON file, rejecting fields that it doesn't know
+func ReadDescription(fname string) (Description, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return Description{}, err
+	}
+	defer f.Close()
+	d := Description{}
+	decoder := json.NewDecoder(f)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&d); err != nil {
+		return Description{}, fmt.Errorf("reading %s: %w", fname, err)
+	}
+	return d, nil
+}
+
+// AddDescription checks the description, and adds it as a scene without parameters. The pens of the scene
+// are those that its layers name.
+func (l *sceneLibrary) AddDescription(d Description) error {
+	render, random, err := d.compile()
+	if err != nil {
+		return err
+	}
+	pens := []pen.Pen{}
+	for _, layer := range d.Layers {
+		if layer.Pen == "" {
+			continue
+		}
+		p, _ := pen.Lookup(layer.Pen) // compile already checked the pen
+		if !slices.Contains(pens, p) {
+			pens = append(pens, p)
+		}
+	}
+	options := []SceneOption{WithDescription(d.Description), WithTags(d.Tags...), WithPens(pens...)}
+	if d.Expensive {
+		options = append(options, Expensive())
+	}
+	return l.add(d.Name, nil, random, func(b primitives.BBox, _ Params, r *rand.Rand) Document { return render(b, r) }, options)
+}
+
+// LoadDescriptions adds the scene of each .json file in the directory. A directory that doesn't exist has no
+// scenes in it, so that the default directory may be left out.
+func (l *sceneLibrary) LoadDescriptions(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		fname := filepath.Join(dir, entry.Name())
+		d, err := ReadDescription(fname)
+		if err != nil {
+			return err
+		}
+		if err := l.AddDescription(d); err != nil {
+			return fmt.Errorf("%s: %w", fname, err)
+		}
+	}
+	return nil
+}
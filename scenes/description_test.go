@@ -0,0 +1,127 @@
+package scenes
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/libeks/go-plotter-svg/paper"
+)
+
+// the descriptions that come with the repository should load, and draw something in every layer
+func TestLoadDescriptions(t *testing.T) {
+	library := SceneLibrary()
+	if err := library.LoadDescriptions("../descriptions"); err != nil {
+		t.Fatalf("LoadDescriptions() returned error %v", err)
+	}
+	if len(library.Scenes()) == 0 {
+		t.Fatalf("LoadDescriptions() found no scenes")
+	}
+	for _, scene := range library.Scenes() {
+		t.Run(scene.Name, func(t *testing.T) {
+			doc := scene.Render(paper.Default().SceneBox(), scene.Defaults(), 1)
+			for _, layer := range doc.Page(0).layers {
+				if len(layer.LineLikes()) == 0 {
+					t.Errorf("layer '%s' is empty", layer.Name())
+				}
+			}
+		})
+	}
+}
+
+func TestDescriptionRandom(t *testing.T) {
+	tests := []struct {
+		name   string
+		layers string
+		want   bool
+	}{
+		{
+			name:   "lines",
+			layers: `[{"name": "a", "generators": [{"type": "frame"}, {"type": "line-field", "spacing": 50}]}]`,
+			want:   false,
+		},
+		{
+			name:   "maze",
+			layers: `[{"name": "a", "generators": [{"type": "frame"}]}, {"name": "b", "generators": [{"type": "maze", "n": 5}]}]`,
+			want:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Description{Name: tt.name}
+			if err := json.Unmarshal([]byte(tt.layers), &d.Layers); err != nil {
+				t.Fatalf("Unmarshal() returned error %v", err)
+			}
+			_, got, err := d.compile()
+			if err != nil {
+				t.Fatalf("compile() returned error %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Unexpected diff %v", diff)
+			}
+		})
+	}
+}
+
+func TestDescriptionErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		layers string
+		want   string // part of the error
+	}{
+		{
+			name:   "no layers",
+			layers: `[]`,
+			want:   "has no layers",
+		},
+		{
+			name:   "unknown generator",
+			layers: `[{"name": "a", "generators": [{"type": "spiral"}]}]`,
+			want:   "layer 'a', generator #0: unknown type 'spiral'",
+		},
+		{
+			name:   "unknown pen",
+			layers: `[{"name": "a", "pen": "crayon", "generators": []}]`,
+			want:   "unknown pen 'crayon'",
+		},
+		{
+			name:   "missing spacing",
+			layers: `[{"name": "a", "generators": [{"type": "line-field"}]}]`,
+			want:   "spacing must be positive",
+		},
+		{
+			name:   "unknown tiles",
+			layers: `[{"name": "a", "generators": [{"type": "truchet", "n": 4, "tiles": "hexagons"}]}]`,
+			want:   "unknown tiles 'hexagons'",
+		},
+		{
+			name:   "clip of a generator that can't be clipped",
+			layers: `[{"name": "a", "generators": [{"type": "maze", "n": 4, "clip": {"type": "circle", "radius": 0.5}}]}]`,
+			want:   "maze can't be clipped",
+		},
+		{
+			name:   "bad clip in a composite",
+			layers: `[{"name": "a", "generators": [{"type": "line-field", "spacing": 10, "clip": {"type": "composite", "with": [{"type": "polygon", "points": [[0, 0]]}]}}]}]`,
+			want:   "a polygon clip needs at least 3 points",
+		},
+		{
+			name:   "unknown sampler",
+			layers: `[{"name": "a", "generators": [{"type": "marching-squares", "n": 10, "thresholds": [0], "sampler": {"type": "waves"}}]}]`,
+			want:   "unknown sampler 'waves'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Description{Name: tt.name}
+			if err := json.Unmarshal([]byte(tt.layers), &d.Layers); err != nil {
+				t.Fatalf("Unmarshal() returned error %v", err)
+			}
+			_, _, err := d.compile()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("compile() returned error %v, want an error with '%s'", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,434 @@
+package scenes
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/libeks/go-plotter-svg/foldable"
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/objects"
+	"github.com/libeks/go-plotter-svg/pen"
+	"github.com/libeks/go-plotter-svg/primitives"
+)
+
+// EncodingVersion is the version of the JSON encoding of documents. It is bumped whenever the encoding changes
+// in a way that older readers can't handle.
+const EncodingVersion = 1
+
+// the JSON types mirror the scene types, but with exported fields. Coordinates are kept at full precision,
+// so that decoding an encoded document gives back exactly the same geometry.
+
+type documentJSON struct {
+	Version int        `json:"version"`
+	Guides  bool       `json:"guides,omitempty"`
+	Source  *Source    `json:"source,omitempty"`
+	Pages   []pageJSON `json:"pages"`
+}
+
+type pageJSON struct {
+	Guides   bool          `json:"guides,omitempty"`
+	Layers   []layerJSON   `json:"layers"`
+	Patterns []patternJSON `json:"patterns,omitempty"`
+}
+
+type layerJSON struct {
+	Name         string      `json:"name"`
+	DrawGuide    bool        `json:"draw_guide,omitempty"`
+	OffsetX      float64     `json:"offset_x,omitempty"`
+	OffsetY      float64     `json:"offset_y,omitempty"`
+	Color        string      `json:"color,omitempty"`
+	Width        float64     `json:"width,omitempty"`
+	Pen          *penJSON    `json:"pen,omitempty"`
+	LineLikes    []*lineJSON `json:"lines"`
+	ControlLines []*lineJSON `json:"control_lines,omitempty"`
+}
+
+type penJSON struct {
+	Name    string  `json:"name"`
+	Spacing float64 `json:"spacing,omitempty"`
+	XOffset float64 `json:"x_offset,omitempty"`
+	YOffset float64 `json:"y_offset,omitempty"`
+}
+
+type patternJSON struct {
+	Edges       []*lineJSON          `json:"edges"`
+	Polygons    [][]point            `json:"polygons"`
+	Fill        map[string]brushJSON `json:"fill,omitempty"`
+	Annotations []*lineJSON          `json:"annotations,omitempty"`
+}
+
+type brushJSON struct {
+	Pen   penJSON     `json:"pen"`
+	Color string      `json:"color,omitempty"`
+	Lines []*lineJSON `json:"lines"`
+}
+
+// lineJSON holds any LineLike or PathChunk, told apart by Type. A nil LineLike is encoded as null.
+type lineJSON struct {
+	Type string `json:"type"`
+	// LineSegment, and all chunks except arcs
+	Start *point `json:"start,omitempty"`
+	P1    *point `json:"p1,omitempty"`
+	P2    *point `json:"p2,omitempty"`
+	End   *point `json:"end,omitempty"`
+	// Circle and arc chunks
+	Center     *point  `json:"center,omitempty"`
+	Radius     float64 `json:"radius,omitempty"`
+	StartAngle float64 `json:"start_angle,omitempty"`
+	EndAngle   float64 `json:"end_angle,omitempty"`
+	Clockwise  bool    `json:"clockwise,omitempty"`
+	// Path
+	Chunks []*lineJSON `json:"chunks,omitempty"`
+}
+
+const (
+	typeSegment   = "segment"
+	typePath      = "path"
+	typeCircle    = "circle"
+	typeLine      = "line"
+	typeQuadratic = "quadratic"
+	typeCubic     = "cubic"
+	typeArc       = "arc"
+)
+
+// point is encoded as [x, y]
+type point [2]float64
+
+func toPoint(p primitives.Point) *point {
+	return &point{p.X, p.Y}
+}
+
+func (p *point) Point() primitives.Point {
+	if p == nil {
+		return primitives.Origin
+	}
+	return primitives.Point{X: p[0], Y: p[1]}
+}
+
+func (d Document) MarshalJSON() ([]byte, error) {
+	doc := documentJSON{Version: EncodingVersion, Guides: d.guides, Source: d.source, Pages: []pageJSON{}}
+	for i, page := range d.pages {
+		p, err := encodePage(page)
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %w", i, err)
+		}
+		doc.Pages = append(doc.Pages, p)
+	}
+	return json.Marshal(doc)
+}
+
+func (d *Document) UnmarshalJSON(b []byte) error {
+	doc := documentJSON{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return err
+	}
+	if doc.Version < 1 || doc.Version > EncodingVersion {
+		return fmt.Errorf("unsupported document version %d, only versions up to %d are supported", doc.Version, EncodingVersion)
+	}
+	*d = Document{guides: doc.Guides, source: doc.Source}
+	for i, p := range doc.Pages {
+		page, err := decodePage(p)
+		if err != nil {
+			return fmt.Errorf("page %d: %w", i, err)
+		}
+		d.pages = append(d.pages, page)
+	}
+	return nil
+}
+
+// WriteJSON saves the document, so that it can be read back with ReadJSON instead of generating it again
+func (d Document) WriteJSON(fname string) error {
+	fmt.Printf("trying to open file %s\n", fname)
+	f, err := os.OpenFile(fname, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := json.NewEncoder(f).Encode(d); err != nil {
+		return err
+	}
+	fmt.Printf("Finished writing to file %s\n", fname)
+	return nil
+}
+
+// ReadJSON reads a document saved with WriteJSON
+func ReadJSON(fname string) (Document, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return Document{}, err
+	}
+	defer f.Close()
+	d := Document{}
+	if err := json.NewDecoder(f).Decode(&d); err != nil {
+		return Document{}, fmt.Errorf("reading %s: %w", fname, err)
+	}
+	return d, nil
+}
+
+func encodePage(page Page) (pageJSON, error) {
+	p := pageJSON{Guides: page.guides, Layers: []layerJSON{}}
+	for _, layer := range page.layers {
+		l, err := encodeLayer(layer)
+		if err != nil {
+			return pageJSON{}, fmt.Errorf("layer '%s': %w", layer.name, err)
+		}
+		p.Layers = append(p.Layers, l)
+	}
+	for _, pattern := range page.patterns {
+		pat, err := encodePattern(pattern)
+		if err != nil {
+			return pageJSON{}, fmt.Errorf("foldable pattern: %w", err)
+		}
+		p.Patterns = append(p.Patterns, pat)
+	}
+	return p, nil
+}
+
+func decodePage(p pageJSON) (Page, error) {
+	page := Page{guides: p.Guides}
+	for _, l := range p.Layers {
+		layer, err := decodeLayer(l)
+		if err != nil {
+			return Page{}, fmt.Errorf("layer '%s': %w", l.Name, err)
+		}
+		page.layers = append(page.layers, layer)
+	}
+	for _, pat := range p.Patterns {
+		pattern, err := decodePattern(pat)
+		if err != nil {
+			return Page{}, fmt.Errorf("foldable pattern: %w", err)
+		}
+		page.patterns = append(page.patterns, pattern)
+	}
+	return page, nil
+}
+
+func encodeLayer(layer Layer) (layerJSON, error) {
+	l := layerJSON{
+		Name:      layer.name,
+		DrawGuide: layer.drawGuide,
+		OffsetX:   layer.offsetX,
+		OffsetY:   layer.offsetY,
+		Color:     layer.color,
+		Width:     layer.width,
+	}
+	if layer.pen != (pen.Pen{}) {
+		p := encodePen(layer.pen)
+		l.Pen = &p
+	}
+	var err error
+	if l.LineLikes, err = encodeLineLikes(layer.linelikes); err != nil {
+		return layerJSON{}, err
+	}
+	if l.ControlLines, err = encodeLineLikes(layer.controllines); err != nil {
+		return layerJSON{}, fmt.Errorf("control lines: %w", err)
+	}
+	return l, nil
+}
+
+func decodeLayer(l layerJSON) (Layer, error) {
+	layer := Layer{
+		name:      l.Name,
+		drawGuide: l.DrawGuide,
+		offsetX:   l.OffsetX,
+		offsetY:   l.OffsetY,
+		color:     l.Color,
+		width:     l.Width,
+	}
+	if l.Pen != nil {
+		layer.pen = decodePen(*l.Pen)
+	}
+	var err error
+	if layer.linelikes, err = decodeLineLikes(l.LineLikes); err != nil {
+		return Layer{}, err
+	}
+	if layer.controllines, err = decodeLineLikes(l.ControlLines); err != nil {
+		return Layer{}, fmt.Errorf("control lines: %w", err)
+	}
+	return layer, nil
+}
+
+func encodePen(p pen.Pen) penJSON {
+	return penJSON{Name: p.Name, Spacing: p.Spacing, XOffset: p.XOffset, YOffset: p.YOffset}
+}
+
+func decodePen(p penJSON) pen.Pen {
+	return pen.Pen{Name: p.Name, Spacing: p.Spacing, XOffset: p.XOffset, YOffset: p.YOffset}
+}
+
+func encodePattern(pattern foldable.FoldablePattern) (patternJSON, error) {
+	p := patternJSON{Polygons: [][]point{}}
+	var err error
+	if p.Edges, err = encodeLineLikes(pattern.Edges); err != nil {
+		return patternJSON{}, fmt.Errorf("edges: %w", err)
+	}
+	if p.Annotations, err = encodeLineLikes(pattern.Annotations); err != nil {
+		return patternJSON{}, fmt.Errorf("annotations: %w", err)
+	}
+	for _, polygon := range pattern.Polygons {
+		points := make([]point, len(polygon.Points))
+		for i, pt := range polygon.Points {
+			points[i] = *toPoint(pt)
+		}
+		p.Polygons = append(p.Polygons, points)
+	}
+	if len(pattern.Fill) > 0 {
+		p.Fill = map[string]brushJSON{}
+	}
+	for label, fill := range pattern.Fill {
+		brush := brushJSON{Pen: encodePen(fill.Pen), Color: fill.Color}
+		if brush.Lines, err = encodeLineLikes(fill.Lines); err != nil {
+			return patternJSON{}, fmt.Errorf("fill '%s': %w", label, err)
+		}
+		p.Fill[label] = brush
+	}
+	return p, nil
+}
+
+func decodePattern(p patternJSON) (foldable.FoldablePattern, error) {
+	pattern := foldable.FoldablePattern{}
+	var err error
+	if pattern.Edges, err = decodeLineLikes(p.Edges); err != nil {
+		return foldable.FoldablePattern{}, fmt.Errorf("edges: %w", err)
+	}
+	if pattern.Annotations, err = decodeLineLikes(p.Annotations); err != nil {
+		return foldable.FoldablePattern{}, fmt.Errorf("annotations: %w", err)
+	}
+	for _, points := range p.Polygons {
+		polygon := objects.Polygon{Points: make([]primitives.Point, len(points))}
+		for i, pt := range points {
+			polygon.Points[i] = pt.Point()
+		}
+		pattern.Polygons = append(pattern.Polygons, polygon)
+	}
+	if p.Fill != nil {
+		pattern.Fill = map[string]foldable.BrushLines{}
+	}
+	for label, brush := range p.Fill {
+		fill := foldable.BrushLines{Pen: decodePen(brush.Pen), Color: brush.Color}
+		if fill.Lines, err = decodeLineLikes(brush.Lines); err != nil {
+			return foldable.FoldablePattern{}, fmt.Errorf("fill '%s': %w", label, err)
+		}
+		pattern.Fill[label] = fill
+	}
+	return pattern, nil
+}
+
+func encodeLineLikes(linelikes []lines.LineLike) ([]*lineJSON, error) {
+	if linelikes == nil {
+		return nil, nil
+	}
+	ret := make([]*lineJSON, len(linelikes))
+	for i, linelike := range linelikes {
+		l, err := encodeLineLike(linelike)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i, err)
+		}
+		ret[i] = l
+	}
+	return ret, nil
+}
+
+func decodeLineLikes(ls []*lineJSON) ([]lines.LineLike, error) {
+	if ls == nil {
+		return nil, nil
+	}
+	ret := make([]lines.LineLike, len(ls))
+	for i, l := range ls {
+		linelike, err := decodeLineLike(l)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i, err)
+		}
+		ret[i] = linelike
+	}
+	return ret, nil
+}
+
+// encodeLineLike turns a LineLike into its JSON form. LineLikes other than LineSegment, Path and objects.Circle
+// are encoded as their Path, if they have one.
+func encodeLineLike(linelike lines.LineLike) (*lineJSON, error) {
+	switch l := linelike.(type) {
+	case nil:
+		return nil, nil
+	case lines.LineSegment:
+		return &lineJSON{Type: typeSegment, P1: toPoint(l.P1), P2: toPoint(l.P2)}, nil
+	case objects.Circle:
+		return &lineJSON{Type: typeCircle, Center: toPoint(l.Center), Radius: l.Radius}, nil
+	case lines.Path:
+		ret := &lineJSON{Type: typePath, Start: toPoint(l.Start()), Chunks: []*lineJSON{}}
+		for i, chunk := range l.Chunks() {
+			c, err := encodeChunk(chunk)
+			if err != nil {
+				return nil, fmt.Errorf("chunk %d: %w", i, err)
+			}
+			ret.Chunks = append(ret.Chunks, c)
+		}
+		return ret, nil
+	case lines.Pather:
+		return encodeLineLike(l.Path())
+	}
+	return nil, fmt.Errorf("don't know how to encode %T", linelike)
+}
+
+// decodeLineLike is the inverse of encodeLineLike
+func decodeLineLike(l *lineJSON) (lines.LineLike, error) {
+	if l == nil {
+		return nil, nil
+	}
+	switch l.Type {
+	case typeSegment:
+		return lines.LineSegment{P1: l.P1.Point(), P2: l.P2.Point()}, nil
+	case typeCircle:
+		return objects.Circle{Center: l.Center.Point(), Radius: l.Radius}, nil
+	case typePath:
+		path := lines.NewPath(l.Start.Point())
+		for i, c := range l.Chunks {
+			chunk, err := decodeChunk(c)
+			if err != nil {
+				return nil, fmt.Errorf("chunk %d: %w", i, err)
+			}
+			path = path.AddPathChunk(chunk)
+		}
+		return path, nil
+	}
+	return nil, fmt.Errorf("unknown line type '%s'", l.Type)
+}
+
+func encodeChunk(chunk lines.PathChunk) (*lineJSON, error) {
+	switch c := chunk.(type) {
+	case lines.LineChunk:
+		return &lineJSON{Type: typeLine, Start: toPoint(c.Start), End: toPoint(c.End)}, nil
+	case lines.QuadraticBezierChunk:
+		return &lineJSON{Type: typeQuadratic, Start: toPoint(c.Start), P1: toPoint(c.P1), End: toPoint(c.End)}, nil
+	case lines.CubicBezierChunk:
+		return &lineJSON{Type: typeCubic, Start: toPoint(c.Start), P1: toPoint(c.P1), P2: toPoint(c.P2), End: toPoint(c.End)}, nil
+	case lines.ArcChunk:
+		return &lineJSON{
+			Type:       typeArc,
+			Center:     toPoint(c.Center()),
+			Radius:     c.Radius(),
+			StartAngle: c.StartAngle(),
+			EndAngle:   c.EndAngle(),
+			Clockwise:  c.IsClockwise(),
+		}, nil
+	}
+	return nil, fmt.Errorf("don't know how to encode chunk %T", chunk)
+}
+
+func decodeChunk(c *lineJSON) (lines.PathChunk, error) {
+	if c == nil {
+		return nil, fmt.Errorf("missing chunk")
+	}
+	switch c.Type {
+	case typeLine:
+		return lines.LineChunk{Start: c.Start.Point(), End: c.End.Point()}, nil
+	case typeQuadratic:
+		return lines.QuadraticBezierChunk{Start: c.Start.Point(), P1: c.P1.Point(), End: c.End.Point()}, nil
+	case typeCubic:
+		return lines.CubicBezierChunk{Start: c.Start.Point(), P1: c.P1.Point(), P2: c.P2.Point(), End: c.End.Point()}, nil
+	case typeArc:
+		return lines.CircleArcChunk(c.Center.Point(), c.Radius, c.StartAngle, c.EndAngle, c.Clockwise), nil
+	}
+	return nil, fmt.Errorf("unknown chunk type '%s'", c.Type)
+}
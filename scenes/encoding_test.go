@@ -0,0 +1,81 @@
+package scenes
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/libeks/go-plotter-svg/foldable"
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/objects"
+	"github.com/libeks/go-plotter-svg/pen"
+	"github.com/libeks/go-plotter-svg/primitives"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	a := primitives.Point{X: 1.0 / 3, Y: 2}
+	b := primitives.Point{X: 100, Y: -math.Pi}
+	c := primitives.Point{X: 1e-9, Y: 12345.6789}
+	path := lines.NewPath(a).
+		AddPathChunk(lines.LineChunk{Start: a, End: b}).
+		AddPathChunk(lines.QuadraticBezierChunk{Start: b, P1: c, End: a}).
+		AddPathChunk(lines.CubicBezierChunk{Start: a, P1: b, P2: c, End: b}).
+		AddPathChunk(lines.CircleArcChunk(c, 10, 0.1, math.Pi, false))
+	linelikes := []lines.LineLike{
+		lines.LineSegment{P1: a, P2: b},
+		path,
+		objects.Circle{Center: c, Radius: 0.25},
+		nil,
+	}
+	doc := Document{}.WithGuides().
+		WithSource(Source{Scene: "test", Seed: 42, Params: Params{"spacing": 40.0, "color": "red"}}).
+		AddPage(Page{}.WithGuides().
+			AddLayer(NewLayer("first").WithLineLike(linelikes).WithControlLines(linelikes[:2]).WithColor("red").WithWidth(7.5)).
+			AddLayer(NewLayer("second").WithLineLike(linelikes[1:2]).WithPen(pen.Micron01).WithNoGuide()),
+		).
+		AddPage(Page{}.
+			AddLayer(NewLayer("empty").WithOffset(-3, 4)).
+			WithFoldablePatterns([]foldable.FoldablePattern{{
+				Edges:       linelikes[:1],
+				Polygons:    []objects.Polygon{{Points: []primitives.Point{a, b, c}}},
+				Fill:        map[string]foldable.BrushLines{"blue": {Pen: pen.Micron05, Color: "blue", Lines: linelikes[:2]}},
+				Annotations: linelikes[2:3],
+			}}),
+		)
+	encoded, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("Marshal() returned error %v", err)
+	}
+	got := Document{}
+	if err := json.Unmarshal(encoded, &got); err != nil {
+		t.Fatalf("Unmarshal() returned error %v", err)
+	}
+	if diff := cmp.Diff(doc, got, cmp.Exporter(func(reflect.Type) bool { return true })); diff != "" {
+		t.Errorf("Unexpected diff %v", diff)
+	}
+}
+
+func TestJSONErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{"newer version", `{"version": 2, "pages": []}`, "unsupported document version 2"},
+		{"missing version", `{"pages": []}`, "unsupported document version 0"},
+		{"unknown line", `{"version": 1, "pages": [{"layers": [{"name": "a", "lines": [{"type": "spiral"}]}]}]}`, "page 0: layer 'a': line 0: unknown line type 'spiral'"},
+		{"unknown chunk", `{"version": 1, "pages": [{"layers": [{"name": "a", "lines": [{"type": "path", "chunks": [{"type": "gap"}]}]}]}]}`, "chunk 0: unknown chunk type 'gap'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := json.Unmarshal([]byte(tt.json), &Document{})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,420 @@
+package scenes
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"maps"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/image/font/gofont/goregular"
+
+	"github.com/libeks/go-plotter-svg/fonts"
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/paper"
+)
+
+// Run with -update to write the golden files again, after a change that is meant to change the scenes:
+//
+//	go test ./scenes -run TestGolden -update
+var (
+	update    = flag.Bool("update", false, "write the golden files of TestGolden instead of comparing against them")
+	tolerance = flag.Float64("tolerance", 0.5, "how far, in internal units, TestGolden lets a point move")
+)
+
+const (
+	goldenDir  = "testdata/golden"
+	goldenSeed = 1
+	maxDiffs   = 10  // differences reported per scene, the rest are only counted
+	maxCurves  = 100 // curves of a layer kept in the golden file, larger layers are sampled
+)
+
+// TestGolden renders every scene of the library with a fixed seed, and compares its geometry against the
+// golden file of the scene. Each curve is reduced to its start, middle and end points and its length, so that
+// the comparison doesn't depend on how the curve is represented. Golden files keep a sample of the curves of
+// large layers, along with the number of curves and their total length, to keep them small enough to review.
+// Every scene but the expensive ones must have a golden file.
+func TestGolden(t *testing.T) {
+	if testing.Short() {
+		t.Skip("rendering every scene is slow")
+	}
+	// the scenes with text get the same font on every machine
+	fonts.DefaultFile = filepath.Join(t.TempDir(), "Go-Regular.ttf")
+	if err := os.WriteFile(fonts.DefaultFile, goregular.TTF, 0644); err != nil {
+		t.Fatal(err)
+	}
+	library := GatherScenes()
+	layout := paper.Default()
+	for _, scene := range library.Scenes() {
+		t.Run(scene.Name, func(t *testing.T) {
+			if scene.Expensive {
+				t.Skip("the scene is expensive to render")
+			}
+			t.Parallel()
+			fname := filepath.Join(goldenDir, scene.Name+".txt")
+			want, readErr := readGolden(fname)
+			if readErr != nil && !errors.Is(readErr, fs.ErrNotExist) && !*update {
+				// a file that can't be read is written again with -update
+				t.Fatal(readErr)
+			}
+			doc, err := renderGolden(scene, layout)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := normalize(doc)
+			if *update {
+				if err := got.write(fname); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if readErr != nil {
+				t.Fatalf("%s is missing, run with -update to create it", fname)
+			}
+			if diffs := want.diff(got, *tolerance); len(diffs) > 0 {
+				t.Errorf("%s doesn't match %s, run with -update if that's expected:\n%s", scene.Name, fname, strings.Join(diffs, "\n"))
+			}
+		})
+	}
+}
+
+// renderGolden renders the scene with its default parameters, turning a panic into an error
+func renderGolden(scene Scene, layout paper.Layout) (doc Document, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(error); ok {
+				err = fmt.Errorf("scene panicked: %w", e)
+			} else {
+				err = fmt.Errorf("scene panicked: %v", r)
+			}
+		}
+	}()
+	return scene.Render(layout.SceneBox(), scene.Defaults(), goldenSeed), nil
+}
+
+// golden is the normalized geometry of a document
+type golden []goldenLayer
+
+// goldenLayer is the number of curves of a layer and their total length, along with the curves themselves, by
+// their index in the layer. Golden files only hold a sample of the curves of large layers, see maxCurves.
+type goldenLayer struct {
+	page   int
+	name   string
+	count  int
+	length float64
+	curves map[int]goldenCurve
+}
+
+// goldenCurve is the start, middle and end points of a curve, and its length. nil LineLikes are kept as
+// empty curves, so that the curves after them keep their index.
+type goldenCurve []float64
+
+func newGoldenLayer(page int, name string, curves []goldenCurve) goldenLayer {
+	gl := goldenLayer{page: page, name: name, count: len(curves), curves: make(map[int]goldenCurve, len(curves))}
+	for i, curve := range curves {
+		gl.curves[i] = curve
+		if curve != nil {
+			gl.length += curve[6]
+		}
+	}
+	return gl
+}
+
+func normalize(doc Document) golden {
+	g := golden{}
+	for i := range doc.NumPages() {
+		for _, layer := range doc.Page(i).GetLayers() {
+			curves := []goldenCurve{}
+			for _, line := range layer.LineLikes() {
+				curves = append(curves, normalizeCurve(line))
+			}
+			g = append(g, newGoldenLayer(i, layer.Name(), curves))
+		}
+	}
+	return g
+}
+
+func normalizeCurve(line lines.LineLike) goldenCurve {
+	if line == nil {
+		return nil
+	}
+	start, middle, end := line.Start(), line.At(0.5), line.End()
+	return goldenCurve{start.X, start.Y, middle.X, middle.Y, end.X, end.Y, line.Len()}
+}
+
+// sample returns the indices of the curves of the layer that go into the golden file, spread evenly over the
+// layer and always including the last curve
+func (gl goldenLayer) sample() []int {
+	if gl.count <= maxCurves {
+		return slices.Sorted(maps.Keys(gl.curves))
+	}
+	indices := []int{}
+	for i := range maxCurves - 1 {
+		indices = append(indices, i*gl.count/(maxCurves-1))
+	}
+	return append(indices, gl.count-1)
+}
+
+// the golden file has a line for each layer, followed by a line for each of the curves sampled from it:
+//
+//	layer <page> <number of curves> <total length> <name>
+//	<index> <start x> <start y> <middle x> <middle y> <end x> <end y> <length>
+func (g golden) write(fname string) error {
+	if err := os.MkdirAll(filepath.Dir(fname), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(fname, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	for _, layer := range g {
+		fmt.Fprintf(w, "layer %d %d %.1f %s\n", layer.page, layer.count, layer.length, layer.name)
+		for _, i := range layer.sample() {
+			curve := layer.curves[i]
+			if curve == nil {
+				fmt.Fprintf(w, "%d nil\n", i)
+				continue
+			}
+			values := []string{strconv.Itoa(i)}
+			for _, v := range curve {
+				values = append(values, strconv.FormatFloat(v, 'f', 1, 64))
+			}
+			fmt.Fprintln(w, strings.Join(values, " "))
+		}
+	}
+	return w.Flush()
+}
+
+func readGolden(fname string) (golden, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	g := golden{}
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if rest, ok := strings.CutPrefix(line, "layer "); ok {
+			fields := strings.SplitN(rest, " ", 4)
+			if len(fields) < 4 {
+				return nil, fmt.Errorf("%s:%d: bad layer '%s'", fname, n, rest)
+			}
+			p, err := strconv.Atoi(fields[0])
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: bad page number '%s'", fname, n, fields[0])
+			}
+			count, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: bad number of curves '%s'", fname, n, fields[1])
+			}
+			length, err := strconv.ParseFloat(fields[2], 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: bad length '%s'", fname, n, fields[2])
+			}
+			g = append(g, goldenLayer{page: p, name: fields[3], count: count, length: length, curves: map[int]goldenCurve{}})
+			continue
+		}
+		if len(g) == 0 {
+			return nil, fmt.Errorf("%s:%d: curve before the first layer", fname, n)
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("%s:%d: empty line", fname, n)
+		}
+		i, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: bad curve index '%s'", fname, n, fields[0])
+		}
+		var curve goldenCurve
+		if len(fields) != 2 || fields[1] != "nil" {
+			for _, field := range fields[1:] {
+				v, err := strconv.ParseFloat(field, 64)
+				if err != nil {
+					return nil, fmt.Errorf("%s:%d: bad number '%s'", fname, n, field)
+				}
+				curve = append(curve, v)
+			}
+		}
+		g[len(g)-1].curves[i] = curve
+	}
+	return g, scanner.Err()
+}
+
+// diff lists how got differs from g, naming the layer and the curve of each difference. Only the curves that g
+// has are compared, along with the number of curves and the total length of each layer.
+func (g golden) diff(got golden, tolerance float64) []string {
+	diffs := []string{}
+	count := 0
+	report := func(format string, a ...any) {
+		count += 1
+		if count <= maxDiffs {
+			diffs = append(diffs, fmt.Sprintf(format, a...))
+		}
+	}
+	if len(g) != len(got) {
+		report("want %d layers, got %d: %v vs %v", len(g), len(got), g.names(), got.names())
+	}
+	for i := range min(len(g), len(got)) {
+		want, have := g[i], got[i]
+		layer := fmt.Sprintf("layer #%d '%s' on page %d", i, want.name, want.page)
+		if want.name != have.name || want.page != have.page {
+			report("%s: got layer '%s' on page %d instead", layer, have.name, have.page)
+			continue
+		}
+		if want.count != have.count {
+			report("%s: want %d curves, got %d", layer, want.count, have.count)
+		}
+		if math.Abs(want.length-have.length) > tolerance {
+			report("%s: total length changed from %.1f to %.1f", layer, want.length, have.length)
+		}
+		for _, j := range slices.Sorted(maps.Keys(want.curves)) {
+			curve, ok := have.curves[j]
+			if !ok {
+				continue // the layer lost curves, which is reported above
+			}
+			if d := want.curves[j].diff(curve, tolerance); d != "" {
+				report("%s, curve #%d: %s", layer, j, d)
+			}
+		}
+	}
+	if count > maxDiffs {
+		diffs = append(diffs, fmt.Sprintf("... and %d more differences", count-maxDiffs))
+	}
+	return diffs
+}
+
+func (g golden) names() []string {
+	names := []string{}
+	for _, layer := range g {
+		names = append(names, layer.name)
+	}
+	return names
+}
+
+var curveValueNames = []string{"start", "middle", "end"}
+
+func (c goldenCurve) diff(got goldenCurve, tolerance float64) string {
+	if c == nil || got == nil {
+		if c == nil && got == nil {
+			return ""
+		}
+		return fmt.Sprintf("want %s, got %s", c, got)
+	}
+	if len(c) != len(got) {
+		return fmt.Sprintf("want %d values, got %d", len(c), len(got))
+	}
+	moved := []string{}
+	for i, name := range curveValueNames {
+		dx, dy := got[2*i]-c[2*i], got[2*i+1]-c[2*i+1]
+		if math.Hypot(dx, dy) > tolerance {
+			moved = append(moved, fmt.Sprintf("%s moved from (%.1f, %.1f) to (%.1f, %.1f)", name, c[2*i], c[2*i+1], got[2*i], got[2*i+1]))
+		}
+	}
+	if math.Abs(got[6]-c[6]) > tolerance {
+		moved = append(moved, fmt.Sprintf("length changed from %.1f to %.1f", c[6], got[6]))
+	}
+	return strings.Join(moved, ", ")
+}
+
+func (c goldenCurve) String() string {
+	if c == nil {
+		return "nil"
+	}
+	return fmt.Sprintf("a curve from (%.1f, %.1f) to (%.1f, %.1f)", c[0], c[1], c[4], c[5])
+}
+
+func TestGoldenDiff(t *testing.T) {
+	want := golden{
+		newGoldenLayer(0, "frame", []goldenCurve{{0, 0, 5, 0, 10, 0, 10}, nil}),
+		newGoldenLayer(0, "content", []goldenCurve{{0, 0, 0, 5, 0, 10, 10}}),
+	}
+	tests := []struct {
+		name string
+		got  golden
+		want []string
+	}{
+		{
+			name: "within tolerance",
+			got: golden{
+				newGoldenLayer(0, "frame", []goldenCurve{{0.1, 0, 5, 0.2, 10, 0, 10.3}, nil}),
+				newGoldenLayer(0, "content", []goldenCurve{{0, 0, 0, 5, 0, 10, 10}}),
+			},
+			want: []string{},
+		},
+		{
+			name: "moved and missing curves",
+			got: golden{
+				newGoldenLayer(0, "frame", []goldenCurve{{0, 0, 5, 3, 10, 0, 11}, {1, 1, 1, 1, 1, 1, 0}}),
+				newGoldenLayer(0, "content", []goldenCurve{}),
+			},
+			want: []string{
+				"layer #0 'frame' on page 0: total length changed from 10.0 to 11.0",
+				"layer #0 'frame' on page 0, curve #0: middle moved from (5.0, 0.0) to (5.0, 3.0), length changed from 10.0 to 11.0",
+				"layer #0 'frame' on page 0, curve #1: want nil, got a curve from (1.0, 1.0) to (1.0, 1.0)",
+				"layer #1 'content' on page 0: want 1 curves, got 0",
+				"layer #1 'content' on page 0: total length changed from 10.0 to 0.0",
+			},
+		},
+		{
+			name: "different layers",
+			got: golden{
+				newGoldenLayer(0, "content", []goldenCurve{{0, 0, 0, 5, 0, 10, 10}}),
+			},
+			want: []string{
+				"want 2 layers, got 1: [frame content] vs [content]",
+				"layer #0 'frame' on page 0: got layer 'content' on page 0 instead",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, want.diff(tt.got, 0.5)); diff != "" {
+				t.Errorf("Unexpected diff %v", diff)
+			}
+		})
+	}
+}
+
+func TestGoldenSample(t *testing.T) {
+	curves := []goldenCurve{}
+	for i := range 250 {
+		x := float64(i)
+		curves = append(curves, goldenCurve{x, 0, x, 5, x, 10, 10})
+	}
+	want := golden{newGoldenLayer(0, "content", curves)}
+	fname := filepath.Join(t.TempDir(), "sample.txt")
+	if err := want.write(fname); err != nil {
+		t.Fatalf("write() returned error %v", err)
+	}
+	read, err := readGolden(fname)
+	if err != nil {
+		t.Fatalf("readGolden() returned error %v", err)
+	}
+	if n := len(read[0].curves); n != maxCurves {
+		t.Errorf("Golden file has %d curves, want %d", n, maxCurves)
+	}
+	if diffs := read.diff(want, 0.5); len(diffs) > 0 {
+		t.Errorf("Unexpected diffs %v", diffs)
+	}
+	curves[249] = goldenCurve{249, 0, 249, 6, 249, 12, 12}
+	got := golden{newGoldenLayer(0, "content", curves)}
+	wantDiffs := []string{
+		"layer #0 'content' on page 0: total length changed from 2500.0 to 2502.0",
+		"layer #0 'content' on page 0, curve #249: middle moved from (249.0, 5.0) to (249.0, 6.0), end moved from (249.0, 10.0) to (249.0, 12.0), length changed from 10.0 to 12.0",
+	}
+	if diff := cmp.Diff(wantDiffs, read.diff(got, 0.5)); diff != "" {
+		t.Errorf("Unexpected diff %v", diff)
+	}
+}
@@ -2,14 +2,18 @@ package scenes
 
 import (
 	"fmt"
-	"math"
 	"math/rand"
+	"os"
+	"time"
 
 	"go.shabbyrobe.org/xmlwriter"
 
 	"github.com/libeks/go-plotter-svg/lines"
 	"github.com/libeks/go-plotter-svg/maths"
+	"github.com/libeks/go-plotter-svg/motion"
+	"github.com/libeks/go-plotter-svg/pen"
 	"github.com/libeks/go-plotter-svg/primitives"
+	"github.com/libeks/go-plotter-svg/route"
 )
 
 func NewLayer(annotation string) Layer {
@@ -28,6 +32,7 @@ type Layer struct {
 	offsetY      float64
 	color        string
 	width        float64
+	pen          pen.Pen
 }
 
 func (l Layer) WithLineLike(linelikes []lines.LineLike) Layer {
@@ -46,6 +51,14 @@ func (l Layer) WithOffset(x, y float64) Layer {
 	return l
 }
 
+// WithPen records which pen the layer is meant to be drawn with, and compensates for the pen's offset
+func (l Layer) WithPen(p pen.Pen) Layer {
+	l.pen = p
+	l.offsetX = p.XOffset
+	l.offsetY = p.YOffset
+	return l
+}
+
 func (l Layer) WithColor(color string) Layer {
 	l.color = color
 	return l
@@ -61,91 +74,212 @@ func (l Layer) WithNoGuide() Layer {
 	return l
 }
 
+func (l Layer) Name() string {
+	return l.name
+}
+
+func (l Layer) LineLikes() []lines.LineLike {
+	return l.linelikes
+}
+
+func (l Layer) ControlLines() []lines.LineLike {
+	return l.controllines
+}
+
+// Offset is the translation applied to the whole layer when plotting, usually to compensate for the pen's offset
+func (l Layer) Offset() primitives.Vector {
+	return primitives.Vector{X: l.offsetX, Y: l.offsetY}
+}
+
+func (l Layer) Color() string {
+	return l.color
+}
+
+func (l Layer) Width() float64 {
+	return l.width
+}
+
+// StrokeWidth is how wide the lines of the layer are drawn, in internal units: the width set with WithWidth,
+// or else the spacing of the layer's pen
+func (l Layer) StrokeWidth() float64 {
+	if l.width > 0 {
+		return l.width
+	}
+	if l.pen.Spacing > 0 {
+		return l.pen.Spacing
+	}
+	return 3 // same as in XML
+}
+
+// Pen returns the pen set by WithPen, or the zero Pen if there isn't one
+func (l Layer) Pen() pen.Pen {
+	return l.pen
+}
+
 func (l Layer) String() string {
 	return fmt.Sprintf("Layer '%s' %v", l.name, l.linelikes)
 }
 
-func (l Layer) Statistics() string {
+// TimeEstimate is roughly how long the layer takes to plot on an AxiDraw with its default settings
+func (l Layer) TimeEstimate() time.Duration {
+	return l.Statistics(motion.DefaultModel()).Statistics.Time()
+}
+
+// Distances returns how far the pen travels down and up, in meters, starting and ending at the origin
+func (l Layer) Distances() (float64, float64) {
 	lengths := []float64{}
-	upDistances := []float64{}
-	start := primitives.Origin
 	for _, linelike := range l.linelikes {
 		if linelike != nil {
 			lengths = append(lengths, linelike.Len())
+		}
+	}
+	upDistances := []float64{}
+	for _, travel := range l.TravelLines() {
+		upDistances = append(upDistances, travel.Len())
+	}
+	return imageSpaceToMeters(maths.SumFloats(lengths)), imageSpaceToMeters(maths.SumFloats(upDistances))
+}
 
-			upDistances = append(upDistances, start.Subtract(linelike.Start()).Len())
+// TravelLines are the moves of the pen while it's up: from the origin to the first line, between the end of
+// each line and the start of the next, and from the last line back to the origin
+func (l Layer) TravelLines() []lines.LineSegment {
+	travel := []lines.LineSegment{}
+	start := primitives.Origin
+	for _, linelike := range l.linelikes {
+		if linelike != nil {
+			travel = append(travel, lines.LineSegment{P1: start, P2: linelike.Start()})
 			start = linelike.End()
 		}
 	}
-
-	upDistances = append(upDistances, start.Subtract(primitives.Origin).Len())
-	downLen := imageSpaceToMeters(maths.SumFloats(lengths))
-	upLen := imageSpaceToMeters(maths.SumFloats(upDistances))
-	totalDistance := downLen + upLen
-	timeEstimate := metersToTime(totalDistance) + upDownEstimate(len(l.linelikes))
-	return fmt.Sprintf("%d curves, down distance %.1fm, up distance %.1fm, total %.1fm traveled\nWould take about %s to plot", len(l.linelikes), downLen, upLen, totalDistance, timeToMinSec(timeEstimate))
+	return append(travel, lines.LineSegment{P1: start, P2: primitives.Origin})
 }
 
-func (l Layer) RandomizedClosedCurves() Layer {
-	if len(l.linelikes) == 0 {
-		return l
+// Transform applies the transformation to the lines and control lines of the layer. The offset and the width
+// are kept, since they depend on the pen rather than on the drawing.
+func (l Layer) Transform(t primitives.Transform) Layer {
+	transform := func(lns []lines.LineLike) []lines.LineLike {
+		ret := make([]lines.LineLike, len(lns))
+		for i, line := range lns {
+			if line != nil {
+				ret[i] = lines.ToPath(line).Transform(t)
+			}
+		}
+		return ret
 	}
+	l.linelikes = transform(l.linelikes)
+	l.controllines = transform(l.controllines)
+	return l
+}
+
+// RandomizedClosedCurves starts each closed curve at a random point along it, so that the marks that the pen
+// leaves where it goes down and up don't line up
+func (l Layer) RandomizedClosedCurves(r *rand.Rand) Layer {
+	linelikes := make([]lines.LineLike, len(l.linelikes))
 	for i, line := range l.linelikes {
-		if line.Start().Subtract(line.End()).Len() < 0.1 {
+		linelikes[i] = line
+		if line != nil && line.Start().Subtract(line.End()).Len() < 0.1 {
 			// line is a closed curve
-			start, end := line.Bisect(rand.Float64())
-			l.linelikes[i] = end.Join(start) // FIXME: I can't combine two linelikes, but I could if I got the underlying PathChunks...
+			linelikes[i] = lines.ToPath(line).StartAt(r.Float64())
 		}
 	}
+	l.linelikes = linelikes
 	return l
 }
 
+// MinimizePath reorders the lines to cut down on pen-up travel, improving on the order until no move helps,
+// and prints how much travel it saved. It has no time budget, so that the scenes that call it come out the same
+// however fast the machine is.
 func (l Layer) MinimizePath(allowReverse bool) Layer {
 	if len(l.linelikes) == 0 {
 		return l
 	}
-	fmt.Printf("Layer '%s' before %s\n", l.name, l.Statistics())
-	lns := []lines.LineLike{}
-	var inputLines []lines.LineLike
-	inputLines = append(inputLines, l.linelikes...)
-	lns = append(lns, inputLines[0])
-	pt := inputLines[0].End()
-	inputLines = inputLines[1:]
-	for len(inputLines) > 0 {
-		minDistance := math.MaxFloat64
-		var minIndex int
-		var isReversed bool
-
-		for i, line := range inputLines {
-			dist := pt.Subtract(line.Start()).Len()
-			if dist < minDistance {
-				minDistance = dist
-				isReversed = false
-				minIndex = i
-			}
-			if allowReverse {
-				dist := pt.Subtract(line.End()).Len()
-				if dist < minDistance {
-					minDistance = dist
-					isReversed = true
-					minIndex = i
-				}
-			}
+	l, result := l.Optimize(route.Options{Reverse: allowReverse})
+	fmt.Fprintln(os.Stderr, l.TravelReport(result))
+	return l
+}
+
+// Optimize reorders the lines of the layer to cut down on pen-up travel, see route.Order
+func (l Layer) Optimize(opts route.Options) (Layer, route.Result) {
+	ordered, result := route.Order(l.linelikes, opts)
+	l.linelikes = ordered
+	return l, result
+}
+
+// TravelReport describes how much pen-up travel the result of Optimize saved
+func (l Layer) TravelReport(result route.Result) string {
+	state := "converged"
+	if !result.Converged {
+		state = "ran out of time"
+	}
+	return fmt.Sprintf("Layer '%s': %d curves, up distance %.2fm before, %.2fm after (%.0f%% less), %d passes in %s, %s",
+		l.name, result.Lines, imageSpaceToMeters(result.Before), imageSpaceToMeters(result.After), 100*result.Saved(),
+		result.Passes, result.Duration.Round(time.Millisecond), state)
+}
+
+// Chain joins lines whose ends are no further than tolerance apart into continuous paths, so that the pen doesn't
+// lift between them, see route.Chain
+func (l Layer) Chain(tolerance float64) (Layer, route.ChainResult) {
+	chained, result := route.Chain(l.linelikes, tolerance)
+	l.linelikes = chained
+	return l, result
+}
+
+// ChainReport describes how many pen lifts the result of Chain saved
+func (l Layer) ChainReport(result route.ChainResult) string {
+	return fmt.Sprintf("Layer '%s': chained %d curves into %d, saving %s of pen lifts",
+		l.name, result.Before, result.After, (motion.DefaultModel().LiftTime() * time.Duration(result.Before-result.After)).Round(time.Second))
+}
+
+// RemoveOverlaps takes out the parts of lines that retrace earlier lines within tolerance, see route.RemoveOverlaps
+func (l Layer) RemoveOverlaps(tolerance float64) (Layer, route.OverlapResult) {
+	kept, result := route.RemoveOverlaps(l.linelikes, tolerance)
+	l.linelikes = kept
+	return l, result
+}
+
+// OverlapReport describes how much length the result of RemoveOverlaps took out
+func (l Layer) OverlapReport(result route.OverlapResult) string {
+	share := 0.0
+	if result.Length > 0 {
+		share = 100 * result.Removed / result.Length
+	}
+	return fmt.Sprintf("Layer '%s': removed %.2fm of overlapping strokes, out of %.2fm of lines and arcs (%.1f%%)",
+		l.name, imageSpaceToMeters(result.Removed), imageSpaceToMeters(result.Length), share)
+}
+
+// SimplifyResult counts the chunks of the lines of a layer before and after Simplify
+type SimplifyResult struct {
+	Before int
+	After  int
+}
+
+// Simplify cuts down on the chunks of the paths of the layer, see lines.Path.Simplify. Other lines are kept as
+// they are.
+func (l Layer) Simplify(s lines.Simplification) (Layer, SimplifyResult) {
+	result := SimplifyResult{}
+	linelikes := make([]lines.LineLike, len(l.linelikes))
+	for i, line := range l.linelikes {
+		linelikes[i] = line
+		if line == nil {
+			continue
 		}
-		line := inputLines[minIndex]
-		if isReversed {
-			line = line.Reverse()
+		result.Before += len(lines.ToPath(line).Chunks())
+		if path, ok := line.(lines.Path); ok {
+			linelikes[i] = path.Simplify(s)
 		}
-		lns = append(lns, line)
-		inputLines[minIndex] = inputLines[len(inputLines)-1]
-		inputLines = inputLines[:len(inputLines)-1]
-		pt = line.End()
+		result.After += len(lines.ToPath(linelikes[i]).Chunks())
 	}
-	if len(l.linelikes) != len(lns) {
-		panic(fmt.Errorf("did not get the same number of lines %s vs %s", l.linelikes, lns))
+	l.linelikes = linelikes
+	return l, result
+}
+
+// SimplifyReport describes how many chunks the result of Simplify saved
+func (l Layer) SimplifyReport(result SimplifyResult) string {
+	share := 0.0
+	if result.Before > 0 {
+		share = 100 * (1 - float64(result.After)/float64(result.Before))
 	}
-	l.linelikes = lns
-	return l
+	return fmt.Sprintf("Layer '%s': simplified %d chunks into %d (%.0f%% fewer)", l.name, result.Before, result.After, share)
 }
 
 func (l Layer) XML(i int) xmlwriter.Elem {
@@ -4,38 +4,169 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"math/rand"
 	"slices"
 	"strings"
 
+	"github.com/libeks/go-plotter-svg/pen"
 	"github.com/libeks/go-plotter-svg/primitives"
 )
 
 func SceneLibrary() sceneLibrary {
 	return sceneLibrary{
-		scenes: make(map[string]func(b primitives.BBox) Document),
+		scenes: make(map[string]Scene),
 	}
 }
 
 type sceneLibrary struct {
 	// map keys are considered to be case insensitive
-	scenes map[string]func(b primitives.BBox) Document
+	scenes map[string]Scene
 }
 
-func (l *sceneLibrary) Add(name string, scene func(b primitives.BBox) Document) error {
+// Scene is a scene of the library, along with the parameters it can be rendered with
+type Scene struct {
+	Name        string
+	Params      []Param
+	Random      bool // whether the scene depends on the seed it's rendered with
+	Description string
+	Tags        []string
+	Pens        []pen.Pen // the pens that the scene is meant to be plotted with, if it calls for particular ones
+	MultiPage   bool      // whether the scene may take more than one page
+	Expensive   bool      // whether the scene takes long to render, such that it's left out of render -all
+	render      func(b primitives.BBox, p Params, r *rand.Rand) Document
+}
+
+// SceneOption sets some of the metadata of a scene, when it's added to the library
+type SceneOption func(s *Scene)
+
+func WithDescription(description string) SceneOption {
+	return func(s *Scene) {
+		s.Description = description
+	}
+}
+
+func WithTags(tags ...string) SceneOption {
+	return func(s *Scene) {
+		s.Tags = append(s.Tags, tags...)
+	}
+}
+
+func WithPens(pens ...pen.Pen) SceneOption {
+	return func(s *Scene) {
+		s.Pens = append(s.Pens, pens...)
+	}
+}
+
+func MultiPage() SceneOption {
+	return func(s *Scene) {
+		s.MultiPage = true
+	}
+}
+
+func Expensive() SceneOption {
+	return func(s *Scene) {
+		s.Expensive = true
+	}
+}
+
+// HasTag tells whether the scene has the tag, ignoring case
+func (s Scene) HasTag(tag string) bool {
+	return slices.ContainsFunc(s.Tags, func(t string) bool { return strings.EqualFold(t, tag) })
+}
+
+// Defaults returns the default value of each parameter
+func (s Scene) Defaults() Params {
+	p := Params{}
+	for _, param := range s.Params {
+		p[param.Name] = param.Default
+	}
+	return p
+}
+
+// WithValues returns the default parameters, overridden by the values, which are either strings from the
+// command line, or values decoded from JSON
+func (s Scene) WithValues(values map[string]any) (Params, error) {
+	p := s.Defaults()
+	names := slices.Sorted(maps.Keys(values))
+	for _, name := range names {
+		i := slices.IndexFunc(s.Params, func(param Param) bool { return param.Name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("scene '%s' has no parameter '%s'", s.Name, name)
+		}
+		v, err := s.Params[i].value(values[name])
+		if err != nil {
+			return nil, err
+		}
+		p[name] = v
+	}
+	return p, nil
+}
+
+// Render generates the scene, taking all random numbers from a source seeded with seed, so that rendering
+// with the same parameters and seed always gives the same document. The document records its Source.
+func (s Scene) Render(b primitives.BBox, p Params, seed int64) Document {
+	doc := s.render(b, p, rand.New(rand.NewSource(seed)))
+	return doc.WithSource(Source{Scene: s.Name, Seed: seed, Params: p})
+}
+
+func (l *sceneLibrary) Add(name string, scene func(b primitives.BBox) Document, options ...SceneOption) error {
+	return l.AddWithParams(name, nil, func(b primitives.BBox, _ Params) Document { return scene(b) }, options...)
+}
+
+// AddWithParams adds a scene that takes parameters. The scene gets the values of all of its parameters,
+// with their defaults unless overridden.
+func (l *sceneLibrary) AddWithParams(name string, params []Param, scene func(b primitives.BBox, p Params) Document, options ...SceneOption) error {
+	return l.add(name, params, false, func(b primitives.BBox, p Params, _ *rand.Rand) Document { return scene(b, p) }, options)
+}
+
+// AddRandom adds a scene that uses random numbers. It must take all of them from r, never from the global
+// source of math/rand, so that it can be rendered again from its seed.
+func (l *sceneLibrary) AddRandom(name string, params []Param, scene func(b primitives.BBox, p Params, r *rand.Rand) Document, options ...SceneOption) error {
+	return l.add(name, params, true, scene, options)
+}
+
+func (l *sceneLibrary) add(name string, params []Param, random bool, scene func(b primitives.BBox, p Params, r *rand.Rand) Document, options []SceneOption) error {
 	lowerName := strings.ToLower(name)
 	if _, ok := l.scenes[lowerName]; ok {
 		return errors.New(fmt.Sprintf("Scene with name '%s' already added", name))
 	}
-	l.scenes[lowerName] = scene
+	for _, param := range params {
+		if _, err := param.value(param.Default); err != nil {
+			return fmt.Errorf("Scene '%s' has an invalid default: %w", name, err)
+		}
+	}
+	s := Scene{Name: lowerName, Params: params, Random: random, render: scene}
+	for _, option := range options {
+		option(&s)
+	}
+	l.scenes[lowerName] = s
 	return nil
 }
 
-func (l *sceneLibrary) Get(name string) (func(b primitives.BBox) Document, error) {
+// Get returns the scene, rendered with the default parameters and the given seed
+func (l *sceneLibrary) Get(name string, seed int64) (func(b primitives.BBox) Document, error) {
+	scene, err := l.GetScene(name)
+	if err != nil {
+		return nil, err
+	}
+	return func(b primitives.BBox) Document { return scene.Render(b, scene.Defaults(), seed) }, nil
+}
+
+func (l *sceneLibrary) GetScene(name string) (Scene, error) {
 	lowerName := strings.ToLower(name)
 	if scene, ok := l.scenes[lowerName]; ok {
 		return scene, nil
 	}
-	return nil, errors.New(fmt.Sprintf("Couldn't find scene with name '%s'", name))
+	return Scene{}, errors.New(fmt.Sprintf("Couldn't find scene with name '%s'", name))
+}
+
+// Scenes returns all the scenes of the library, sorted by name
+func (l *sceneLibrary) Scenes() []Scene {
+	scenes := []Scene{}
+	for _, name := range l.GetNames() {
+		scenes = append(scenes, l.scenes[name])
+	}
+	return scenes
 }
 
 func (l *sceneLibrary) GetNames() []string {
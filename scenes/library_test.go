@@ -0,0 +1,139 @@
+package scenes
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/libeks/go-plotter-svg/pen"
+	"github.com/libeks/go-plotter-svg/primitives"
+)
+
+func TestRenderSeed(t *testing.T) {
+	b := primitives.BBox{UpperLeft: primitives.Point{X: 500, Y: 800}, LowerRight: primitives.Point{X: 12833, Y: 9500}}
+	library := GatherScenes()
+	render := func(name string, seed int64) string {
+		t.Helper()
+		scene, err := library.GetScene(name)
+		if err != nil {
+			t.Fatalf("GetScene(%s) returned error %v", name, err)
+		}
+		encoded, err := json.Marshal(scene.Render(b, scene.Defaults(), seed))
+		if err != nil {
+			t.Fatalf("Marshal() returned error %v", err)
+		}
+		return string(encoded)
+	}
+	for _, name := range []string{"truchet", "parallel-box", "radial-box", "maze", "lines-inside-box"} {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(render(name, 7), render(name, 7)); diff != "" {
+				t.Errorf("Rendering twice with the same seed gave a diff %v", diff)
+			}
+			if render(name, 7) == render(name, 8) {
+				t.Errorf("Rendering with different seeds gave the same document")
+			}
+		})
+	}
+}
+
+func TestRenderSource(t *testing.T) {
+	library := GatherScenes()
+	scene, err := library.GetScene("circles-in-square")
+	if err != nil {
+		t.Fatalf("GetScene() returned error %v", err)
+	}
+	params := Params{"spacing": 200.0, "color": "blue"}
+	doc := scene.Render(primitives.BBox{LowerRight: primitives.Point{X: 1000, Y: 1000}}, params, 3)
+	got, ok := doc.Source()
+	if !ok {
+		t.Fatalf("Source() returned no source")
+	}
+	want := Source{Scene: "circles-in-square", Seed: 3, Params: params}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected diff %v", diff)
+	}
+}
+
+func TestProcessSource(t *testing.T) {
+	library := GatherScenes()
+	scene, err := library.GetScene("circles-in-square")
+	if err != nil {
+		t.Fatalf("GetScene() returned error %v", err)
+	}
+	params := Params{"spacing": 200.0, "color": "blue"}
+	processing := Processing{Chain: 5, Optimize: true, OptimizeBudget: time.Second}
+	doc := scene.Render(primitives.BBox{LowerRight: primitives.Point{X: 1000, Y: 1000}}, params, 3).Process(processing, io.Discard)
+	got, ok := doc.Source()
+	if !ok {
+		t.Fatalf("Source() returned no source")
+	}
+	want := Source{Scene: "circles-in-square", Seed: 3, Params: params, Processing: &processing}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected diff %v", diff)
+	}
+}
+
+func TestSceneOptions(t *testing.T) {
+	render := func(b primitives.BBox) Document { return Document{} }
+	tests := []struct {
+		name    string
+		options []SceneOption
+		want    Scene
+	}{
+		{
+			name: "no options",
+			want: Scene{Name: "scene"},
+		},
+		{
+			name: "all options",
+			options: []SceneOption{
+				WithDescription("a test scene"),
+				WithTags("test", "calibration"),
+				WithPens(pen.Micron05),
+				MultiPage(),
+				Expensive(),
+			},
+			want: Scene{
+				Name:        "scene",
+				Description: "a test scene",
+				Tags:        []string{"test", "calibration"},
+				Pens:        []pen.Pen{pen.Micron05},
+				MultiPage:   true,
+				Expensive:   true,
+			},
+		},
+		{
+			name:    "tags add up",
+			options: []SceneOption{WithTags("test"), WithTags("truchet")},
+			want:    Scene{Name: "scene", Tags: []string{"test", "truchet"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			library := SceneLibrary()
+			if err := library.Add("Scene", render, tt.options...); err != nil {
+				t.Fatalf("Add() returned error %v", err)
+			}
+			got, err := library.GetScene("scene")
+			if err != nil {
+				t.Fatalf("GetScene() returned error %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got, cmpopts.IgnoreUnexported(Scene{})); diff != "" {
+				t.Errorf("Unexpected diff %v", diff)
+			}
+		})
+	}
+}
+
+func TestHasTag(t *testing.T) {
+	scene := Scene{Tags: []string{"truchet", "Test"}}
+	for tag, want := range map[string]bool{"truchet": true, "TRUCHET": true, "test": true, "foldable": false, "": false} {
+		if got := scene.HasTag(tag); got != want {
+			t.Errorf("HasTag(%s) = %v, want %v", tag, got, want)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package scenes
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type ParamType int
+
+const (
+	IntParam ParamType = iota
+	FloatParam
+	BoolParam
+	StringParam
+)
+
+func (t ParamType) String() string {
+	switch t {
+	case IntParam:
+		return "int"
+	case FloatParam:
+		return "float"
+	case BoolParam:
+		return "bool"
+	}
+	return "string"
+}
+
+// Param describes a setting of a scene that can be changed from the command line
+type Param struct {
+	Name        string
+	Type        ParamType
+	Default     any      // int, float64, bool or string, to match Type
+	Min         float64  // lower bound of int and float parameters, if Min < Max
+	Max         float64  // upper bound of int and float parameters, if Min < Max
+	Choices     []string // allowed values of string parameters, any value is allowed if empty
+	Description string
+}
+
+func IntP(name string, def, min, max int, description string) Param {
+	return Param{Name: name, Type: IntParam, Default: def, Min: float64(min), Max: float64(max), Description: description}
+}
+
+func FloatP(name string, def, min, max float64, description string) Param {
+	return Param{Name: name, Type: FloatParam, Default: def, Min: min, Max: max, Description: description}
+}
+
+func BoolP(name string, def bool, description string) Param {
+	return Param{Name: name, Type: BoolParam, Default: def, Description: description}
+}
+
+func StringP(name string, def string, choices []string, description string) Param {
+	return Param{Name: name, Type: StringParam, Default: def, Choices: choices, Description: description}
+}
+
+func (p Param) String() string {
+	s := fmt.Sprintf("%s (%s, default %v", p.Name, p.Type, p.Default)
+	if p.Min < p.Max {
+		s += fmt.Sprintf(", %v to %v", p.Min, p.Max)
+	}
+	if len(p.Choices) > 0 {
+		s += fmt.Sprintf(", one of %s", strings.Join(p.Choices, ", "))
+	}
+	s += ")"
+	if p.Description != "" {
+		s += ": " + p.Description
+	}
+	return s
+}
+
+// value converts v into the type of the parameter and checks that it's allowed. Strings are parsed, so that
+// values can come from the command line as well as from JSON.
+func (p Param) value(v any) (any, error) {
+	var ret any
+	switch p.Type {
+	case IntParam:
+		switch x := v.(type) {
+		case int:
+			ret = x
+		case float64:
+			if x != math.Trunc(x) {
+				return nil, fmt.Errorf("parameter '%s' must be an integer, got %v", p.Name, x)
+			}
+			ret = int(x)
+		case string:
+			i, err := strconv.Atoi(strings.TrimSpace(x))
+			if err != nil {
+				return nil, fmt.Errorf("parameter '%s' must be an integer, got '%s'", p.Name, x)
+			}
+			ret = i
+		}
+	case FloatParam:
+		switch x := v.(type) {
+		case int:
+			ret = float64(x)
+		case float64:
+			ret = x
+		case string:
+			f, err := strconv.ParseFloat(strings.TrimSpace(x), 64)
+			if err != nil {
+				return nil, fmt.Errorf("parameter '%s' must be a number, got '%s'", p.Name, x)
+			}
+			ret = f
+		}
+	case BoolParam:
+		switch x := v.(type) {
+		case bool:
+			ret = x
+		case string:
+			b, err := strconv.ParseBool(strings.TrimSpace(x))
+			if err != nil {
+				return nil, fmt.Errorf("parameter '%s' must be true or false, got '%s'", p.Name, x)
+			}
+			ret = b
+		}
+	case StringParam:
+		if x, ok := v.(string); ok {
+			if len(p.Choices) > 0 && !slices.Contains(p.Choices, x) {
+				return nil, fmt.Errorf("parameter '%s' must be one of %s, got '%s'", p.Name, strings.Join(p.Choices, ", "), x)
+			}
+			ret = x
+		}
+	}
+	if ret == nil {
+		return nil, fmt.Errorf("parameter '%s' must be of type %s, got %v", p.Name, p.Type, v)
+	}
+	if p.Min < p.Max {
+		var f float64
+		switch x := ret.(type) {
+		case int:
+			f = float64(x)
+		case float64:
+			f = x
+		}
+		if f < p.Min || f > p.Max {
+			return nil, fmt.Errorf("parameter '%s' must be between %v and %v, got %v", p.Name, p.Min, p.Max, ret)
+		}
+	}
+	return ret, nil
+}
+
+// Params are the values of the parameters of a scene, keyed by name
+type Params map[string]any
+
+// Int returns the value of an int parameter, it panics if the scene didn't declare it
+func (p Params) Int(name string) int {
+	return get[int](p, name)
+}
+
+func (p Params) Float(name string) float64 {
+	return get[float64](p, name)
+}
+
+func (p Params) Bool(name string) bool {
+	return get[bool](p, name)
+}
+
+func (p Params) String(name string) string {
+	return get[string](p, name)
+}
+
+func get[T any](p Params, name string) T {
+	v, ok := p[name].(T)
+	if !ok {
+		panic(fmt.Sprintf("Scene parameter '%s' was not declared as %T", name, v))
+	}
+	return v
+}
+
+// SplitSetting splits a "name=value" setting from the command line
+func SplitSetting(s string) (string, string, error) {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok || strings.TrimSpace(name) == "" {
+		return "", "", fmt.Errorf("expected a setting like name=value, got '%s'", s)
+	}
+	return strings.TrimSpace(name), value, nil
+}
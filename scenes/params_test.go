@@ -0,0 +1,78 @@
+package scenes
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWithValues(t *testing.T) {
+	scene := Scene{
+		Name: "test",
+		Params: []Param{
+			IntP("n", 10, 1, 100, ""),
+			FloatP("spacing", 20, 0, 0, ""),
+			BoolP("frame", true, ""),
+			StringP("color", "red", []string{"red", "blue"}, ""),
+		},
+	}
+	tests := []struct {
+		name    string
+		values  map[string]any
+		want    Params
+		wantErr string
+	}{
+		{
+			name:   "defaults",
+			values: nil,
+			want:   Params{"n": 10, "spacing": 20.0, "frame": true, "color": "red"},
+		},
+		{
+			name:   "from the command line",
+			values: map[string]any{"n": "12", "spacing": "40.5", "frame": "false", "color": "blue"},
+			want:   Params{"n": 12, "spacing": 40.5, "frame": false, "color": "blue"},
+		},
+		{
+			name:   "from JSON",
+			values: map[string]any{"n": 12.0, "spacing": 40.0, "frame": false},
+			want:   Params{"n": 12, "spacing": 40.0, "frame": false, "color": "red"},
+		},
+		{
+			name:    "out of range",
+			values:  map[string]any{"n": "0"},
+			wantErr: "parameter 'n' must be between 1 and 100, got 0",
+		},
+		{
+			name:    "not an integer",
+			values:  map[string]any{"n": 1.5},
+			wantErr: "parameter 'n' must be an integer, got 1.5",
+		},
+		{
+			name:    "not a choice",
+			values:  map[string]any{"color": "green"},
+			wantErr: "parameter 'color' must be one of red, blue, got 'green'",
+		},
+		{
+			name:    "unknown",
+			values:  map[string]any{"size": "1"},
+			wantErr: "scene 'test' has no parameter 'size'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := scene.WithValues(tt.values)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("WithValues() returned error %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Unexpected diff %v", diff)
+			}
+		})
+	}
+}
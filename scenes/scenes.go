@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
-	"runtime"
+	"os"
 
 	"github.com/libeks/go-plotter-svg/collections"
 	"github.com/libeks/go-plotter-svg/fonts"
@@ -12,6 +12,7 @@ import (
 	"github.com/libeks/go-plotter-svg/maths"
 	"github.com/libeks/go-plotter-svg/maze"
 	"github.com/libeks/go-plotter-svg/objects"
+	"github.com/libeks/go-plotter-svg/occlusion"
 	"github.com/libeks/go-plotter-svg/pack"
 	"github.com/libeks/go-plotter-svg/pen"
 	"github.com/libeks/go-plotter-svg/primitives"
@@ -21,49 +22,117 @@ import (
 func GatherScenes() sceneLibrary {
 	library := SceneLibrary()
 
-	library.Add("lines-inside-box", func(b primitives.BBox) Document { return getLinesInsideScene(b, 1000) })
-	library.Add("line-field", getLineFieldInObjects)
-	library.Add("radial-box", radialBoxScene)
-	library.Add("parallel-box", parallelBoxScene)
-	library.Add("parallel-sine-field", parallelSineFieldsScene)
-	library.Add("parallel-coherent", parallelCoherentSineFieldsScene)
-	library.Add("circles-in-square", circlesInSquareScene)
-	library.Add("rising-sun", getRisingSun)
-	library.Add("circle-line-segments", getCirlceLineSegmentScene)
-	library.Add("maze", mazeScene)
+	library.AddRandom("lines-inside-box", []Param{
+		IntP("n", 1000, 1, 10000, "number of lines"),
+	}, func(b primitives.BBox, p Params, r *rand.Rand) Document { return getLinesInsideScene(b, r, p.Int("n")) },
+		WithDescription("random lines, clipped to a circle"))
+	library.Add("line-field", getLineFieldInObjects,
+		WithDescription("a radial line field, clipped to four overlapping rectangles in different colors"))
+	library.AddRandom("radial-box", nil, radialBoxScene,
+		WithDescription("a grid of boxes, each filled with lines radiating from a point near its center"))
+	library.AddRandom("parallel-box", nil, parallelBoxScene,
+		WithDescription("a grid of boxes, each filled with parallel lines at a random angle"))
+	library.Add("parallel-sine-field", parallelSineFieldsScene,
+		WithDescription("fields of parallel lines at different angles, whose spacing follows a sine wave"))
+	library.Add("parallel-coherent", parallelCoherentSineFieldsScene,
+		WithDescription("two slightly shifted fields of parallel lines with sine spacing, in two colors"))
+	library.AddWithParams("circles-in-square", []Param{
+		FloatP("spacing", 100, 10, 5000, "distance between consecutive circles"),
+		StringP("color", "red", nil, "color of the circles"),
+	}, circlesInSquareScene,
+		WithDescription("concentric circles, clipped to a square"))
+	library.AddRandom("rising-sun", nil, getRisingSun,
+		WithDescription("lines that rise from the horizon and hug a sun, which is filled with concentric circles"))
+	library.Add("circle-line-segments", getCirlceLineSegmentScene,
+		WithDescription("a grid of short strokes, whose angle and color depend on two sets of concentric rings"))
+	library.AddRandom("maze", nil, mazeScene,
+		WithDescription("a random maze, along with the path that solves it"))
+	library.AddRandom("stacked-circles", []Param{
+		IntP("n", 40, 1, 1000, "number of circles"),
+		FloatP("spacing", 40, 5, 1000, "distance between the rings that fill each circle"),
+	}, stackedCirclesScene,
+		WithDescription("random circles filled with rings, each hiding the circles behind it"))
 
 	// Truchet
-	library.Add("truchet", getTruchetScene)
-	library.Add("sweep-truchet", getSweepTruchet)
+	library.AddRandom("truchet", []Param{
+		IntP("n", 30, 1, 500, "number of tiles along each side"),
+		StringP("tiles", "6-non-crossing-side", truchetTileSetNames(), "set of tiles to pick from"),
+		FloatP("width", 10, 1, 200, "width of the lines"),
+	}, getTruchetScene,
+		WithDescription("a grid of random Truchet tiles, with the curves that they join into"),
+		WithTags("truchet"))
+	library.AddRandom("sweep-truchet", []Param{
+		FloatP("spacing", 20, 1, 500, "distance between the offset curves"),
+	}, getSweepTruchet,
+		WithDescription("Truchet curves, each drawn as a sweep of offset curves"),
+		WithTags("truchet"))
 
 	// Marching Squares
-	library.Add("circle-marching-square", getCircleMarchingSquares)
-	library.Add("circle-marching-square-artifact", getCircleArtifactMarchingSquares)
-	library.Add("circle-tricolor-marching-square", getThreeColorCircleMarchingSquares)
-	library.Add("perlin-marching-square", getPerlinMarchingSquares)
-	library.Add("random-marching-square", getRandomMarchingSquares)
+	library.Add("circle-marching-square", getCircleMarchingSquares,
+		WithDescription("contours of the distance to the nearest of three points"),
+		WithTags("marching-squares"))
+	library.Add("circle-marching-square-artifact", getCircleArtifactMarchingSquares,
+		WithDescription("contours of the distance to the nearest of five points, rippled by a sine wave"),
+		WithTags("marching-squares"))
+	library.Add("circle-tricolor-marching-square", getThreeColorCircleMarchingSquares,
+		WithDescription("contours of the differences of distances to three points, in three colors"),
+		WithTags("marching-squares"))
+	library.Add("perlin-marching-square", getPerlinMarchingSquares,
+		WithDescription("contours of Perlin noise at several scales"),
+		WithTags("marching-squares"))
+	library.Add("random-marching-square", getRandomMarchingSquares,
+		WithDescription("contours of a mix of tangent and sine waves"),
+		WithTags("marching-squares"))
 
 	// Foldables
-	library.Add("foldable-rhombi", foldableRhombicuboctahedronIDScene)
-	library.Add("foldable-triangle-prism", foldableRightTrianglePrismIDScene)
-	library.Add("foldable-rhombi-sans-corner", foldableRhombicuboctahedronSansCornersScene)
-	library.Add("foldable-rhombi-sans-corner-tricolor", foldableRhombicuboctahedronSansCornersTricolorScene)
-	library.Add("foldable-cube", foldableCubeIDScene)
-	library.Add("foldable-cut-cube", foldableCutCornerScene)
-	library.Add("foldable-voronoi", foldableVoronoiScene)
+	foldableOptions := []SceneOption{WithTags("foldable"), MultiPage()}
+	library.Add("foldable-rhombi", foldableRhombicuboctahedronIDScene, append(foldableOptions,
+		WithDescription("a foldable rhombicuboctahedron, with its faces numbered"))...)
+	library.Add("foldable-triangle-prism", foldableRightTrianglePrismIDScene, append(foldableOptions,
+		WithDescription("a foldable right triangular prism, with its faces numbered"))...)
+	library.Add("foldable-rhombi-sans-corner", foldableRhombicuboctahedronSansCornersScene, append(foldableOptions,
+		WithDescription("a foldable rhombicuboctahedron without its corner triangles, in two colors"),
+		WithPens(pen.BicIntensityBrushTip))...)
+	library.Add("foldable-rhombi-sans-corner-tricolor", foldableRhombicuboctahedronSansCornersTricolorScene, append(foldableOptions,
+		WithDescription("a foldable rhombicuboctahedron without its corner triangles, in three colors"),
+		WithPens(pen.CrayolaSuperTips))...)
+	library.Add("foldable-cube", foldableCubeIDScene, append(foldableOptions,
+		WithDescription("a foldable cube, with its faces numbered"))...)
+	library.Add("foldable-cut-cube", foldableCutCornerScene, append(foldableOptions,
+		WithDescription("a foldable cube with a corner cut off"))...)
+	library.AddRandom("foldable-voronoi", nil, foldableVoronoiScene, append(foldableOptions,
+		WithDescription("a foldable made of random Voronoi cells"))...)
 
 	// Test cards, used to calibrate pens
-	library.Add("test-density-v1", densityTestCardV1Scene)
-	library.Add("test-density-v2", densityTestCardV2Scene)
-	library.Add("test-pen-height", penHeightTestCardScene)
+	library.Add("test-density-v1", densityTestCardV1Scene,
+		WithDescription("patches of lines at increasing spacing in four colors, to find the spacing of a pen"),
+		WithTags("calibration"))
+	library.Add("test-density-v2", densityTestCardV2Scene,
+		WithDescription("labeled patches of lines at increasing spacing in eleven colors, to find the spacing of a pen"),
+		WithTags("calibration"))
+	library.Add("test-pen-height", penHeightTestCardScene,
+		WithDescription("rows of small circles, one strip per pen, to tune the height of the pen"),
+		WithTags("calibration"))
 
 	// Test scenes, more of a proof-of-concept
-	library.Add("polygon-box", polygonScene)
-	library.Add("box-fill", testBoxFillScene)
-	library.Add("font", fontScene)
-	library.Add("text", textScene)
-	// The rectangle packing test is computationally expensive, excluding to keep --render-all short
-	// library.Add("rectangle-packing-test", rectanglePackginScene)
+	library.Add("polygon-box", polygonScene,
+		WithDescription("polygons with the largest square that fits in each, a letter and a line fill"),
+		WithTags("test"))
+	library.Add("box-fill", testBoxFillScene,
+		WithDescription("polygons filled with each of the pens, at the spacing of the pen"),
+		WithTags("test"),
+		WithPens(pen.Micron10, pen.SharpieCreativeMarker, pen.BicIntensityFineTip, pen.BicIntensityBrushTip))
+	library.Add("font", fontScene,
+		WithDescription("the outline of a glyph of a system font"),
+		WithTags("test"))
+	library.Add("text", textScene,
+		WithDescription("text rendered with a system font"),
+		WithTags("test"))
+	library.AddRandom("rectangle-packing-test", nil, rectanglePackginScene,
+		WithDescription("random rectangles packed onto as few pages as possible"),
+		WithTags("test"),
+		MultiPage(),
+		Expensive())
 
 	return library
 }
@@ -154,16 +223,16 @@ func parallelCoherentSineFieldsScene(b primitives.BBox) Document {
 
 // circlesInSquareScene are concentric circles in a square
 // due to overlap, there tends to be a darkening on the left with certain pens
-func circlesInSquareScene(b primitives.BBox) Document {
+func circlesInSquareScene(b primitives.BBox, p Params) Document {
 	scene := Document{}.WithGuides()
 	layer1 := collections.LimitCirclesToShape(
 		collections.ConcentricCircles(
-			b, b.Center(), 100,
+			b, b.Center(), p.Float("spacing"),
 		),
 		objects.PolygonFromBBox(b),
 	)
 	scene = scene.AddLayer(NewLayer("frame").WithLineLike(lines.LinesFromBBox(b)).WithOffset(0, 0))
-	scene = scene.AddLayer(NewLayer("content").WithLineLike(layer1).WithOffset(0, 0).WithColor("red"))
+	scene = scene.AddLayer(NewLayer("content").WithLineLike(layer1).WithOffset(0, 0).WithColor(p.String("color")))
 	return scene
 }
 
@@ -227,7 +296,7 @@ func testBoxFillScene(b primitives.BBox) Document {
 	}
 	for i, pen := range pens {
 		layerName := fmt.Sprintf("pen %s", pen.Name)
-		layers = append(layers, NewLayer(layerName).WithLineLike(lineLikes[i]).WithOffset(pen.XOffset, pen.YOffset).WithColor(colors[i]))
+		layers = append(layers, NewLayer(layerName).WithLineLike(lineLikes[i]).WithPen(pen).WithColor(colors[i]))
 	}
 	// ensure that the frame layer is rendered first, to make sure it isn't added to guides
 	scene = scene.AddLayer(NewLayer("guides").WithLineLike(guides).WithOffset(0, 0).WithColor("grey"))
@@ -270,7 +339,7 @@ func parallelSineFieldsScene(b primitives.BBox) Document {
 	return scene
 }
 
-func parallelBoxScene(b primitives.BBox) Document {
+func parallelBoxScene(b primitives.BBox, _ Params, r *rand.Rand) Document {
 	b = b.Square()
 	minLineWidth := 20.0
 	maxLineWidth := 100.0
@@ -280,20 +349,20 @@ func parallelBoxScene(b primitives.BBox) Document {
 	segments := [][]lines.LineLike{}
 	boxes := primitives.PartitionIntoSquares(b, 10)
 	for _, minibox := range boxes.BoxIterator() {
-		spacing := maths.RandInRange(minLineWidth, maxLineWidth)
-		angle := maths.RandInRange(minAngle, maxAngle)
+		spacing := maths.RandInRange(r, minLineWidth, maxLineWidth)
+		angle := maths.RandInRange(r, minAngle, maxAngle)
 		lns := collections.LinearLineField(minibox.BBox, angle, spacing)
 		lineseg := collections.LimitLinesToShape(lns, objects.PolygonFromBBox(minibox.WithPadding(50)))
 		segments = append(segments, lines.SegmentsToLineLikes(lineseg))
 	}
-	layer1, layer2 := collections.RandomlyAllocateSegments(segments, 0.5)
+	layer1, layer2 := collections.RandomlyAllocateSegments(r, segments, 0.5)
 	scene = scene.AddLayer(NewLayer("frame").WithLineLike(lines.LinesFromBBox(b)).WithOffset(0, 0))
 	scene = scene.AddLayer(NewLayer("content").WithLineLike(layer1).WithOffset(0, 0).WithColor("red"))
 	scene = scene.AddLayer(NewLayer("content2").WithLineLike(layer2).WithOffset(0, 0).WithColor("blue"))
 	return scene
 }
 
-func radialBoxScene(b primitives.BBox) Document {
+func radialBoxScene(b primitives.BBox, _ Params, r *rand.Rand) Document {
 	b = b.Square()
 	nSegments := 15
 	exclusionRadius := 100.0
@@ -303,15 +372,15 @@ func radialBoxScene(b primitives.BBox) Document {
 	boxes := primitives.PartitionIntoSquares(b, 10)
 	for _, minibox := range boxes.BoxIterator() {
 		boxcenter := minibox.Center()
-		xwiggle := maths.RandRangeMinusPlusOne() * wiggle
-		ywiggle := maths.RandRangeMinusPlusOne() * wiggle
+		xwiggle := maths.RandRangeMinusPlusOne(r) * wiggle
+		ywiggle := maths.RandRangeMinusPlusOne(r) * wiggle
 		center := primitives.Point{X: boxcenter.X + xwiggle, Y: boxcenter.Y + ywiggle}
 		segments = append(segments, radialBoxWithCircleExclusion(objects.PolygonFromBBox(minibox.WithPadding(50)), center, nSegments, exclusionRadius))
 	}
 	layer1 := []lines.LineLike{}
 	layer2 := []lines.LineLike{}
 	for _, segs := range segments {
-		if rand.Float64() > 0.5 {
+		if r.Float64() > 0.5 {
 			layer1 = append(layer1, segs...)
 		} else {
 			layer2 = append(layer2, segs...)
@@ -323,15 +392,15 @@ func radialBoxScene(b primitives.BBox) Document {
 	return scene
 }
 
-func getLinesInsidePolygonScene(b primitives.BBox, poly objects.Object, n int) Document {
+func getLinesInsidePolygonScene(b primitives.BBox, r *rand.Rand, poly objects.Object, n int) Document {
 	scene := Document{}
 	ls := []lines.LineLike{}
 	for {
 		if len(ls) == n {
 			break
 		}
-		x := rand.Float64()*(b.Width()) + b.UpperLeft.X
-		y := rand.Float64()*(b.Height()) + b.UpperLeft.Y
+		x := r.Float64()*(b.Width()) + b.UpperLeft.X
+		y := r.Float64()*(b.Height()) + b.UpperLeft.Y
 		if poly.Inside(primitives.Point{X: x, Y: y}) {
 			ls = append(ls, lines.LineSegment{P1: primitives.Point{X: x, Y: y}, P2: primitives.Point{X: x + 100, Y: y}})
 		}
@@ -341,12 +410,12 @@ func getLinesInsidePolygonScene(b primitives.BBox, poly objects.Object, n int) D
 	return scene
 }
 
-func getLinesInsideScene(b primitives.BBox, n int) Document {
+func getLinesInsideScene(b primitives.BBox, r *rand.Rand, n int) Document {
 	poly := objects.Circle{
 		Center: primitives.Point{X: 5000, Y: 5000},
 		Radius: 1000,
 	}
-	return getLinesInsidePolygonScene(b, poly, n)
+	return getLinesInsidePolygonScene(b, r, poly, n)
 }
 
 func radialBoxWithCircleExclusion(container objects.Object, center primitives.Point, nLines int, radius float64) []lines.LineLike {
@@ -368,7 +437,7 @@ func getOffsetForCurves(curves []lines.LineLike, distance float64, n int) []line
 	return outlineCurves
 }
 
-func getRisingSun(b primitives.BBox) Document {
+func getRisingSun(b primitives.BBox, _ Params, r *rand.Rand) Document {
 	scene := Document{}.WithGuides()
 	scene = scene.AddLayer(NewLayer("frame").WithLineLike(lines.LinesFromBBox(b)).WithOffset(0, 0))
 	sun := objects.Circle{
@@ -386,7 +455,7 @@ func getRisingSun(b primitives.BBox) Document {
 	}
 
 	scene = scene.AddLayer(NewLayer("sun_huggers").WithLineLike(sunHuggers.Render(b)).WithColor("black").WithWidth(20).MinimizePath(true))
-	scene = scene.AddLayer(NewLayer("sun").WithLineLike(collections.ConcentricCirclesInCircle(sun, 10)).WithColor("red").WithWidth(20).RandomizedClosedCurves())
+	scene = scene.AddLayer(NewLayer("sun").WithLineLike(collections.ConcentricCirclesInCircle(sun, 10)).WithColor("red").WithWidth(20).RandomizedClosedCurves(r))
 	// scene = scene.AddLayer(NewLayer("gridlines").WithLineLike(grid.GetGridLines()).WithColor("black").WithWidth(10))
 	return scene
 }
@@ -465,14 +534,7 @@ func fontScene(b primitives.BBox) Document {
 	scene := Document{}.WithGuides()
 	scene = scene.AddLayer(NewLayer("frame").WithLineLike(lines.LinesFromBBox(b)).WithOffset(0, 0))
 
-	var fontFile string
-	if runtime.GOOS == "windows" {
-		fontFile = "C:/Windows/Fonts/bahnschrift.ttf"
-	} else {
-		// MacOS
-		fontFile = "/System/Library/Fonts/Palatino.ttc"
-	}
-	font, err := fonts.LoadFont(fontFile)
+	font, err := fonts.LoadFont(fonts.DefaultFile)
 	if err != nil {
 		panic(err)
 	}
@@ -640,12 +702,12 @@ func polygonScene(b primitives.BBox) Document {
 	return scene
 }
 
-func mazeScene(b primitives.BBox) Document {
+func mazeScene(b primitives.BBox, _ Params, r *rand.Rand) Document {
 	b = b.Square()
 	scene := Document{}.WithGuides()
 	scene = scene.AddLayer(NewLayer("frame").WithLineLike(lines.LinesFromBBox(b)).WithOffset(0, 0))
 
-	maze := maze.NewMaze(30)
+	maze := maze.NewMaze(r, 30)
 	mazeLines := maze.Render(b)
 
 	scene = scene.AddLayer(NewLayer("path").WithLineLike(mazeLines.Path).WithColor("red").WithWidth(20).MinimizePath(true))
@@ -653,15 +715,36 @@ func mazeScene(b primitives.BBox) Document {
 	return scene
 }
 
-func rectanglePackginScene(b primitives.BBox) Document {
+func stackedCirclesScene(b primitives.BBox, p Params, r *rand.Rand) Document {
+	b = b.Square()
+	scene := Document{}.WithGuides()
+	scene = scene.AddLayer(NewLayer("frame").WithLineLike(lines.LinesFromBBox(b)).WithOffset(0, 0))
+
+	// the circles are stacked in the order they're made, each one hiding the parts of those before it that it covers
+	shapes := []occlusion.Shape{}
+	for range p.Int("n") {
+		radius := b.Width() * (0.05 + 0.1*r.Float64())
+		center := b.UpperLeft.Add(primitives.Vector{
+			X: radius + r.Float64()*(b.Width()-2*radius),
+			Y: radius + r.Float64()*(b.Height()-2*radius),
+		})
+		circle := objects.Circle{Center: center, Radius: radius}
+		strokes := append([]lines.LineLike{circle}, collections.ConcentricCirclesInCircle(circle, p.Float("spacing"))...)
+		shapes = append(shapes, occlusion.Shape{Outline: circle, Strokes: strokes})
+	}
+	scene = scene.AddLayer(NewLayer("circles").WithLineLike(occlusion.Visible(shapes)).WithColor("black").MinimizePath(true))
+	return scene
+}
+
+func rectanglePackginScene(b primitives.BBox, _ Params, r *rand.Rand) Document {
 	doc := Document{}.WithGuides()
 
 	rectangles := []primitives.BBox{}
 	for i := range 20 {
 		rectangles = append(rectangles, primitives.BBox{
 			UpperLeft: primitives.Origin, LowerRight: primitives.Origin.Add(primitives.Vector{
-				X: 1500 + rand.Float64()*4000 + float64(i),
-				Y: 1500 + rand.Float64()*4000,
+				X: 1500 + r.Float64()*4000 + float64(i),
+				Y: 1500 + r.Float64()*4000,
 			}),
 		})
 	}
@@ -689,7 +772,7 @@ func rectanglePackginScene(b primitives.BBox) Document {
 		}
 
 	}
-	fmt.Printf("Doc has %d pages\n", solution.Pages)
+	fmt.Fprintf(os.Stderr, "Doc has %d pages\n", solution.Pages)
 	for i := range solution.Pages {
 		page := Page{}
 		page = page.AddLayer(NewLayer("frame").WithLineLike(lines.LinesFromBBox(b)).WithOffset(0, 0))
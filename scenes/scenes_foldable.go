@@ -1,6 +1,8 @@
 package scenes
 
 import (
+	"math/rand"
+
 	"github.com/libeks/go-plotter-svg/foldable"
 	"github.com/libeks/go-plotter-svg/primitives"
 )
@@ -47,8 +49,8 @@ func foldableCutCornerScene(b primitives.BBox) Document {
 	return scene
 }
 
-func foldableVoronoiScene(b primitives.BBox) Document {
-	patterns := foldable.VoronoiFoldable(b)
+func foldableVoronoiScene(b primitives.BBox, _ Params, r *rand.Rand) Document {
+	patterns := foldable.VoronoiFoldable(r, b)
 	scene := FromFoldableLayers(patterns, b)
 	return scene
 }
@@ -1,17 +1,31 @@
 package scenes
 
 import (
+	"maps"
+	"math/rand"
+	"slices"
+
 	"github.com/libeks/go-plotter-svg/curve"
 	"github.com/libeks/go-plotter-svg/lines"
 	"github.com/libeks/go-plotter-svg/primitives"
 	"github.com/libeks/go-plotter-svg/samplers"
 )
 
-func getTruchetScene(b primitives.BBox) Document {
+var truchetTileSets = map[string]curve.TruchetTileSet{
+	"4-non-crossing":      curve.Truchet4NonCrossing,
+	"4-crossing":          curve.Truchet4Crossing,
+	"6-non-crossing-side": curve.Truchet6NonCrossingSide,
+}
+
+func truchetTileSetNames() []string {
+	return slices.Sorted(maps.Keys(truchetTileSets))
+}
+
+func getTruchetScene(b primitives.BBox, p Params, r *rand.Rand) Document {
 	b = b.Square()
 	scene := Document{}.WithGuides()
 	scene = scene.AddLayer(NewLayer("frame").WithLineLike(lines.LinesFromBBox(b)).WithOffset(0, 0))
-	tileSource := samplers.RandomDataSource{}
+	tileSource := samplers.RandomDataSource{Rand: r}
 	// tileSource := samplers.ConstantDataSource{0}
 	// tileSource := samplers.InsideCircleSubDataSource{
 	// 	Radius:  0.5,
@@ -28,26 +42,24 @@ func getTruchetScene(b primitives.BBox) Document {
 	// edgeSource := samplers.RandomChooser{Values: []float64{-.25, 1.25}}
 	// edgeSource := samplers.RandomChooser{Values: []float64{.3, .7}}
 	// edgeSource := samplers.RandomChooser{Values: []float64{0, 1}}
-	// truch := curve.Truchet4NonCrossing
-	// truch := curve.Truchet4Crossing
-	truch := curve.Truchet6NonCrossingSide
-	grid := curve.NewTruchetGrid(b, 30, truch, tileSource, edgeSource, curve.MapCircularCurve)
+	truch := truchetTileSets[p.String("tiles")]
+	grid := curve.NewTruchetGrid(b, p.Int("n"), truch, tileSource, edgeSource, curve.MapCircularCurve)
 	curves := grid.GenerateCurves()
 	// scene = scene.AddLayer(NewLayer("truchet").WithControlLines(curves).WithColor("blue").WithWidth(10))
-	scene = scene.AddLayer(NewLayer("truchet").WithLineLike(curves).WithColor("red").WithWidth(10))
+	scene = scene.AddLayer(NewLayer("truchet").WithLineLike(curves).WithColor("red").WithWidth(p.Float("width")))
 	// scene = scene.AddLayer(NewLayer("gridlines").WithLineLike(grid.GetGridLines()).WithColor("black").WithWidth(10))
 
 	return scene
 }
 
-func getSweepTruchet(b primitives.BBox) Document {
+func getSweepTruchet(b primitives.BBox, p Params, r *rand.Rand) Document {
 	b = b.Square()
 	scene := Document{}.WithGuides()
 	scene = scene.AddLayer(NewLayer("frame").WithLineLike(lines.LinesFromBBox(b)).WithOffset(0, 0))
-	curves1 := curve.NewTruchetGrid(b, 3, curve.Truchet4NonCrossing, samplers.RandomDataSource{}, samplers.Constant(0.5), curve.MapCircularCircleCurve).GenerateCurves()
-	curves2 := curve.NewTruchetGrid(b, 6, curve.Truchet4NonCrossing, samplers.RandomDataSource{}, samplers.Constant(0.5), curve.MapCircularCircleCurve).GenerateCurves()
-	curves3 := curve.NewTruchetGrid(b, 12, curve.Truchet4NonCrossing, samplers.RandomDataSource{}, samplers.Constant(0.5), curve.MapCircularCircleCurve).GenerateCurves()
-	distance := 20.0
+	curves1 := curve.NewTruchetGrid(b, 3, curve.Truchet4NonCrossing, samplers.RandomDataSource{Rand: r}, samplers.Constant(0.5), curve.MapCircularCircleCurve).GenerateCurves()
+	curves2 := curve.NewTruchetGrid(b, 6, curve.Truchet4NonCrossing, samplers.RandomDataSource{Rand: r}, samplers.Constant(0.5), curve.MapCircularCircleCurve).GenerateCurves()
+	curves3 := curve.NewTruchetGrid(b, 12, curve.Truchet4NonCrossing, samplers.RandomDataSource{Rand: r}, samplers.Constant(0.5), curve.MapCircularCircleCurve).GenerateCurves()
+	distance := p.Float("spacing")
 
 	// scene = scene.AddLayer(NewLayer("truchet_offsets_1").WithControlLines(curves1).WithColor("gray").WithWidth(distance))
 	scene = scene.AddLayer(NewLayer("truchet_offsets_1").WithLineLike(getOffsetForCurves(curves1, distance, 10)).WithColor("red").WithWidth(distance))
@@ -0,0 +1,71 @@
+package scenes
+
+import (
+	"time"
+
+	"github.com/libeks/go-plotter-svg/pen"
+)
+
+// PenGroup holds the layers of a page that are plotted in a single pass, with one pen
+type PenGroup struct {
+	Name   string // the name of the pen, or the color of the layers if they don't have a pen
+	Pen    pen.Pen
+	Color  string
+	Layers []string // names of the layers of the group, without their guides
+	Page   Page     // the layers of the group, with their registration guides
+}
+
+// TimeEstimate is roughly how long the group takes to plot
+func (g PenGroup) TimeEstimate() time.Duration {
+	total := time.Duration(0)
+	for _, layer := range g.Page.layers {
+		total += layer.TimeEstimate()
+	}
+	return total
+}
+
+// SplitByPen groups the layers of the page by their pen, or by their color for layers without a pen, in the
+// order in which they first appear on the page. Each layer keeps the registration guide that GetLayers draws
+// for it, at the same position, so that the passes line up. The comb that the guides are checked against is
+// drawn in the first pass only.
+func (s Page) SplitByPen() []PenGroup {
+	all := s.GetLayers()
+	guided := s.guides && len(s.layers) >= 2
+	groups := []PenGroup{}
+	index := map[string]int{}
+	i := 0
+	if guided {
+		i = 1 // GetLayers starts with the comb
+	}
+	for _, layer := range s.layers {
+		name := penGroupName(layer)
+		g, ok := index[name]
+		if !ok {
+			g = len(groups)
+			index[name] = g
+			groups = append(groups, PenGroup{Name: name, Pen: layer.pen, Color: layer.color})
+		}
+		groups[g].Layers = append(groups[g].Layers, layer.name)
+		groups[g].Page = groups[g].Page.AddLayer(all[i])
+		i += 1
+		if guided && layer.drawGuide {
+			// GetLayers follows each guided layer with its guide
+			groups[g].Page = groups[g].Page.AddLayer(all[i])
+			i += 1
+		}
+	}
+	if guided && len(groups) > 0 {
+		groups[0].Page.layers = append([]Layer{all[0]}, groups[0].Page.layers...)
+	}
+	return groups
+}
+
+func penGroupName(layer Layer) string {
+	if layer.pen.Name != "" {
+		return layer.pen.Name
+	}
+	if layer.color != "" {
+		return layer.color
+	}
+	return "black" // the default color of the SVG
+}
@@ -0,0 +1,81 @@
+package scenes
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/pen"
+	"github.com/libeks/go-plotter-svg/primitives"
+)
+
+func TestSplitByPen(t *testing.T) {
+	line := []lines.LineLike{lines.LineSegment{P1: primitives.Origin, P2: primitives.Point{X: 100, Y: 0}}}
+	tests := []struct {
+		name   string
+		page   Page
+		want   []string   // group names
+		layers [][]string // names of the layers in each group's page
+	}{
+		{
+			name: "without guides",
+			page: Page{}.
+				AddLayer(NewLayer("a").WithLineLike(line).WithColor("red")).
+				AddLayer(NewLayer("b").WithLineLike(line).WithPen(pen.Micron01)).
+				AddLayer(NewLayer("c").WithLineLike(line).WithColor("red")).
+				AddLayer(NewLayer("d").WithLineLike(line)),
+			want:   []string{"red", "Micron 01", "black"},
+			layers: [][]string{{"a", "c"}, {"b"}, {"d"}},
+		},
+		{
+			name: "with guides",
+			page: Page{}.WithGuides().
+				AddLayer(NewLayer("a").WithLineLike(line).WithColor("red")).
+				AddLayer(NewLayer("frame").WithLineLike(line).WithNoGuide()).
+				AddLayer(NewLayer("b").WithLineLike(line).WithColor("blue")).
+				AddLayer(NewLayer("c").WithLineLike(line).WithColor("red")),
+			want: []string{"red", "black", "blue"},
+			layers: [][]string{
+				{"GUIDES-comb", "a", "GUIDES-a", "c", "GUIDES-c"},
+				{"frame"},
+				{"b", "GUIDES-b"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := tt.page.SplitByPen()
+			names := []string{}
+			layers := [][]string{}
+			for _, group := range groups {
+				names = append(names, group.Name)
+				groupLayers := []string{}
+				for _, layer := range group.Page.GetLayers() {
+					groupLayers = append(groupLayers, layer.Name())
+				}
+				layers = append(layers, groupLayers)
+			}
+			if diff := cmp.Diff(tt.want, names); diff != "" {
+				t.Errorf("Unexpected diff in groups %v", diff)
+			}
+			if diff := cmp.Diff(tt.layers, layers); diff != "" {
+				t.Errorf("Unexpected diff in layers %v", diff)
+			}
+		})
+	}
+}
+
+func TestSplitKeepsGuidePositions(t *testing.T) {
+	line := []lines.LineLike{lines.LineSegment{P1: primitives.Origin, P2: primitives.Point{X: 100, Y: 0}}}
+	page := Page{}.WithGuides().
+		AddLayer(NewLayer("a").WithLineLike(line).WithColor("red")).
+		AddLayer(NewLayer("b").WithLineLike(line).WithColor("blue"))
+	all := page.GetLayers()
+	groups := page.SplitByPen()
+	// the guide of the second layer is drawn in the second slot of the comb, even though it's alone in its file
+	got := groups[1].Page.GetLayers()[1].LineLikes()[0].Start()
+	if diff := cmp.Diff(all[len(all)-1].LineLikes()[0].Start(), got); diff != "" {
+		t.Errorf("Unexpected diff %v", diff)
+	}
+}
@@ -0,0 +1,199 @@
+package scenes
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/motion"
+	"github.com/libeks/go-plotter-svg/primitives"
+	"github.com/libeks/go-plotter-svg/units"
+)
+
+// Statistics sum up what it takes to plot some lines, with the times estimated by a motion.Model
+type Statistics struct {
+	Curves     int           // lines drawn without lifting the pen
+	Chunks     int           // path chunks that the curves are made of
+	Lifts      int           // times the pen goes down and up again, once per curve
+	Down       float64       // distance drawn, in meters
+	Up         float64       // distance traveled with the pen up, from the origin and back, in meters
+	DrawTime   time.Duration // time spent drawing, slowing down for corners
+	TravelTime time.Duration // time spent moving with the pen up
+	LiftTime   time.Duration // time spent putting the pen down and lifting it
+}
+
+// Time is the total time the plot takes
+func (s Statistics) Time() time.Duration {
+	return s.DrawTime + s.TravelTime + s.LiftTime
+}
+
+// Add sums up the statistics of two sets of lines that are plotted one after the other
+func (s Statistics) Add(other Statistics) Statistics {
+	return Statistics{
+		Curves:     s.Curves + other.Curves,
+		Chunks:     s.Chunks + other.Chunks,
+		Lifts:      s.Lifts + other.Lifts,
+		Down:       s.Down + other.Down,
+		Up:         s.Up + other.Up,
+		DrawTime:   s.DrawTime + other.DrawTime,
+		TravelTime: s.TravelTime + other.TravelTime,
+		LiftTime:   s.LiftTime + other.LiftTime,
+	}
+}
+
+func (s Statistics) String() string {
+	return fmt.Sprintf("%d curves of %d chunks, down distance %.1fm, up distance %.1fm, total %.1fm traveled\n"+
+		"Would take about %s to plot: %s drawing, %s traveling, %s lifting the pen %d times",
+		s.Curves, s.Chunks, s.Down, s.Up, s.Down+s.Up,
+		timeToMinSec(s.Time()), timeToMinSec(s.DrawTime), timeToMinSec(s.TravelTime), timeToMinSec(s.LiftTime), s.Lifts)
+}
+
+// MarshalJSON writes the times in seconds
+func (s Statistics) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Curves          int     `json:"curves"`
+		Chunks          int     `json:"chunks"`
+		Lifts           int     `json:"lifts"`
+		Down            float64 `json:"down_meters"`
+		Up              float64 `json:"up_meters"`
+		DrawSeconds     float64 `json:"draw_seconds"`
+		TravelSeconds   float64 `json:"travel_seconds"`
+		LiftSeconds     float64 `json:"lift_seconds"`
+		EstimateSeconds float64 `json:"estimate_seconds"`
+	}{
+		Curves:          s.Curves,
+		Chunks:          s.Chunks,
+		Lifts:           s.Lifts,
+		Down:            s.Down,
+		Up:              s.Up,
+		DrawSeconds:     s.DrawTime.Seconds(),
+		TravelSeconds:   s.TravelTime.Seconds(),
+		LiftSeconds:     s.LiftTime.Seconds(),
+		EstimateSeconds: s.Time().Seconds(),
+	})
+}
+
+// LayerStatistics are the statistics of one layer
+type LayerStatistics struct {
+	Name       string     `json:"name"`
+	Statistics Statistics `json:"statistics"`
+}
+
+// PageStatistics are the statistics of the layers of a page, in the order in which they're plotted
+type PageStatistics struct {
+	Guides bool              `json:"guides"`
+	Layers []LayerStatistics `json:"layers"`
+}
+
+// Total sums up the statistics of the layers of the page
+func (p PageStatistics) Total() Statistics {
+	total := Statistics{}
+	for _, layer := range p.Layers {
+		total = total.Add(layer.Statistics)
+	}
+	return total
+}
+
+func (p PageStatistics) String() string {
+	yesGuides := "without"
+	if p.Guides {
+		yesGuides = "with"
+	}
+	s := []string{fmt.Sprintf("Page has %d layers, %s guides", len(p.Layers), yesGuides)}
+	for i, layer := range p.Layers {
+		s = append(s, fmt.Sprintf("layer '%s' #%d has %s", layer.Name, i, layer.Statistics))
+	}
+	return strings.Join(s, "\n")
+}
+
+// MarshalJSON adds the total of the page
+func (p PageStatistics) MarshalJSON() ([]byte, error) {
+	type page PageStatistics // without the MarshalJSON method
+	return json.Marshal(struct {
+		page
+		Total Statistics `json:"total"`
+	}{page(p), p.Total()})
+}
+
+// DocumentStatistics are the statistics of the pages of a document
+type DocumentStatistics struct {
+	Pages []PageStatistics `json:"pages"`
+}
+
+// Total sums up the statistics of all the pages of the document
+func (d DocumentStatistics) Total() Statistics {
+	total := Statistics{}
+	for _, page := range d.Pages {
+		total = total.Add(page.Total())
+	}
+	return total
+}
+
+func (d DocumentStatistics) String() string {
+	s := []string{fmt.Sprintf("Document contains %d pages", len(d.Pages))}
+	for i, page := range d.Pages {
+		s = append(s, fmt.Sprintf("Page #%d:", i), page.String())
+	}
+	s = append(s, fmt.Sprintf("Document would take about %s to plot", timeToMinSec(d.Total().Time())))
+	return strings.Join(s, "\n")
+}
+
+// MarshalJSON adds the total of the document
+func (d DocumentStatistics) MarshalJSON() ([]byte, error) {
+	type document DocumentStatistics // without the MarshalJSON method
+	return json.Marshal(struct {
+		document
+		Total Statistics `json:"total"`
+	}{document(d), d.Total()})
+}
+
+// Statistics sums up what it takes to plot the layer, from the origin and back. Each line is flattened into a
+// polyline whose velocity is planned with the drawing limits of the model, so that sharp corners take longer
+// than gentle curves, and each pen-up move is planned with its travel limits.
+func (l Layer) Statistics(model motion.Model) LayerStatistics {
+	s := Statistics{}
+	s.Down, s.Up = l.Distances()
+	tolerance := model.Tolerance * units.Millimeter
+	for _, line := range l.linelikes {
+		if line == nil {
+			continue
+		}
+		s.Curves++
+		s.Chunks += len(lines.ToPath(line).Chunks())
+		points := lines.Flatten(line, tolerance)
+		for i, point := range points {
+			points[i] = toMillimeters(point)
+		}
+		s.DrawTime += model.DrawTime(points)
+	}
+	for _, travel := range l.TravelLines() {
+		s.TravelTime += model.TravelTime(toMillimeters(travel.P1), toMillimeters(travel.P2))
+	}
+	s.Lifts = s.Curves
+	s.LiftTime = model.LiftTime() * time.Duration(s.Lifts)
+	return LayerStatistics{Name: l.name, Statistics: s}
+}
+
+// Statistics sums up what it takes to plot each layer of the page
+func (p Page) Statistics(model motion.Model) PageStatistics {
+	stats := PageStatistics{Guides: p.guides}
+	for _, layer := range p.layers {
+		stats.Layers = append(stats.Layers, layer.Statistics(model))
+	}
+	return stats
+}
+
+// Statistics sums up what it takes to plot each page of the document
+func (d Document) Statistics(model motion.Model) DocumentStatistics {
+	stats := DocumentStatistics{}
+	for _, page := range d.pages {
+		stats.Pages = append(stats.Pages, page.Statistics(model))
+	}
+	return stats
+}
+
+func toMillimeters(p primitives.Point) primitives.Point {
+	return primitives.Point{X: p.X / units.Millimeter, Y: p.Y / units.Millimeter}
+}
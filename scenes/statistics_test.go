@@ -0,0 +1,98 @@
+package scenes
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/motion"
+	"github.com/libeks/go-plotter-svg/primitives"
+	"github.com/libeks/go-plotter-svg/units"
+)
+
+// mm returns the point at the given coordinates in millimeters
+func mm(x, y float64) primitives.Point {
+	return primitives.Point{X: x * units.Millimeter, Y: y * units.Millimeter}
+}
+
+func polylinePath(points ...primitives.Point) lines.Path {
+	path := lines.NewPath(points[0])
+	for i := 1; i < len(points); i++ {
+		path = path.AddPathChunk(lines.LineChunk{Start: points[i-1], End: points[i]})
+	}
+	return path
+}
+
+func TestLayerStatistics(t *testing.T) {
+	// without acceleration limits, the pen moves at full speed throughout
+	model := motion.Model{
+		Drawing:      motion.Limits{Speed: 10},
+		Travel:       motion.Limits{Speed: 100},
+		PenUpDelay:   time.Second,
+		PenDownDelay: time.Second,
+		Tolerance:    0.05,
+	}
+	layer := NewLayer("a").WithLineLike([]lines.LineLike{
+		lines.LineSegment{P1: mm(10, 0), P2: mm(110, 0)},
+		polylinePath(mm(110, 0), mm(110, 50), mm(110, 100)),
+	})
+	got := layer.Statistics(model)
+	if got.Name != "a" {
+		t.Errorf("Got name %q, want %q", got.Name, "a")
+	}
+	s := got.Statistics
+	if diff := cmp.Diff([]int{2, 3, 2}, []int{s.Curves, s.Chunks, s.Lifts}); diff != "" {
+		t.Errorf("Unexpected diff in curves, chunks and lifts %v", diff)
+	}
+	// from the origin to the first line, and from the end of the second line back to the origin
+	travel := 10 + math.Hypot(110, 100)
+	durations := []struct {
+		name      string
+		got, want time.Duration
+	}{
+		{"drawing", s.DrawTime, 20 * time.Second},
+		{"traveling", s.TravelTime, time.Duration(travel / 100 * float64(time.Second))},
+		{"lifting", s.LiftTime, 4 * time.Second},
+	}
+	for _, d := range durations {
+		if (d.got - d.want).Abs() > time.Microsecond {
+			t.Errorf("Got %s time %s, want %s", d.name, d.got, d.want)
+		}
+	}
+	if math.Abs(s.Down-0.2) > 1e-9 || math.Abs(s.Up-travel/1000) > 1e-9 {
+		t.Errorf("Got distances %v and %v, want %v and %v", s.Down, s.Up, 0.2, travel/1000)
+	}
+	if s.Time() != s.DrawTime+s.TravelTime+s.LiftTime {
+		t.Errorf("Time %s isn't the sum of its parts", s.Time())
+	}
+}
+
+func TestLayerStatisticsCorners(t *testing.T) {
+	model := motion.DefaultModel()
+	draw := func(line lines.LineLike) time.Duration {
+		return NewLayer("a").WithLineLike([]lines.LineLike{line}).Statistics(model).Statistics.DrawTime
+	}
+	straight := draw(polylinePath(mm(0, 0), mm(50, 0), mm(100, 0)))
+	bent := draw(polylinePath(mm(0, 0), mm(50, 0), mm(50, 50)))
+	reversed := draw(polylinePath(mm(0, 0), mm(50, 0), mm(0, 0)))
+	if !(straight < bent && bent < reversed) {
+		t.Errorf("Sharper corners should take longer to draw, got %s straight, %s bent and %s reversed", straight, bent, reversed)
+	}
+}
+
+func TestDocumentStatisticsTotal(t *testing.T) {
+	line := []lines.LineLike{lines.LineSegment{P1: mm(0, 0), P2: mm(100, 0)}}
+	page := Page{}.AddLayer(NewLayer("a").WithLineLike(line)).AddLayer(NewLayer("b").WithLineLike(line))
+	stats := Document{}.AddPage(page).AddPage(page).Statistics(motion.DefaultModel())
+	layer := stats.Pages[0].Layers[0].Statistics
+	total := stats.Total()
+	if diff := cmp.Diff([]int{4, 4, 4}, []int{total.Curves, total.Chunks, total.Lifts}); diff != "" {
+		t.Errorf("Unexpected diff in curves, chunks and lifts %v", diff)
+	}
+	if total.Time() != 4*layer.Time() {
+		t.Errorf("Got total time %s, want 4 times %s", total.Time(), layer.Time())
+	}
+}
@@ -0,0 +1,230 @@
+package scenes
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/libeks/go-plotter-svg/fonts"
+	"github.com/libeks/go-plotter-svg/primitives"
+)
+
+// SeedAxis is the name of an Axis that varies the seed instead of a parameter
+const SeedAxis = "seed"
+
+const (
+	sweepPadding    = 0.03 // space around each variant, as a fraction of the smaller side of its cell
+	sweepLabelBand  = 0.08 // height of the label under each variant, as a fraction of the height of its cell
+	sweepLabelLayer = "labels"
+)
+
+// Axis is a parameter that a Sweep varies, along with the values it takes, as they would be given to -set
+type Axis struct {
+	Param  string
+	Values []string
+}
+
+// ParseAxis parses an axis like "n=4,8,16", or "spacing=10:40:4" for 4 values evenly spaced from 10 to 40
+func ParseAxis(s string) (Axis, error) {
+	name, value, err := SplitSetting(s)
+	if err != nil {
+		return Axis{}, err
+	}
+	if from, rest, ok := strings.Cut(value, ":"); ok {
+		to, steps, ok := strings.Cut(rest, ":")
+		if !ok {
+			return Axis{}, fmt.Errorf("expected a range like min:max:steps, got '%s'", value)
+		}
+		min, errMin := strconv.ParseFloat(strings.TrimSpace(from), 64)
+		max, errMax := strconv.ParseFloat(strings.TrimSpace(to), 64)
+		n, errN := strconv.Atoi(strings.TrimSpace(steps))
+		if errMin != nil || errMax != nil || errN != nil || n < 1 {
+			return Axis{}, fmt.Errorf("expected a range like min:max:steps, got '%s'", value)
+		}
+		axis := Axis{Param: name}
+		for i := range n {
+			v := min
+			if n > 1 {
+				v = min + (max-min)*float64(i)/float64(n-1)
+			}
+			// round away the noise of the division, so that 0.1:0.3:3 gives 0.2 rather than 0.20000000000000004
+			v = math.Round(v*1e9) / 1e9
+			axis.Values = append(axis.Values, strconv.FormatFloat(v, 'f', -1, 64))
+		}
+		return axis, nil
+	}
+	axis := Axis{Param: name}
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			axis.Values = append(axis.Values, v)
+		}
+	}
+	if len(axis.Values) == 0 {
+		return Axis{}, fmt.Errorf("axis '%s' has no values", name)
+	}
+	return axis, nil
+}
+
+// Sweep renders variants of a scene next to each other on a contact sheet, with the values of X changing from
+// column to column, and those of Y from row to row
+type Sweep struct {
+	Scene  Scene
+	Values map[string]any // parameters that all variants share, the axes override them
+	Seed   int64          // seed of all variants, unless an axis varies it
+	X      Axis
+	Y      Axis // optional, there's a single row without it
+}
+
+// Variant is a single cell of a Sweep
+type Variant struct {
+	Row, Column int
+	Label       string // the values of the axes, e.g. "n=8 seed=3"
+	Params      Params
+	Seed        int64
+}
+
+// Variants checks the values of the axes, and returns the variants row by row
+func (s Sweep) Variants() ([]Variant, error) {
+	if len(s.X.Values) == 0 {
+		return nil, fmt.Errorf("the sweep needs at least one value to vary")
+	}
+	if s.X.Param == s.Y.Param {
+		return nil, fmt.Errorf("both axes vary '%s'", s.X.Param)
+	}
+	if !s.Scene.Random && (s.X.Param == SeedAxis || s.Y.Param == SeedAxis) {
+		return nil, fmt.Errorf("scene '%s' doesn't use random numbers, so all seeds look the same", s.Scene.Name)
+	}
+	ys := s.Y.Values
+	if len(ys) == 0 {
+		ys = []string{""} // a single row, which doesn't override anything
+	}
+	variants := []Variant{}
+	for row, y := range ys {
+		for column, x := range s.X.Values {
+			values := map[string]any{}
+			for name, v := range s.Values {
+				values[name] = v
+			}
+			seed := s.Seed
+			labels := []string{}
+			for _, setting := range []struct {
+				param, value string
+			}{{s.X.Param, x}, {s.Y.Param, y}} {
+				if setting.param == "" {
+					continue
+				}
+				labels = append(labels, setting.param+"="+setting.value)
+				if setting.param != SeedAxis {
+					values[setting.param] = setting.value
+					continue
+				}
+				var err error
+				if seed, err = strconv.ParseInt(strings.TrimSpace(setting.value), 10, 64); err != nil {
+					return nil, fmt.Errorf("seed must be an integer, got '%s'", setting.value)
+				}
+			}
+			params, err := s.Scene.WithValues(values)
+			if err != nil {
+				return nil, err
+			}
+			variants = append(variants, Variant{Row: row, Column: column, Label: strings.Join(labels, " "), Params: params, Seed: seed})
+		}
+	}
+	return variants, nil
+}
+
+// Render renders each variant for the box b, and scales it into its cell of b, leaving room for its label.
+// Layers with the same name are merged across variants, keeping the color, width and pen of the first one,
+// and the labels are drawn on the first page in a layer of their own.
+func (s Sweep) Render(b primitives.BBox) (Document, error) {
+	variants, err := s.Variants()
+	if err != nil {
+		return Document{}, err
+	}
+	rows := max(len(s.Y.Values), 1)
+	cells := primitives.PartitionIntoRectangles(b, len(s.X.Values), rows).BoxIterator()
+	sheet := sheet{}
+	labels := NewLayer(sweepLabelLayer).WithNoGuide()
+	for i, variant := range variants {
+		cell := cells[i].BBox // cells are in the same row by row order as the variants
+		doc, err := s.renderVariant(b, variant)
+		if err != nil {
+			return Document{}, err
+		}
+		band := cell.Height() * sweepLabelBand
+		content := primitives.BBox{
+			UpperLeft:  cell.UpperLeft,
+			LowerRight: cell.LowerRight.Add(primitives.Vector{Y: -band}),
+		}.WithPadding(min(cell.Width(), cell.Height()) * sweepPadding)
+		t := fitInto(b, content)
+		sheet.add(doc, t)
+
+		// the label is centered right under the variant, which may be narrower or shorter than its cell
+		height := band * 0.6
+		width := fonts.StrokeTextWidth(variant.Label, height)
+		if width > content.Width() {
+			height *= content.Width() / width
+			width = content.Width()
+		}
+		bottom := t.Apply(b.LowerRight).Y
+		corner := primitives.Point{X: content.Center().X - width/2, Y: bottom + (band-height)/2}
+		labels = labels.WithLineLike(fonts.StrokeText(variant.Label, corner, height))
+	}
+	return sheet.document(labels), nil
+}
+
+// renderVariant turns a panic of the scene into an error that names the variant, since the reason to sweep
+// a parameter is often to find out which of its values break the scene
+func (s Sweep) renderVariant(b primitives.BBox, variant Variant) (doc Document, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("scene '%s' panicked for %s: %v", s.Scene.Name, variant.Label, r)
+		}
+	}()
+	return s.Scene.Render(b, variant.Params, variant.Seed), nil
+}
+
+// fitInto scales the box from uniformly, so that it's centered in the box to
+func fitInto(from, to primitives.BBox) primitives.Transform {
+	scale := min(to.Width()/from.Width(), to.Height()/from.Height())
+	return primitives.Translation(primitives.Vector(to.Center())).
+		Multiply(primitives.Scaling(scale, scale)).
+		Multiply(primitives.Translation(primitives.Origin.Subtract(from.Center())))
+}
+
+// sheet collects the variants of a sweep, merging their layers by name, page by page
+type sheet struct {
+	pages []Page
+	index []map[string]int // the index of each layer of each page, by name
+}
+
+func (s *sheet) add(doc Document, t primitives.Transform) {
+	for i, page := range doc.pages {
+		if i == len(s.pages) {
+			// foldable patterns are left behind, since they can't be told apart once the variants are merged
+			s.pages = append(s.pages, Page{guides: page.guides})
+			s.index = append(s.index, map[string]int{})
+		}
+		for _, layer := range page.layers {
+			layer = layer.Transform(t)
+			j, ok := s.index[i][layer.name]
+			if !ok {
+				s.index[i][layer.name] = len(s.pages[i].layers)
+				s.pages[i] = s.pages[i].AddLayer(layer)
+				continue
+			}
+			merged := &s.pages[i].layers[j]
+			merged.linelikes = append(merged.linelikes, layer.linelikes...)
+			merged.controllines = append(merged.controllines, layer.controllines...)
+		}
+	}
+}
+
+func (s *sheet) document(labels Layer) Document {
+	doc := Document{}
+	for _, page := range s.pages {
+		doc = doc.AddPage(page)
+	}
+	return doc.AddLayer(labels)
+}
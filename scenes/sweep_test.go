@@ -0,0 +1,167 @@
+package scenes
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/primitives"
+)
+
+func TestParseAxis(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    Axis
+		wantErr bool
+	}{
+		{
+			name: "list",
+			s:    "tiles=4-crossing, 4-non-crossing",
+			want: Axis{Param: "tiles", Values: []string{"4-crossing", "4-non-crossing"}},
+		},
+		{
+			name: "range",
+			s:    "spacing=10:40:4",
+			want: Axis{Param: "spacing", Values: []string{"10", "20", "30", "40"}},
+		},
+		{
+			name: "range without rounding noise",
+			s:    "ratio=0.1:0.3:3",
+			want: Axis{Param: "ratio", Values: []string{"0.1", "0.2", "0.3"}},
+		},
+		{
+			name: "range of a single value",
+			s:    "n=5:10:1",
+			want: Axis{Param: "n", Values: []string{"5"}},
+		},
+		{
+			name:    "range without steps",
+			s:       "n=5:10",
+			wantErr: true,
+		},
+		{
+			name:    "no values",
+			s:       "seed=,",
+			wantErr: true,
+		},
+		{
+			name:    "no name",
+			s:       "1,2,3",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAxis(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAxis(%s) returned error %v, want error %v", tt.s, err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Unexpected diff %v", diff)
+			}
+		})
+	}
+}
+
+func TestSweepRender(t *testing.T) {
+	// the scene draws a horizontal line across the box, in a layer per color
+	library := SceneLibrary()
+	err := library.AddRandom("line", []Param{
+		FloatP("height", 0.5, 0, 1, "where the line is, as a fraction of the box"),
+		StringP("color", "red", []string{"red", "blue"}, "color of the line"),
+	}, func(b primitives.BBox, p Params, r *rand.Rand) Document {
+		y := b.UpperLeft.Y + b.Height()*p.Float("height")
+		line := lines.LineSegment{P1: primitives.Point{X: b.UpperLeft.X, Y: y}, P2: primitives.Point{X: b.LowerRight.X, Y: y}}
+		return Document{}.AddLayer(NewLayer(p.String("color")).WithLineLike([]lines.LineLike{line}).WithColor(p.String("color")))
+	})
+	if err != nil {
+		t.Fatalf("AddRandom() returned error %v", err)
+	}
+	scene, _ := library.GetScene("line")
+	sweep := Sweep{
+		Scene: scene,
+		X:     Axis{Param: "height", Values: []string{"0", "1"}},
+		Y:     Axis{Param: "color", Values: []string{"red", "blue"}},
+	}
+	doc, err := sweep.Render(primitives.BBox{LowerRight: primitives.Point{X: 1000, Y: 1000}})
+	if err != nil {
+		t.Fatalf("Render() returned error %v", err)
+	}
+	round := func(p primitives.Point) primitives.Point {
+		return primitives.Point{X: math.Round(p.X*1000) / 1000, Y: math.Round(p.Y*1000) / 1000}
+	}
+	got := map[string][]lines.LineSegment{}
+	names := []string{}
+	for _, layer := range doc.Page(0).GetLayers() {
+		names = append(names, layer.Name())
+		if layer.Name() == sweepLabelLayer {
+			continue
+		}
+		for _, line := range layer.LineLikes() {
+			got[layer.Name()] = append(got[layer.Name()], lines.LineSegment{P1: round(line.Start()), P2: round(line.End())})
+		}
+	}
+	// each cell is 500x500, and its variant is scaled by 0.43 to fit above the label, 15 away from the edges
+	want := map[string][]lines.LineSegment{
+		"red": {
+			{P1: primitives.Point{X: 35, Y: 15}, P2: primitives.Point{X: 465, Y: 15}},
+			{P1: primitives.Point{X: 535, Y: 445}, P2: primitives.Point{X: 965, Y: 445}},
+		},
+		"blue": {
+			{P1: primitives.Point{X: 35, Y: 515}, P2: primitives.Point{X: 465, Y: 515}},
+			{P1: primitives.Point{X: 535, Y: 945}, P2: primitives.Point{X: 965, Y: 945}},
+		},
+	}
+	if diff := cmp.Diff([]string{"red", "blue", sweepLabelLayer}, names); diff != "" {
+		t.Errorf("Unexpected diff in the layers %v", diff)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected diff %v", diff)
+	}
+}
+
+func TestSweepVariantsErrors(t *testing.T) {
+	library := GatherScenes()
+	truchet, _ := library.GetScene("truchet")
+	circles, _ := library.GetScene("circles-in-square")
+	tests := []struct {
+		name  string
+		sweep Sweep
+	}{
+		{
+			name:  "no values",
+			sweep: Sweep{Scene: truchet, X: Axis{Param: "n"}},
+		},
+		{
+			name:  "same parameter twice",
+			sweep: Sweep{Scene: truchet, X: Axis{Param: "n", Values: []string{"2"}}, Y: Axis{Param: "n", Values: []string{"3"}}},
+		},
+		{
+			name:  "unknown parameter",
+			sweep: Sweep{Scene: truchet, X: Axis{Param: "size", Values: []string{"2"}}},
+		},
+		{
+			name:  "bad value",
+			sweep: Sweep{Scene: truchet, X: Axis{Param: "n", Values: []string{"2.5"}}},
+		},
+		{
+			name:  "bad seed",
+			sweep: Sweep{Scene: truchet, X: Axis{Param: SeedAxis, Values: []string{"one"}}},
+		},
+		{
+			name:  "seed of a scene that isn't random",
+			sweep: Sweep{Scene: circles, X: Axis{Param: SeedAxis, Values: []string{"1", "2"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.sweep.Variants(); err == nil {
+				t.Errorf("Variants() returned no error")
+			}
+		})
+	}
+}
@@ -2,17 +2,44 @@ package scenes
 
 import (
 	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/libeks/go-plotter-svg/foldable"
 	"github.com/libeks/go-plotter-svg/lines"
 	"github.com/libeks/go-plotter-svg/pack"
 	"github.com/libeks/go-plotter-svg/primitives"
+	"github.com/libeks/go-plotter-svg/route"
+	"github.com/libeks/go-plotter-svg/units"
 )
 
 type Document struct {
 	pages  []Page
 	guides bool
+	source *Source
+}
+
+// Source records how a document was generated, so that it can be generated again
+type Source struct {
+	Scene      string      `json:"scene"`
+	Seed       int64       `json:"seed"`
+	Params     Params      `json:"params,omitempty"`
+	Processing *Processing `json:"processing,omitempty"` // how the lines were cleaned up after the scene was rendered
+}
+
+// Processing says how Document.Process cleans up the lines of a document, leaving out the steps whose fields are
+// zero
+type Processing struct {
+	RemoveOverlaps float64              `json:"remove_overlaps,omitempty"` // see Layer.RemoveOverlaps
+	Chain          float64              `json:"chain,omitempty"`           // see Layer.Chain
+	Simplify       lines.Simplification `json:"simplify,omitzero"`         // see Layer.Simplify
+	Optimize       bool                 `json:"optimize,omitempty"`        // see Layer.Optimize
+	OptimizeBudget time.Duration        `json:"optimize_budget,omitempty"` // how long Optimize may spend on each layer
 }
 
 func (d Document) WithGuides() Document {
@@ -20,10 +47,38 @@ func (d Document) WithGuides() Document {
 	return d
 }
 
+func (d Document) WithSource(source Source) Document {
+	d.source = &source
+	return d
+}
+
+// Source returns how the document was generated, if it came from a scene of the library
+func (d Document) Source() (Source, bool) {
+	if d.source == nil {
+		return Source{}, false
+	}
+	return *d.source, true
+}
+
 func (d Document) NumPages() int {
 	return len(d.pages)
 }
 
+// PageFileNames returns the file that each page of the document is written to. A single page goes to fname, and
+// otherwise the page number is added before the extension, so that "art.svg" becomes "art_0.svg", "art_1.svg", ...
+func (d Document) PageFileNames(fname string) []string {
+	if len(d.pages) == 1 {
+		return []string{fname}
+	}
+	extension := filepath.Ext(fname)
+	basename := strings.TrimSuffix(fname, extension)
+	names := make([]string, len(d.pages))
+	for i := range d.pages {
+		names[i] = fmt.Sprintf("%s_%d%s", basename, i, extension)
+	}
+	return names
+}
+
 // Adds a layer to the first page of the document, this is here for backwards compatibility
 func (d Document) AddLayer(layer Layer) Document {
 	if len(d.pages) == 0 {
@@ -41,12 +96,91 @@ func (d Document) AddPage(p Page) Document {
 	return d
 }
 
-func (d Document) CalculateStatistics() {
-	fmt.Printf("Document contains %d pages\n", len(d.pages))
+// MinimizePaths reorders the lines of every layer to cut down on pen-up travel, see Layer.MinimizePath
+func (d Document) MinimizePaths(allowReverse bool) Document {
+	return d.Optimize(route.Options{Reverse: allowReverse}, os.Stdout)
+}
+
+// Optimize reorders the lines of every layer to cut down on pen-up travel, and writes how much each layer saved
+// to w
+func (d Document) Optimize(opts route.Options, w io.Writer) Document {
+	return d.mapLayers(w, func(l Layer) (Layer, string) {
+		l, result := l.Optimize(opts)
+		return l, l.TravelReport(result)
+	})
+}
+
+// RemoveOverlaps takes out the retraced parts of lines in every layer, and writes how much each layer lost to w
+func (d Document) RemoveOverlaps(tolerance float64, w io.Writer) Document {
+	return d.mapLayers(w, func(l Layer) (Layer, string) {
+		l, result := l.RemoveOverlaps(tolerance)
+		return l, l.OverlapReport(result)
+	})
+}
+
+// Simplify cuts down on the chunks of the paths in every layer, and writes how many chunks each layer lost to w
+func (d Document) Simplify(s lines.Simplification, w io.Writer) Document {
+	return d.mapLayers(w, func(l Layer) (Layer, string) {
+		l, result := l.Simplify(s)
+		return l, l.SimplifyReport(result)
+	})
+}
+
+// Chain joins touching lines into continuous paths in every layer, and writes how many lines each layer lost to w
+func (d Document) Chain(tolerance float64, w io.Writer) Document {
+	return d.mapLayers(w, func(l Layer) (Layer, string) {
+		l, result := l.Chain(tolerance)
+		return l, l.ChainReport(result)
+	})
+}
+
+// Process removes overlaps, chains, simplifies and reorders the lines of every layer, as p asks for and in that
+// order, so that chaining joins the pieces that removing overlaps leaves behind, and simplifying sees the whole of
+// the joined paths. It writes the report of each step to w, and records p in the Source of the document.
+func (d Document) Process(p Processing, w io.Writer) Document {
+	if !p.Optimize {
+		p.OptimizeBudget = 0
+	}
+	if p == (Processing{}) {
+		return d
+	}
+	if p.RemoveOverlaps > 0 {
+		d = d.RemoveOverlaps(p.RemoveOverlaps, w)
+	}
+	if p.Chain > 0 {
+		d = d.Chain(p.Chain, w)
+	}
+	if p.Simplify != (lines.Simplification{}) {
+		d = d.Simplify(p.Simplify, w)
+	}
+	if p.Optimize {
+		// reversing lines and moving the start of closed curves doesn't change how the drawing looks
+		d = d.Optimize(route.Options{Reverse: true, RotateClosed: true, Budget: p.OptimizeBudget}, w)
+	}
+	if d.source != nil {
+		source := *d.source
+		source.Processing = &p
+		d.source = &source
+	}
+	return d
+}
+
+// mapLayers replaces every layer of every page with what f makes of it, and writes the report that f gives for
+// each layer to w
+func (d Document) mapLayers(w io.Writer, f func(Layer) (Layer, string)) Document {
+	pages := make([]Page, len(d.pages))
 	for i, page := range d.pages {
-		fmt.Printf("Page #%d:\n", i)
-		page.CalculateStatistics()
+		layers := make([]Layer, len(page.layers))
+		for j, layer := range page.layers {
+			var report string
+			layers[j], report = f(layer)
+			fmt.Fprintln(w, report)
+		}
+		page.layers = layers
+		pages[i] = page
 	}
+	d.pages = pages
+	return d
 }
 
 func (d Document) Page(i int) Page {
@@ -66,17 +200,18 @@ func FromFoldableLayers(shapes []foldable.FoldablePattern, container primitives.
 	}
 	boxPacking := pack.PackOnMultiplePages(bboxes, container, 200)
 	shapesByPage := map[int][]foldable.FoldablePattern{}
-	for i, v := range boxPacking.Translations {
+	for i := range shapes {
+		v := boxPacking.Translations[i]
 		shapesByPage[v.Page] = append(shapesByPage[v.Page], shapes[i].Translate(primitives.Origin.Subtract(shapes[i].BBox().UpperLeft).Add(v.Vector)))
 	}
 	for i := range boxPacking.Pages {
-		page := Page{}.WithGuides()
+		objects := shapesByPage[i]
+		page := Page{}.WithGuides().WithFoldablePatterns(objects)
 		polygons := []lines.LineLike{}
 		edges := []lines.LineLike{}
 		annotations := []lines.LineLike{}
 		bboxLines := []lines.LineLike{}
 		fillColors := map[string]foldable.BrushLines{}
-		objects := shapesByPage[i]
 		for _, p := range objects {
 			for _, poly := range p.Polygons {
 				polygons = append(polygons, lines.SegmentsToLineLikes(poly.EdgeLines())...)
@@ -102,10 +237,11 @@ func FromFoldableLayers(shapes []foldable.FoldablePattern, container primitives.
 		page = page.AddLayer(NewLayer("polygons").WithLineLike(polygons).WithColor("red").WithWidth(20).WithNoGuide())
 		page = page.AddLayer(NewLayer("annotations").WithLineLike(annotations).WithColor("green").WithWidth(20).WithNoGuide())
 		page = page.AddLayer(NewLayer("bboxes").WithLineLike(bboxLines).WithColor("blue").WithWidth(20).WithNoGuide())
-		for color, infill := range fillColors {
+		for _, color := range slices.Sorted(maps.Keys(fillColors)) {
+			infill := fillColors[color]
 			layerName := fmt.Sprintf("FILL-%s", color)
 			pen := infill.Pen
-			page = page.AddLayer(NewLayer(layerName).WithLineLike(infill.Lines).WithOffset(pen.XOffset, pen.YOffset).WithColor(infill.Color).WithWidth(pen.Spacing))
+			page = page.AddLayer(NewLayer(layerName).WithLineLike(infill.Lines).WithPen(pen).WithColor(infill.Color).WithWidth(pen.Spacing))
 		}
 		doc = doc.AddPage(page)
 	}
@@ -113,8 +249,21 @@ func FromFoldableLayers(shapes []foldable.FoldablePattern, container primitives.
 }
 
 type Page struct {
-	layers []Layer
-	guides bool
+	layers   []Layer
+	guides   bool
+	patterns []foldable.FoldablePattern
+}
+
+// WithFoldablePatterns records the foldable patterns that the page was drawn from, so that writers can tell
+// cut lines from fold lines
+func (s Page) WithFoldablePatterns(patterns []foldable.FoldablePattern) Page {
+	s.patterns = patterns
+	return s
+}
+
+// FoldablePatterns returns the patterns set with WithFoldablePatterns, already placed on the page
+func (s Page) FoldablePatterns() []foldable.FoldablePattern {
+	return s.patterns
 }
 
 func (s Page) AddLayer(layer Layer) Page {
@@ -227,35 +376,18 @@ func (s Page) GetLayers() []Layer {
 				P1: primitives.Point{X: 300, Y: 500},
 				P2: primitives.Point{X: 700, Y: 500},
 			}.Translate(offset),
-		}).WithColor(layer.color).WithWidth(layer.width).WithOffset(layer.offsetX, layer.offsetY))
+		}).WithColor(layer.color).WithWidth(layer.width).WithPen(layer.pen).WithOffset(layer.offsetX, layer.offsetY))
 		outlineOffset += 1
 	}
 	return newLayers
 }
 
-func (s Page) CalculateStatistics() {
-	yesGuides := "without"
-	if s.guides {
-		yesGuides = "with"
-	}
-
-	fmt.Printf("Page has %d layers, %s guides\n", len(s.layers), yesGuides)
-	for i, layer := range s.layers {
-		fmt.Printf("layer '%s' #%d has %s\n", layer.name, i, layer.Statistics())
-	}
-}
-
 func timeToMinSec(d time.Duration) string {
 	minutes := int(d / time.Minute)
 	seconds := int((d - time.Duration(float64(minutes)*float64(time.Minute))) / time.Second)
 	return fmt.Sprintf("%dm%ds", minutes, seconds)
 }
 
-func metersToTime(m float64) time.Duration {
-	return time.Duration(22.6 * float64(time.Second) * m)
-}
-
 func imageSpaceToMeters(l float64) float64 {
-	const unitPerMeter = 44092.0
-	return l / unitPerMeter
+	return l / units.Meter
 }
@@ -0,0 +1,46 @@
+package scenes
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPageFileNames(t *testing.T) {
+	tests := []struct {
+		name  string
+		pages int
+		fname string
+		want  []string
+	}{
+		{
+			name:  "single page",
+			pages: 1,
+			fname: "build/art.svg",
+			want:  []string{"build/art.svg"},
+		},
+		{
+			name:  "relative path",
+			pages: 2,
+			fname: "build/art.svg",
+			want:  []string{"build/art_0.svg", "build/art_1.svg"},
+		},
+		{
+			name:  "absolute path",
+			pages: 2,
+			fname: "/tmp/art.gcode",
+			want:  []string{"/tmp/art_0.gcode", "/tmp/art_1.gcode"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := Document{}
+			for range tt.pages {
+				doc = doc.AddPage(Page{})
+			}
+			if diff := cmp.Diff(tt.want, doc.PageFileNames(tt.fname)); diff != "" {
+				t.Errorf("Unexpected diff %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package server
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/motion"
+	"github.com/libeks/go-plotter-svg/paper"
+	"github.com/libeks/go-plotter-svg/scenes"
+	"github.com/libeks/go-plotter-svg/svg"
+)
+
+// the page is embedded, so that the server works without network access
+//
+//go:embed index.html
+var indexHTML []byte
+
+// Server hosts a page that lists the scenes, and renders them again on every request
+type Server struct {
+	Scenes []scenes.Scene
+	mu     sync.Mutex // scenes are rendered one at a time
+}
+
+// New serves the scenes, which are usually those of GatherScenes
+func New(library []scenes.Scene) *Server {
+	return &Server{Scenes: library}
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.index)
+	mux.HandleFunc("GET /api/scenes", s.listScenes)
+	mux.HandleFunc("POST /api/render", s.render)
+	return mux
+}
+
+func (s *Server) index(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(indexHTML)
+}
+
+type paramJSON struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Default     any      `json:"default"`
+	Min         float64  `json:"min,omitempty"`
+	Max         float64  `json:"max,omitempty"`
+	Choices     []string `json:"choices,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+type sceneJSON struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Tags        []string    `json:"tags,omitempty"`
+	Expensive   bool        `json:"expensive,omitempty"`
+	Random      bool        `json:"random"`
+	Params      []paramJSON `json:"params"`
+}
+
+type libraryJSON struct {
+	Scenes []sceneJSON `json:"scenes"`
+	Papers []string    `json:"papers"`
+}
+
+func (s *Server) listScenes(w http.ResponseWriter, r *http.Request) {
+	ret := libraryJSON{Scenes: []sceneJSON{}}
+	for _, scene := range s.Scenes {
+		params := []paramJSON{}
+		for _, p := range scene.Params {
+			params = append(params, paramJSON{
+				Name:        p.Name,
+				Type:        p.Type.String(),
+				Default:     p.Default,
+				Min:         p.Min,
+				Max:         p.Max,
+				Choices:     p.Choices,
+				Description: p.Description,
+			})
+		}
+		ret.Scenes = append(ret.Scenes, sceneJSON{
+			Name:        scene.Name,
+			Description: scene.Description,
+			Tags:        scene.Tags,
+			Expensive:   scene.Expensive,
+			Random:      scene.Random,
+			Params:      params,
+		})
+	}
+	for _, size := range paper.Sizes {
+		ret.Papers = append(ret.Papers, size.Name)
+	}
+	writeJSON(w, ret)
+}
+
+type renderRequest struct {
+	Scene       string         `json:"scene"`
+	Seed        int64          `json:"seed"` // 0 picks a random seed
+	Params      map[string]any `json:"params"`
+	Paper       string         `json:"paper"`
+	Orientation string         `json:"orientation"`
+}
+
+type renderResponse struct {
+	Scene           string         `json:"scene"`
+	Seed            int64          `json:"seed"`
+	Params          scenes.Params  `json:"params"`
+	Pages           []pageResponse `json:"pages"`
+	Duration        string         `json:"duration"` // how long the scene took to render
+	Estimate        string         `json:"estimate"`
+	EstimateSeconds float64        `json:"estimate_seconds"`
+}
+
+type pageResponse struct {
+	SVG    string          `json:"svg"`
+	Layers []layerResponse `json:"layers"`
+}
+
+type layerResponse struct {
+	Name            string  `json:"name"`
+	Color           string  `json:"color"`
+	Group           int     `json:"group"`  // index of the <g> of the layer in the SVG
+	Travel          int     `json:"travel"` // index of the <g> of its pen-up travel
+	Curves          int     `json:"curves"`
+	Down            float64 `json:"down_meters"`
+	Up              float64 `json:"up_meters"`
+	Estimate        string  `json:"estimate"`
+	EstimateSeconds float64 `json:"estimate_seconds"`
+}
+
+func (s *Server) render(w http.ResponseWriter, r *http.Request) {
+	req := renderRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("reading request: %s", err), http.StatusBadRequest)
+		return
+	}
+	scene, params, layout, err := s.parse(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Seed == 0 {
+		req.Seed = rand.Int63()
+	}
+	start := time.Now()
+	resp, err := s.renderScene(scene, params, layout, req.Seed)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	resp.Scene, resp.Seed, resp.Params = scene.Name, req.Seed, params
+	resp.Duration = time.Since(start).Round(time.Millisecond).String()
+	writeJSON(w, resp)
+}
+
+func (s *Server) parse(req renderRequest) (scenes.Scene, scenes.Params, paper.Layout, error) {
+	i := slices.IndexFunc(s.Scenes, func(scene scenes.Scene) bool { return scene.Name == req.Scene })
+	if i < 0 {
+		return scenes.Scene{}, nil, paper.Layout{}, fmt.Errorf("unknown scene '%s'", req.Scene)
+	}
+	scene := s.Scenes[i]
+	params, err := scene.WithValues(req.Params)
+	if err != nil {
+		return scenes.Scene{}, nil, paper.Layout{}, err
+	}
+	layout := paper.Default()
+	if req.Paper != "" {
+		if layout.Size, err = paper.Lookup(req.Paper); err != nil {
+			return scenes.Scene{}, nil, paper.Layout{}, err
+		}
+		layout.Unit = layout.Size.Unit
+	}
+	if req.Orientation != "" {
+		if layout.Orientation, err = paper.ParseOrientation(req.Orientation); err != nil {
+			return scenes.Scene{}, nil, paper.Layout{}, err
+		}
+	}
+	return scene, params, layout, nil
+}
+
+// renderScene turns panics of the scene into errors, since plenty of geometry code panics on bad input
+func (s *Server) renderScene(scene scenes.Scene, params scenes.Params, layout paper.Layout, seed int64) (resp renderResponse, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("scene '%s' panicked: %v", scene.Name, r)
+		}
+	}()
+	return response(scene.Render(layout.SceneBox(), params, seed), layout)
+}
+
+// response draws each page with its layers followed by their pen-up travel, which the page hides until asked
+func response(doc scenes.Document, layout paper.Layout) (renderResponse, error) {
+	resp := renderResponse{}
+	source, _ := doc.Source()
+	total := time.Duration(0)
+	for i := range doc.NumPages() {
+		layers := doc.Page(i).GetLayers()
+		page := scenes.Page{}
+		for _, layer := range layers {
+			page = page.AddLayer(layer)
+		}
+		pageResp := pageResponse{}
+		for j, layer := range layers {
+			offset := layer.Offset()
+			page = page.AddLayer(scenes.NewLayer("travel "+layer.Name()).
+				WithLineLike(lines.SegmentsToLineLikes(layer.TravelLines())).
+				WithColor("#888888").WithWidth(4).WithOffset(offset.X, offset.Y))
+			stats := layer.Statistics(motion.DefaultModel()).Statistics
+			estimate := stats.Time()
+			total += estimate
+			color := layer.Color()
+			if color == "" {
+				color = "black"
+			}
+			pageResp.Layers = append(pageResp.Layers, layerResponse{
+				Name:            layer.Name(),
+				Color:           color,
+				Group:           j,
+				Travel:          len(layers) + j,
+				Curves:          stats.Curves,
+				Down:            stats.Down,
+				Up:              stats.Up,
+				Estimate:        estimate.Round(time.Second).String(),
+				EstimateSeconds: estimate.Seconds(),
+			})
+		}
+		b := &bytes.Buffer{}
+		if err := (svg.SVG{Paper: layout, Document: doc}).Write(b, source.Scene+".svg", page); err != nil {
+			return renderResponse{}, err
+		}
+		pageResp.SVG = b.String()
+		resp.Pages = append(resp.Pages, pageResp)
+	}
+	resp.Estimate = total.Round(time.Second).String()
+	resp.EstimateSeconds = total.Seconds()
+	return resp, nil
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
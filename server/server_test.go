@@ -0,0 +1,121 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/libeks/go-plotter-svg/scenes"
+)
+
+func TestRender(t *testing.T) {
+	library := scenes.GatherScenes()
+	srv := httptest.NewServer(New(library.Scenes()).Handler())
+	defer srv.Close()
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{
+			name:       "defaults",
+			body:       `{"scene": "circles-in-square"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "parameters and paper",
+			body:       `{"scene": "truchet", "seed": 3, "params": {"n": "6"}, "paper": "a4", "orientation": "portrait"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "unknown scene",
+			body:       `{"scene": "nope"}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "unknown scene 'nope'",
+		},
+		{
+			name:       "bad parameter",
+			body:       `{"scene": "truchet", "params": {"n": "many"}}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "parameter 'n' must be an integer",
+		},
+		{
+			name:       "bad paper",
+			body:       `{"scene": "truchet", "paper": "napkin"}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "napkin",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(srv.URL+"/api/render", "application/json", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatalf("Post() returned error %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("Got status %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				b, err := io.ReadAll(resp.Body)
+				if err != nil {
+					t.Fatalf("Reading the body returned error %v", err)
+				}
+				if !strings.Contains(string(b), tt.wantError) {
+					t.Errorf("Got error %q, want it to contain %q", b, tt.wantError)
+				}
+				return
+			}
+			got := renderResponse{}
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatalf("Decoding the response returned error %v", err)
+			}
+			if got.Seed == 0 || len(got.Pages) == 0 {
+				t.Fatalf("Got seed %d and %d pages, want a seed and pages", got.Seed, len(got.Pages))
+			}
+			for _, page := range got.Pages {
+				if !strings.Contains(page.SVG, "<svg") {
+					t.Errorf("Page isn't an SVG: %.100s", page.SVG)
+				}
+				// each layer is followed by its travel, after all of the layers
+				if n := strings.Count(page.SVG, "<g "); n != 2*len(page.Layers) {
+					t.Errorf("Got %d groups for %d layers", n, len(page.Layers))
+				}
+			}
+		})
+	}
+}
+
+func TestScenes(t *testing.T) {
+	library := scenes.GatherScenes()
+	srv := httptest.NewServer(New(library.Scenes()).Handler())
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + "/api/scenes")
+	if err != nil {
+		t.Fatalf("Get() returned error %v", err)
+	}
+	defer resp.Body.Close()
+	got := libraryJSON{}
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("Decoding the response returned error %v", err)
+	}
+	for _, scene := range got.Scenes {
+		if scene.Name == "circles-in-square" {
+			want := []paramJSON{
+				{Name: "spacing", Type: "float", Default: 100.0, Min: 10, Max: 5000, Description: "distance between consecutive circles"},
+				{Name: "color", Type: "string", Default: "red", Description: "color of the circles"},
+			}
+			if diff := cmp.Diff(want, scene.Params); diff != "" {
+				t.Errorf("Unexpected diff %v", diff)
+			}
+			return
+		}
+	}
+	t.Errorf("circles-in-square is missing from %v", got.Scenes)
+}
@@ -0,0 +1,152 @@
+package split
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/libeks/go-plotter-svg/gcode"
+	"github.com/libeks/go-plotter-svg/paper"
+	"github.com/libeks/go-plotter-svg/scenes"
+	"github.com/libeks/go-plotter-svg/svg"
+)
+
+const (
+	FormatSVG   = "svg"
+	FormatGCode = "gcode"
+)
+
+// Split writes one file per pen, so that a single-pen plotter can draw the document in several passes
+type Split struct {
+	Fname  string // files are named after it, e.g. gallery/test.svg gives gallery/test_1-fill-micron-01.svg
+	Format string // FormatSVG or FormatGCode
+	Paper  paper.Layout
+	GCode  gcode.Config
+	scenes.Document
+}
+
+// Entry describes a single pass in the manifest
+type Entry struct {
+	Order           int      `json:"order"`
+	Page            int      `json:"page"`
+	File            string   `json:"file"`
+	Pen             string   `json:"pen"`
+	Color           string   `json:"color,omitempty"`
+	Layers          []string `json:"layers"`
+	Estimate        string   `json:"estimate"`
+	EstimateSeconds float64  `json:"estimate_seconds"`
+}
+
+// Manifest lists the files in the order in which they should be plotted
+type Manifest struct {
+	Files        []Entry `json:"files"`
+	Total        string  `json:"total"`
+	TotalSeconds float64 `json:"total_seconds"`
+}
+
+// WriteSplit writes the file for each pen of each page, followed by the manifest, which is named after Fname
+// with a _manifest.json suffix
+func (s Split) WriteSplit() (Manifest, error) {
+	if s.Format != FormatSVG && s.Format != FormatGCode {
+		return Manifest{}, fmt.Errorf("unknown split format '%s', expected %s or %s", s.Format, FormatSVG, FormatGCode)
+	}
+	extension := filepath.Ext(s.Fname)
+	basename := strings.TrimSuffix(s.Fname, extension)
+	manifest := Manifest{Files: []Entry{}}
+	total := time.Duration(0)
+	for i := range s.Document.NumPages() {
+		prefix := basename
+		if s.Document.NumPages() > 1 {
+			prefix = fmt.Sprintf("%s_%d", basename, i)
+		}
+		for j, group := range s.Document.Page(i).SplitByPen() {
+			fname := fmt.Sprintf("%s_%d-%s%s", prefix, j+1, groupSlug(group), extension)
+			if err := s.writeGroup(fname, group.Page); err != nil {
+				return Manifest{}, err
+			}
+			estimate := group.TimeEstimate()
+			total += estimate
+			layers := []string{}
+			for _, layer := range group.Page.GetLayers() {
+				layers = append(layers, layer.Name())
+			}
+			manifest.Files = append(manifest.Files, Entry{
+				Order:           len(manifest.Files) + 1,
+				Page:            i,
+				File:            fname,
+				Pen:             group.Name,
+				Color:           group.Color,
+				Layers:          layers,
+				Estimate:        estimate.Round(time.Second).String(),
+				EstimateSeconds: estimate.Seconds(),
+			})
+		}
+	}
+	manifest.Total = total.Round(time.Second).String()
+	manifest.TotalSeconds = total.Seconds()
+	if err := writeManifest(basename+"_manifest.json", manifest); err != nil {
+		return Manifest{}, err
+	}
+	return manifest, nil
+}
+
+func (s Split) writeGroup(fname string, page scenes.Page) error {
+	if s.Format == FormatSVG {
+		return svg.SVG{Fname: fname, Paper: s.Paper}.WritePage(fname, page)
+	}
+	fmt.Printf("trying to open file %s\n", fname)
+	f, err := os.OpenFile(fname, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := (gcode.GCode{Fname: fname, Config: s.GCode}).WritePage(f, page); err != nil {
+		return err
+	}
+	fmt.Printf("Finished rendering to file %s\n", fname)
+	return nil
+}
+
+func writeManifest(fname string, manifest Manifest) error {
+	fmt.Printf("trying to open file %s\n", fname)
+	f, err := os.OpenFile(fname, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		return err
+	}
+	fmt.Printf("Finished writing to file %s\n", fname)
+	return nil
+}
+
+// groupSlug names the file of a group after its first layer and its pen, unless the layer is already named
+// after the pen
+func groupSlug(group scenes.PenGroup) string {
+	pen := slug(group.Name)
+	if len(group.Layers) == 0 {
+		return pen
+	}
+	layer := slug(group.Layers[0])
+	if strings.Contains(layer, pen) {
+		return layer
+	}
+	return layer + "-" + pen
+}
+
+// slug turns a pen name such as "Uni-ball eco 'Japan' pen" into something that fits in a file name
+func slug(name string) string {
+	parts := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+	if len(parts) == 0 {
+		return "pen"
+	}
+	return strings.Join(parts, "-")
+}
@@ -0,0 +1,108 @@
+package split
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/libeks/go-plotter-svg/gcode"
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/paper"
+	"github.com/libeks/go-plotter-svg/pen"
+	"github.com/libeks/go-plotter-svg/primitives"
+	"github.com/libeks/go-plotter-svg/scenes"
+)
+
+func testDocument() scenes.Document {
+	line := []lines.LineLike{lines.LineSegment{P1: primitives.Point{X: 1000, Y: 1000}, P2: primitives.Point{X: 5000, Y: 1000}}}
+	return scenes.Document{}.
+		AddPage(scenes.Page{}.
+			AddLayer(scenes.NewLayer("fill").WithLineLike(line).WithPen(pen.Micron01)).
+			AddLayer(scenes.NewLayer("outline").WithLineLike(line).WithColor("red")).
+			AddLayer(scenes.NewLayer("fill-2").WithLineLike(line).WithPen(pen.Micron01))).
+		AddPage(scenes.Page{}.
+			AddLayer(scenes.NewLayer("frame").WithLineLike(line)))
+}
+
+func TestWriteSplit(t *testing.T) {
+	dir := t.TempDir()
+	got, err := Split{Fname: filepath.Join(dir, "test.svg"), Format: FormatSVG, Paper: paper.Default(), Document: testDocument()}.WriteSplit()
+	if err != nil {
+		t.Fatalf("WriteSplit() returned error %v", err)
+	}
+	want := Manifest{Files: []Entry{
+		{Order: 1, Page: 0, File: filepath.Join(dir, "test_0_1-fill-micron-01.svg"), Pen: "Micron 01", Layers: []string{"fill", "fill-2"}},
+		{Order: 2, Page: 0, File: filepath.Join(dir, "test_0_2-outline-red.svg"), Pen: "red", Color: "red", Layers: []string{"outline"}},
+		{Order: 3, Page: 1, File: filepath.Join(dir, "test_1_1-frame-black.svg"), Pen: "black", Layers: []string{"frame"}},
+	}}
+	ignoreEstimates := cmpopts.IgnoreFields(Entry{}, "Estimate", "EstimateSeconds")
+	if diff := cmp.Diff(want, got, ignoreEstimates, cmpopts.IgnoreFields(Manifest{}, "Total", "TotalSeconds")); diff != "" {
+		t.Errorf("Unexpected diff %v", diff)
+	}
+	total := 0.0
+	for _, entry := range got.Files {
+		if entry.EstimateSeconds <= 0 {
+			t.Errorf("%s has an estimate of %fs", entry.File, entry.EstimateSeconds)
+		}
+		total += entry.EstimateSeconds
+		if _, err := os.Stat(entry.File); err != nil {
+			t.Errorf("%s wasn't written: %v", entry.File, err)
+		}
+	}
+	if math.Abs(got.TotalSeconds-total) > 1e-9 {
+		t.Errorf("Total is %fs, want the sum of the passes, %fs", got.TotalSeconds, total)
+	}
+
+	// the manifest on disk is the one that was returned
+	b, err := os.ReadFile(filepath.Join(dir, "test_manifest.json"))
+	if err != nil {
+		t.Fatalf("Reading the manifest returned error %v", err)
+	}
+	written := Manifest{}
+	if err := json.Unmarshal(b, &written); err != nil {
+		t.Fatalf("Decoding the manifest returned error %v", err)
+	}
+	if diff := cmp.Diff(got, written); diff != "" {
+		t.Errorf("Unexpected diff in the manifest %v", diff)
+	}
+}
+
+func TestWriteSplitGCode(t *testing.T) {
+	dir := t.TempDir()
+	doc := scenes.Document{}.AddPage(testDocument().Page(0))
+	manifest, err := Split{Fname: filepath.Join(dir, "test.gcode"), Format: FormatGCode, GCode: gcode.DefaultConfig(), Document: doc}.WriteSplit()
+	if err != nil {
+		t.Fatalf("WriteSplit() returned error %v", err)
+	}
+	// a single page doesn't get a page number
+	files := []string{}
+	for _, entry := range manifest.Files {
+		files = append(files, filepath.Base(entry.File))
+		b, err := os.ReadFile(entry.File)
+		if err != nil {
+			t.Fatalf("Reading %s returned error %v", entry.File, err)
+		}
+		if !strings.HasPrefix(string(b), "; generated by go-plotter-svg\n") {
+			t.Errorf("%s isn't G-code:\n%s", entry.File, b)
+		}
+	}
+	if diff := cmp.Diff([]string{"test_1-fill-micron-01.gcode", "test_2-outline-red.gcode"}, files); diff != "" {
+		t.Errorf("Unexpected diff %v", diff)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "test_manifest.json")); err != nil {
+		t.Errorf("The manifest wasn't written: %v", err)
+	}
+}
+
+func TestUnknownFormat(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "test.hpgl")
+	if _, err := (Split{Fname: fname, Format: "hpgl", Document: testDocument()}).WriteSplit(); err == nil {
+		t.Errorf("WriteSplit() returned no error for an unknown format")
+	}
+}
@@ -0,0 +1,107 @@
+package svg
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.shabbyrobe.org/xmlwriter"
+
+	"github.com/libeks/go-plotter-svg/paper"
+	"github.com/libeks/go-plotter-svg/scenes"
+	"github.com/libeks/go-plotter-svg/units"
+)
+
+// id of the <metadata> element that holds the Metadata
+const metadataID = "go-plotter-svg"
+
+// Metadata is embedded in the SVG of a generated document, with everything needed to generate it again
+type Metadata struct {
+	scenes.Source
+	Paper       string  `json:"paper"`
+	Orientation string  `json:"orientation"`
+	Margin      float64 `json:"margin"` // in internal units
+	Unit        string  `json:"unit"`
+}
+
+// Layout is the paper that the document was drawn on
+func (m Metadata) Layout() (paper.Layout, error) {
+	size, err := paper.Lookup(m.Paper)
+	if err != nil {
+		return paper.Layout{}, err
+	}
+	orientation, err := paper.ParseOrientation(m.Orientation)
+	if err != nil {
+		return paper.Layout{}, err
+	}
+	unit, err := units.ParseUnit(m.Unit)
+	if err != nil {
+		return paper.Layout{}, err
+	}
+	return paper.Layout{Size: size, Orientation: orientation, Margin: m.Margin, Unit: unit}, nil
+}
+
+func (s SVG) metadata(source scenes.Source) (xmlwriter.Elem, error) {
+	b, err := json.Marshal(Metadata{
+		Source:      source,
+		Paper:       s.Paper.Size.Name,
+		Orientation: s.Paper.Orientation.String(),
+		Margin:      s.Paper.Margin,
+		Unit:        s.Paper.Unit.Name,
+	})
+	if err != nil {
+		return xmlwriter.Elem{}, err
+	}
+	return xmlwriter.Elem{
+		Name: "metadata", Attrs: []xmlwriter.Attr{
+			{Name: "id", Value: metadataID},
+		},
+		Content: []xmlwriter.Writable{xmlwriter.Text(b)},
+	}, nil
+}
+
+// ReadMetadata reads the Metadata from an SVG written by SVG.WriteSVG
+func ReadMetadata(fname string) (Metadata, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return Metadata{}, err
+	}
+	defer f.Close()
+	decoder := xml.NewDecoder(f)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return Metadata{}, fmt.Errorf("%s has no metadata to generate it again from", fname)
+		}
+		if err != nil {
+			return Metadata{}, fmt.Errorf("reading %s: %w", fname, err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "metadata" || !hasID(start, metadataID) {
+			continue
+		}
+		var elem struct {
+			Text string `xml:",chardata"`
+		}
+		if err := decoder.DecodeElement(&elem, &start); err != nil {
+			return Metadata{}, fmt.Errorf("reading %s: %w", fname, err)
+		}
+		m := Metadata{}
+		if err := json.Unmarshal([]byte(elem.Text), &m); err != nil {
+			return Metadata{}, fmt.Errorf("reading the metadata of %s: %w", fname, err)
+		}
+		return m, nil
+	}
+}
+
+func hasID(start xml.StartElement, id string) bool {
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "id" && attr.Value == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package svg
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/paper"
+	"github.com/libeks/go-plotter-svg/scenes"
+	"github.com/libeks/go-plotter-svg/units"
+)
+
+func TestMetadata(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "test.svg")
+	layout := paper.Layout{Size: paper.A4, Orientation: paper.Portrait, Margin: 250, Unit: units.Millimeters}
+	source := scenes.Source{
+		Scene:  "truchet",
+		Seed:   1234567890123,
+		Params: scenes.Params{"n": 12.0, "tiles": "4-crossing"},
+		Processing: &scenes.Processing{
+			Chain:          2,
+			Simplify:       lines.Simplification{Tolerance: 0.5, MaxDeviation: 1},
+			Optimize:       true,
+			OptimizeBudget: 3 * time.Second,
+		},
+	}
+	doc := scenes.Document{}.
+		AddLayer(scenes.NewLayer("frame").WithLineLike(lines.LinesFromBBox(layout.Drawable()))).
+		WithSource(source)
+	if err := (SVG{Fname: fname, Paper: layout, Document: doc}.WriteSVG()); err != nil {
+		t.Fatalf("WriteSVG() returned error %v", err)
+	}
+
+	got, err := ReadMetadata(fname)
+	if err != nil {
+		t.Fatalf("ReadMetadata() returned error %v", err)
+	}
+	want := Metadata{Source: source, Paper: "a4", Orientation: "portrait", Margin: 250, Unit: "mm"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected diff %v", diff)
+	}
+	gotLayout, err := got.Layout()
+	if err != nil {
+		t.Fatalf("Layout() returned error %v", err)
+	}
+	if diff := cmp.Diff(layout, gotLayout); diff != "" {
+		t.Errorf("Unexpected layout diff %v", diff)
+	}
+}
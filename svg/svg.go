@@ -2,68 +2,81 @@ package svg
 
 import (
 	"fmt"
+	"io"
 	"os"
-	"path/filepath"
-	"strings"
 
 	"go.shabbyrobe.org/xmlwriter"
 
+	"github.com/libeks/go-plotter-svg/paper"
 	"github.com/libeks/go-plotter-svg/scenes"
 )
 
 type SVG struct {
-	Fname  string
-	Width  string
-	Height string
+	Fname string
+	Paper paper.Layout // sets the size of the SVG and its viewBox
 	scenes.Document
 }
 
-func (s SVG) WritePage(fname string, scene scenes.Page) {
+// WritePage writes a page of the document to the file fname
+func (s SVG) WritePage(fname string, scene scenes.Page) error {
 	fmt.Printf("trying to open file %s\n", fname)
 	f, err := os.OpenFile(fname, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
 	if err != nil {
-		fmt.Printf("Got error\n")
-		panic(err)
+		return err
 	}
 	defer f.Close()
+	if err := s.Write(f, fname, scene); err != nil {
+		return err
+	}
+	fmt.Printf("Finished rendering to file %s\n", fname)
+	return nil
+}
+
+// Write writes a page of the document as an SVG, docname is the name Inkscape shows for it
+func (s SVG) Write(f io.Writer, docname string, scene scenes.Page) error {
 	w := xmlwriter.Open(f, xmlwriter.WithIndentString("    "))
-	ec := &xmlwriter.ErrCollector{}
-	defer ec.Panic()
 	layers := []xmlwriter.Writable{}
+	if source, ok := s.Document.Source(); ok {
+		metadata, err := s.metadata(source)
+		if err != nil {
+			return err
+		}
+		layers = append(layers, metadata)
+	}
 	for i, layer := range scene.GetLayers() {
 		layers = append(layers, layer.XML(i))
 	}
-	ec.Do(
-		w.Start(xmlwriter.Doc{}),
-		w.Start(xmlwriter.Elem{
-			Name: "svg", Attrs: []xmlwriter.Attr{
-				{Name: "width", Value: s.Width},
-				{Name: "height", Value: s.Height},
-				{Name: "viewBox", Value: "0 0 13333 10000"}, // ensure the viewbox fits the page size
-				{Name: "version", Value: "1.1"},
-				{Name: "id", Value: "svg6"},
-				{Name: "sodipodi:docname", Value: fname},
-				{Name: "inkscape:version", Value: "1.3.2 (091e20e, 2023-11-25, custom)"},
-				{Name: "xmlns:inkscape", Value: "http://www.inkscape.org/namespaces/inkscape"},
-				{Name: "xmlns:sodipodi", Value: "http://sodipodi.sourceforge.net/DTD/sodipodi-0.dtd"},
-				{Name: "xmlns", Value: "http://www.w3.org/2000/svg"},
-				{Name: "xmlns:svg", Value: "http://www.w3.org/2000/svg"},
-			},
-			Content: layers,
-		}),
-		w.EndAllFlush(),
-	)
-	fmt.Printf("Finished rendering to file %s\n", fname)
+	if err := w.Start(xmlwriter.Doc{}); err != nil {
+		return err
+	}
+	err := w.Start(xmlwriter.Elem{
+		Name: "svg", Attrs: []xmlwriter.Attr{
+			{Name: "width", Value: s.Paper.SVGWidth()},
+			{Name: "height", Value: s.Paper.SVGHeight()},
+			{Name: "viewBox", Value: s.Paper.ViewBox()}, // ensure the viewbox fits the page size
+			{Name: "version", Value: "1.1"},
+			{Name: "id", Value: "svg6"},
+			{Name: "sodipodi:docname", Value: docname},
+			{Name: "inkscape:version", Value: "1.3.2 (091e20e, 2023-11-25, custom)"},
+			{Name: "xmlns:inkscape", Value: "http://www.inkscape.org/namespaces/inkscape"},
+			{Name: "xmlns:sodipodi", Value: "http://sodipodi.sourceforge.net/DTD/sodipodi-0.dtd"},
+			{Name: "xmlns", Value: "http://www.w3.org/2000/svg"},
+			{Name: "xmlns:svg", Value: "http://www.w3.org/2000/svg"},
+		},
+		Content: layers,
+	})
+	if err != nil {
+		return err
+	}
+	return w.EndAllFlush()
 }
 
-func (s SVG) WriteSVG() {
-	if s.Document.NumPages() == 1 {
-		s.WritePage(s.Fname, s.Document.Page(0))
-	} else {
-		extension := filepath.Ext(s.Fname)
-		basename := strings.TrimSuffix(s.Fname, extension)
-		for i := range s.Document.NumPages() {
-			s.WritePage(fmt.Sprintf("./%s_%d%s", basename, i, extension), s.Document.Page(i))
+// WriteSVG writes each page of the document to its own file, named as in Document.PageFileNames
+func (s SVG) WriteSVG() error {
+	for i, fname := range s.Document.PageFileNames(s.Fname) {
+		if err := s.WritePage(fname, s.Document.Page(i)); err != nil {
+			return err
 		}
 	}
+	return nil
 }
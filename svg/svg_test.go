@@ -0,0 +1,19 @@
+package svg
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/paper"
+	"github.com/libeks/go-plotter-svg/scenes"
+)
+
+func TestWriteSVGError(t *testing.T) {
+	layout := paper.Default()
+	doc := scenes.Document{}.AddLayer(scenes.NewLayer("frame").WithLineLike(lines.LinesFromBBox(layout.Drawable())))
+	fname := filepath.Join(t.TempDir(), "missing", "test.svg")
+	if err := (SVG{Fname: fname, Paper: layout, Document: doc}.WriteSVG()); err == nil {
+		t.Errorf("WriteSVG() to a missing directory returned no error")
+	}
+}
@@ -0,0 +1,388 @@
+package svgimport
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/primitives"
+)
+
+// pathBuilder collects the subpaths of a shape, in the shape's own coordinates
+type pathBuilder struct {
+	subpaths  []Subpath
+	current   lines.Path
+	hasPath   bool
+	start     primitives.Point
+	pos       primitives.Point
+	lastCtrl  primitives.Point // the last control point of the previous curve, for S and T
+	lastCurve byte             // 'C' or 'Q' if the previous command was a curve of that kind
+}
+
+func (b *pathBuilder) flush(closed bool) {
+	if b.hasPath && len(b.current.Chunks()) > 0 {
+		b.subpaths = append(b.subpaths, Subpath{Path: b.current, Closed: closed})
+	}
+	b.hasPath = false
+}
+
+func (b *pathBuilder) moveTo(pt primitives.Point) {
+	b.flush(false)
+	b.current = lines.NewPath(pt)
+	b.hasPath = true
+	b.start = pt
+	b.pos = pt
+	b.lastCurve = 0
+}
+
+func (b *pathBuilder) add(chunk lines.PathChunk) {
+	if !b.hasPath {
+		// drawing after a closepath continues from the start of the closed subpath
+		b.current = lines.NewPath(b.pos)
+		b.hasPath = true
+		b.start = b.pos
+	}
+	b.current = b.current.AddPathChunk(chunk)
+	b.pos = chunk.Endpoint()
+	b.lastCurve = 0
+}
+
+func (b *pathBuilder) lineTo(pt primitives.Point) {
+	if pt == b.pos {
+		b.lastCurve = 0
+		return
+	}
+	b.add(lines.LineChunk{Start: b.pos, End: pt})
+}
+
+func (b *pathBuilder) cubicTo(p1, p2, end primitives.Point) {
+	b.add(lines.CubicBezierChunk{Start: b.pos, P1: p1, P2: p2, End: end})
+	b.lastCtrl = p2
+	b.lastCurve = 'C'
+}
+
+func (b *pathBuilder) quadTo(p1, end primitives.Point) {
+	b.add(lines.QuadraticBezierChunk{Start: b.pos, P1: p1, End: end})
+	b.lastCtrl = p1
+	b.lastCurve = 'Q'
+}
+
+// reflectedCtrl is the implicit first control point of S and T commands
+func (b *pathBuilder) reflectedCtrl(kind byte) primitives.Point {
+	if b.lastCurve != kind {
+		return b.pos
+	}
+	return b.pos.Add(b.pos.Subtract(b.lastCtrl))
+}
+
+// arcTo follows the endpoint to center conversion of the SVG spec, section F.6.5. Circular arcs become
+// circle arc chunks, elliptical ones are approximated with cubic Beziers.
+func (b *pathBuilder) arcTo(rx, ry, rotation float64, large, sweep bool, end primitives.Point) {
+	start := b.pos
+	if start == end {
+		return
+	}
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 {
+		b.lineTo(end)
+		return
+	}
+	phi := rotation * math.Pi / 180
+	cos, sin := math.Cos(phi), math.Sin(phi)
+	dx, dy := (start.X-end.X)/2, (start.Y-end.Y)/2
+	x1 := cos*dx + sin*dy
+	y1 := -sin*dx + cos*dy
+	// scale up radii that are too small to connect the endpoints
+	if lambda := x1*x1/(rx*rx) + y1*y1/(ry*ry); lambda > 1 {
+		rx *= math.Sqrt(lambda)
+		ry *= math.Sqrt(lambda)
+	}
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	coef := math.Sqrt(max(num/den, 0))
+	if large == sweep {
+		coef = -coef
+	}
+	cx1 := coef * rx * y1 / ry
+	cy1 := -coef * ry * x1 / rx
+	center := primitives.Point{
+		X: cos*cx1 - sin*cy1 + (start.X+end.X)/2,
+		Y: sin*cx1 + cos*cy1 + (start.Y+end.Y)/2,
+	}
+	u := primitives.Vector{X: (x1 - cx1) / rx, Y: (y1 - cy1) / ry}
+	v := primitives.Vector{X: (-x1 - cx1) / rx, Y: (-y1 - cy1) / ry}
+	theta := math.Atan2(u.Y, u.X)
+	delta := math.Atan2(u.X*v.Y-u.Y*v.X, u.Dot(v))
+	if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	} else if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	}
+	if math.Abs(rx-ry) <= 1e-9*max(rx, ry) {
+		// the rotation of a circle doesn't matter, but its angles are measured in the rotated frame
+		b.add(lines.CircleArcChunk(center, rx, theta+phi, theta+phi+delta, delta > 0))
+		b.pos = end // avoid drift from the trigonometry
+		return
+	}
+	t := primitives.Translation(primitives.Vector(center)).Multiply(primitives.Rotation(phi))
+	beziers := lines.EllipticalArcToBeziers(t, rx, ry, theta, delta)
+	beziers[len(beziers)-1].End = end
+	for _, bezier := range beziers {
+		b.add(bezier)
+	}
+}
+
+func (b *pathBuilder) closePath() {
+	if b.hasPath {
+		if b.pos.Subtract(b.start).Len() > 1e-9 {
+			b.add(lines.LineChunk{Start: b.pos, End: b.start})
+		}
+		b.flush(true)
+	}
+	b.pos = b.start
+	b.lastCurve = 0
+}
+
+// pathScanner tokenizes SVG path data and point lists
+type pathScanner struct {
+	s   string
+	pos int
+}
+
+func (p *pathScanner) skipSeparators() {
+	for p.pos < len(p.s) {
+		switch p.s[p.pos] {
+		case ' ', '\t', '\n', '\r', ',':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *pathScanner) done() bool {
+	p.skipSeparators()
+	return p.pos >= len(p.s)
+}
+
+// nextIsNumber reports whether a number follows, i.e. whether the previous command is implicitly repeated
+func (p *pathScanner) nextIsNumber() bool {
+	if p.done() {
+		return false
+	}
+	c := p.s[p.pos]
+	return c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9')
+}
+
+func (p *pathScanner) command() (byte, error) {
+	if p.done() {
+		return 0, fmt.Errorf("unexpected end of path data")
+	}
+	c := p.s[p.pos]
+	p.pos++
+	return c, nil
+}
+
+func (p *pathScanner) number() (float64, error) {
+	p.skipSeparators()
+	start := p.pos
+	if p.pos < len(p.s) && (p.s[p.pos] == '-' || p.s[p.pos] == '+') {
+		p.pos++
+	}
+	digits := func() {
+		for p.pos < len(p.s) && p.s[p.pos] >= '0' && p.s[p.pos] <= '9' {
+			p.pos++
+		}
+	}
+	digits()
+	// a second decimal point starts the next number, as in "0.5.5"
+	if p.pos < len(p.s) && p.s[p.pos] == '.' {
+		p.pos++
+		digits()
+	}
+	if p.pos < len(p.s) && (p.s[p.pos] == 'e' || p.s[p.pos] == 'E') {
+		mark := p.pos
+		p.pos++
+		if p.pos < len(p.s) && (p.s[p.pos] == '-' || p.s[p.pos] == '+') {
+			p.pos++
+		}
+		if p.pos < len(p.s) && p.s[p.pos] >= '0' && p.s[p.pos] <= '9' {
+			digits()
+		} else {
+			p.pos = mark
+		}
+	}
+	v, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+	if err != nil {
+		return 0, fmt.Errorf("expected a number at position %d of '%s'", start, p.s)
+	}
+	return v, nil
+}
+
+// flag reads an arc flag, which may be written without a separator, as in "a10 10 0 1120 20"
+func (p *pathScanner) flag() (bool, error) {
+	p.skipSeparators()
+	if p.pos < len(p.s) {
+		switch p.s[p.pos] {
+		case '0':
+			p.pos++
+			return false, nil
+		case '1':
+			p.pos++
+			return true, nil
+		}
+	}
+	return false, fmt.Errorf("expected a flag at position %d of '%s'", p.pos, p.s)
+}
+
+func (p *pathScanner) numbers(n int) ([]float64, error) {
+	ret := make([]float64, n)
+	for i := range n {
+		v, err := p.number()
+		if err != nil {
+			return nil, err
+		}
+		ret[i] = v
+	}
+	return ret, nil
+}
+
+// parsePathData interprets the d attribute of a path element
+func parsePathData(d string) ([]Subpath, error) {
+	b := &pathBuilder{}
+	p := &pathScanner{s: d}
+	var cmd byte
+	for !p.done() {
+		if !p.nextIsNumber() {
+			c, err := p.command()
+			if err != nil {
+				return nil, err
+			}
+			cmd = c
+		} else if cmd == 0 {
+			return nil, fmt.Errorf("path data '%s' must start with a command", d)
+		}
+		relative := cmd >= 'a' && cmd <= 'z'
+		abs := func(x, y float64) primitives.Point {
+			if relative {
+				return primitives.Point{X: b.pos.X + x, Y: b.pos.Y + y}
+			}
+			return primitives.Point{X: x, Y: y}
+		}
+		switch cmd {
+		case 'M', 'm':
+			v, err := p.numbers(2)
+			if err != nil {
+				return nil, err
+			}
+			b.moveTo(abs(v[0], v[1]))
+			// further coordinate pairs are implicit lineto commands
+			if relative {
+				cmd = 'l'
+			} else {
+				cmd = 'L'
+			}
+		case 'L', 'l':
+			v, err := p.numbers(2)
+			if err != nil {
+				return nil, err
+			}
+			b.lineTo(abs(v[0], v[1]))
+		case 'H', 'h':
+			v, err := p.number()
+			if err != nil {
+				return nil, err
+			}
+			if relative {
+				v += b.pos.X
+			}
+			b.lineTo(primitives.Point{X: v, Y: b.pos.Y})
+		case 'V', 'v':
+			v, err := p.number()
+			if err != nil {
+				return nil, err
+			}
+			if relative {
+				v += b.pos.Y
+			}
+			b.lineTo(primitives.Point{X: b.pos.X, Y: v})
+		case 'C', 'c':
+			v, err := p.numbers(6)
+			if err != nil {
+				return nil, err
+			}
+			b.cubicTo(abs(v[0], v[1]), abs(v[2], v[3]), abs(v[4], v[5]))
+		case 'S', 's':
+			v, err := p.numbers(4)
+			if err != nil {
+				return nil, err
+			}
+			b.cubicTo(b.reflectedCtrl('C'), abs(v[0], v[1]), abs(v[2], v[3]))
+		case 'Q', 'q':
+			v, err := p.numbers(4)
+			if err != nil {
+				return nil, err
+			}
+			b.quadTo(abs(v[0], v[1]), abs(v[2], v[3]))
+		case 'T', 't':
+			v, err := p.numbers(2)
+			if err != nil {
+				return nil, err
+			}
+			b.quadTo(b.reflectedCtrl('Q'), abs(v[0], v[1]))
+		case 'A', 'a':
+			v, err := p.numbers(3)
+			if err != nil {
+				return nil, err
+			}
+			large, err := p.flag()
+			if err != nil {
+				return nil, err
+			}
+			sweep, err := p.flag()
+			if err != nil {
+				return nil, err
+			}
+			end, err := p.numbers(2)
+			if err != nil {
+				return nil, err
+			}
+			b.arcTo(v[0], v[1], v[2], large, sweep, abs(end[0], end[1]))
+		case 'Z', 'z':
+			b.closePath()
+			if p.nextIsNumber() {
+				return nil, fmt.Errorf("closepath in '%s' can't be followed by a number", d)
+			}
+		default:
+			return nil, fmt.Errorf("unknown path command '%c' in '%s'", cmd, d)
+		}
+	}
+	b.flush(false)
+	return b.subpaths, nil
+}
+
+// parsePoints interprets the points attribute of polyline and polygon elements
+func parsePoints(s string, closed bool) ([]Subpath, error) {
+	b := &pathBuilder{}
+	p := &pathScanner{s: s}
+	first := true
+	for !p.done() {
+		v, err := p.numbers(2)
+		if err != nil {
+			return nil, err
+		}
+		pt := primitives.Point{X: v[0], Y: v[1]}
+		if first {
+			b.moveTo(pt)
+			first = false
+		} else {
+			b.lineTo(pt)
+		}
+	}
+	if closed {
+		b.closePath()
+	}
+	b.flush(false)
+	return b.subpaths, nil
+}
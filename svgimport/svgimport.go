@@ -0,0 +1,370 @@
+package svgimport
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/objects"
+	"github.com/libeks/go-plotter-svg/primitives"
+)
+
+// elements whose contents are never rendered directly
+var skippedElements = map[string]bool{
+	"defs":     true,
+	"clipPath": true,
+	"mask":     true,
+	"marker":   true,
+	"pattern":  true,
+	"symbol":   true,
+	"style":    true,
+	"script":   true,
+	"title":    true,
+	"desc":     true,
+	"metadata": true,
+	"text":     true,
+}
+
+// Subpath is a single continuous stroke of a shape
+type Subpath struct {
+	Path   lines.Path
+	Closed bool
+}
+
+// Shape is a single SVG element, with its transform already applied
+type Shape struct {
+	ID       string
+	Element  string // name of the SVG element, e.g. "path" or "rect"
+	Subpaths []Subpath
+	// circle is set for circle elements whose transform keeps them circular, so that they can become an objects.Circle
+	circle *objects.Circle
+}
+
+// LineLikes returns every subpath of the shape, ready to be added to a Layer
+func (s Shape) LineLikes() []lines.LineLike {
+	ret := []lines.LineLike{}
+	for _, subpath := range s.Subpaths {
+		ret = append(ret, subpath.Path)
+	}
+	return ret
+}
+
+// Polygons flattens each closed subpath into a polygon, such that the outline deviates by at most tolerance.
+// Subpaths that aren't closed are ignored.
+func (s Shape) Polygons(tolerance float64) []objects.Polygon {
+	polygons := []objects.Polygon{}
+	for _, subpath := range s.Subpaths {
+		if !subpath.Closed {
+			continue
+		}
+		points := lines.Flatten(subpath.Path, tolerance)
+		// the last point repeats the first one
+		points = points[:len(points)-1]
+		if len(points) >= 3 {
+			polygons = append(polygons, objects.Polygon{Points: points})
+		}
+	}
+	return polygons
+}
+
+// Polygon returns the outline of a shape with a single closed subpath
+func (s Shape) Polygon(tolerance float64) (objects.Polygon, error) {
+	polygons := s.Polygons(tolerance)
+	if len(polygons) != 1 {
+		return objects.Polygon{}, fmt.Errorf("%s '%s' has %d closed subpaths, expected exactly one", s.Element, s.ID, len(polygons))
+	}
+	return polygons[0], nil
+}
+
+// Object returns the area enclosed by the shape, to be used for clipping and fills. Circles become an
+// objects.Circle, everything else is flattened into polygons. Subpaths that lie inside an odd number of other
+// subpaths are treated as holes, as with the even-odd fill rule.
+func (s Shape) Object(tolerance float64) (objects.Object, error) {
+	if s.circle != nil {
+		return *s.circle, nil
+	}
+	polygons := s.Polygons(tolerance)
+	if len(polygons) == 0 {
+		return nil, fmt.Errorf("%s '%s' has no closed subpaths", s.Element, s.ID)
+	}
+	if len(polygons) == 1 {
+		return polygons[0], nil
+	}
+	composite := objects.NewComposite()
+	for i, polygon := range polygons {
+		depth := 0
+		for j, other := range polygons {
+			if i != j && other.Inside(polygon.Points[0]) {
+				depth += 1
+			}
+		}
+		if depth%2 == 0 {
+			composite = composite.With(polygon)
+		} else {
+			composite = composite.Without(polygon)
+		}
+	}
+	return composite, nil
+}
+
+// BBox is the bounding box of the shape, estimated from its flattened outline
+func (s Shape) BBox() primitives.BBox {
+	return bboxOf([]Shape{s})
+}
+
+func bboxOf(shapes []Shape) primitives.BBox {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, shape := range shapes {
+		for _, subpath := range shape.Subpaths {
+			for _, pt := range lines.Flatten(subpath.Path, subpath.Path.Len()/1000+1e-6) {
+				minX, minY = min(minX, pt.X), min(minY, pt.Y)
+				maxX, maxY = max(maxX, pt.X), max(maxY, pt.Y)
+			}
+		}
+	}
+	return primitives.BBox{
+		UpperLeft:  primitives.Point{X: minX, Y: minY},
+		LowerRight: primitives.Point{X: maxX, Y: maxY},
+	}
+}
+
+// Transform applies a transformation to the shape
+func (s Shape) Transform(t primitives.Transform) Shape {
+	subpaths := make([]Subpath, len(s.Subpaths))
+	for i, subpath := range s.Subpaths {
+		subpaths[i] = Subpath{Path: subpath.Path.Transform(t), Closed: subpath.Closed}
+	}
+	s.Subpaths = subpaths
+	if s.circle != nil {
+		if t.IsSimilarity() {
+			s.circle = &objects.Circle{Center: t.Apply(s.circle.Center), Radius: s.circle.Radius * t.Scale()}
+		} else {
+			s.circle = nil
+		}
+	}
+	return s
+}
+
+// FitInto scales and translates the shapes uniformly, so that together they're centered inside the box
+func FitInto(shapes []Shape, b primitives.BBox) []Shape {
+	bbox := bboxOf(shapes)
+	width, height := bbox.LowerRight.X-bbox.UpperLeft.X, bbox.LowerRight.Y-bbox.UpperLeft.Y
+	if len(shapes) == 0 || width <= 0 && height <= 0 {
+		return shapes
+	}
+	scale := math.Inf(1)
+	if width > 0 {
+		scale = (b.LowerRight.X - b.UpperLeft.X) / width
+	}
+	if height > 0 {
+		scale = min(scale, (b.LowerRight.Y-b.UpperLeft.Y)/height)
+	}
+	t := primitives.Translation(b.Center().Subtract(primitives.Origin)).
+		Multiply(primitives.Scaling(scale, scale)).
+		Multiply(primitives.Translation(primitives.Origin.Subtract(bbox.Center())))
+	ret := make([]Shape, len(shapes))
+	for i, shape := range shapes {
+		ret[i] = shape.Transform(t)
+	}
+	return ret
+}
+
+// ParseFile reads all the shapes from an SVG file
+func ParseFile(fname string) ([]Shape, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	shapes, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("could not import %s: %w", fname, err)
+	}
+	return shapes, nil
+}
+
+// Parse reads the path, line, polyline, polygon, rect, circle and ellipse elements of an SVG document, in
+// document order. Coordinates are in the SVG's user units, with all transforms applied.
+func Parse(r io.Reader) ([]Shape, error) {
+	decoder := xml.NewDecoder(r)
+	shapes := []Shape{}
+	transforms := []primitives.Transform{primitives.Identity}
+	skipDepth := 0
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return shapes, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch el := token.(type) {
+		case xml.StartElement:
+			if skipDepth > 0 || skippedElements[el.Name.Local] {
+				skipDepth += 1
+				continue
+			}
+			attrs := map[string]string{}
+			for _, attr := range el.Attr {
+				attrs[attr.Name.Local] = attr.Value
+			}
+			t := transforms[len(transforms)-1]
+			if value, ok := attrs["transform"]; ok {
+				local, err := parseTransform(value)
+				if err != nil {
+					return nil, err
+				}
+				t = t.Multiply(local)
+			}
+			transforms = append(transforms, t)
+			shape, ok, err := parseElement(el.Name.Local, attrs)
+			if err != nil {
+				return nil, fmt.Errorf("%s '%s': %w", el.Name.Local, attrs["id"], err)
+			}
+			if ok && len(shape.Subpaths) > 0 {
+				shapes = append(shapes, shape.Transform(t))
+			}
+		case xml.EndElement:
+			if skipDepth > 0 {
+				skipDepth -= 1
+				continue
+			}
+			transforms = transforms[:len(transforms)-1]
+		}
+	}
+}
+
+// parseElement converts a single element to a shape in its own coordinates. Elements that aren't shapes
+// return false.
+func parseElement(name string, attrs map[string]string) (Shape, bool, error) {
+	shape := Shape{ID: attrs["id"], Element: name}
+	var err error
+	switch name {
+	case "path":
+		shape.Subpaths, err = parsePathData(attrs["d"])
+	case "polyline":
+		shape.Subpaths, err = parsePoints(attrs["points"], false)
+	case "polygon":
+		shape.Subpaths, err = parsePoints(attrs["points"], true)
+	case "line":
+		var v []float64
+		v, err = lengths(attrs, "x1", "y1", "x2", "y2")
+		if err == nil {
+			b := &pathBuilder{}
+			b.moveTo(primitives.Point{X: v[0], Y: v[1]})
+			b.lineTo(primitives.Point{X: v[2], Y: v[3]})
+			b.flush(false)
+			shape.Subpaths = b.subpaths
+		}
+	case "rect":
+		shape.Subpaths, err = parseRect(attrs)
+	case "circle":
+		var v []float64
+		v, err = lengths(attrs, "cx", "cy", "r")
+		if err == nil && v[2] > 0 {
+			shape.Subpaths = ellipse(v[0], v[1], v[2], v[2])
+			shape.circle = &objects.Circle{Center: primitives.Point{X: v[0], Y: v[1]}, Radius: v[2]}
+		}
+	case "ellipse":
+		var v []float64
+		v, err = lengths(attrs, "cx", "cy", "rx", "ry")
+		if err == nil && v[2] > 0 && v[3] > 0 {
+			shape.Subpaths = ellipse(v[0], v[1], v[2], v[3])
+		}
+	default:
+		return shape, false, nil
+	}
+	return shape, true, err
+}
+
+func ellipse(cx, cy, rx, ry float64) []Subpath {
+	b := &pathBuilder{}
+	b.moveTo(primitives.Point{X: cx + rx, Y: cy})
+	b.arcTo(rx, ry, 0, false, true, primitives.Point{X: cx - rx, Y: cy})
+	b.arcTo(rx, ry, 0, false, true, primitives.Point{X: cx + rx, Y: cy})
+	b.closePath()
+	return b.subpaths
+}
+
+func parseRect(attrs map[string]string) ([]Subpath, error) {
+	v, err := lengths(attrs, "x", "y", "width", "height", "rx", "ry")
+	if err != nil {
+		return nil, err
+	}
+	x, y, w, h, rx, ry := v[0], v[1], v[2], v[3], v[4], v[5]
+	if w <= 0 || h <= 0 {
+		return nil, nil
+	}
+	// a missing radius takes the value of the other one
+	if _, ok := attrs["rx"]; !ok {
+		rx = ry
+	}
+	if _, ok := attrs["ry"]; !ok {
+		ry = rx
+	}
+	rx, ry = min(rx, w/2), min(ry, h/2)
+	b := &pathBuilder{}
+	b.moveTo(primitives.Point{X: x + rx, Y: y})
+	b.lineTo(primitives.Point{X: x + w - rx, Y: y})
+	b.arcTo(rx, ry, 0, false, true, primitives.Point{X: x + w, Y: y + ry})
+	b.lineTo(primitives.Point{X: x + w, Y: y + h - ry})
+	b.arcTo(rx, ry, 0, false, true, primitives.Point{X: x + w - rx, Y: y + h})
+	b.lineTo(primitives.Point{X: x + rx, Y: y + h})
+	b.arcTo(rx, ry, 0, false, true, primitives.Point{X: x, Y: y + h - ry})
+	b.lineTo(primitives.Point{X: x, Y: y + ry})
+	b.arcTo(rx, ry, 0, false, true, primitives.Point{X: x + rx, Y: y})
+	b.closePath()
+	return b.subpaths, nil
+}
+
+// CSS units in user units (pixels), per the SVG spec
+var unitSizes = map[string]float64{
+	"":   1,
+	"px": 1,
+	"in": 96,
+	"cm": 96 / 2.54,
+	"mm": 96 / 25.4,
+	"pt": 96.0 / 72,
+	"pc": 16,
+}
+
+// lengths parses the named attributes, where missing attributes are zero
+func lengths(attrs map[string]string, names ...string) ([]float64, error) {
+	ret := make([]float64, len(names))
+	for i, name := range names {
+		value, ok := attrs[name]
+		if !ok {
+			continue
+		}
+		v, err := parseLength(value)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %s: %w", name, err)
+		}
+		ret[i] = v
+	}
+	return ret, nil
+}
+
+func parseLength(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	i := len(s)
+	for i > 0 && (s[i-1] >= 'a' && s[i-1] <= 'z' || s[i-1] == '%') {
+		i--
+	}
+	unit, ok := unitSizes[s[i:]]
+	if !ok {
+		return 0, fmt.Errorf("unsupported unit in length '%s'", s)
+	}
+	v, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid length '%s'", s)
+	}
+	return v * unit, nil
+}
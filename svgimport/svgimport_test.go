@@ -0,0 +1,199 @@
+package svgimport
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/libeks/go-plotter-svg/lines"
+	"github.com/libeks/go-plotter-svg/objects"
+	"github.com/libeks/go-plotter-svg/primitives"
+)
+
+func round(points []primitives.Point) []primitives.Point {
+	ret := make([]primitives.Point, len(points))
+	for i, pt := range points {
+		ret[i] = primitives.Point{X: math.Round(pt.X*1000) / 1000, Y: math.Round(pt.Y*1000) / 1000}
+	}
+	return ret
+}
+
+func TestParsePolygons(t *testing.T) {
+	square := []primitives.Point{{X: 10, Y: 10}, {X: 20, Y: 10}, {X: 20, Y: 20}, {X: 10, Y: 20}}
+	tests := []struct {
+		name string
+		svg  string
+		want []primitives.Point
+	}{
+		{
+			name: "absolute path",
+			svg:  `<svg><path d="M10 10 L20 10 L20 20 L10 20 Z"/></svg>`,
+			want: square,
+		},
+		{
+			name: "relative path",
+			svg:  `<svg><path d="m10,10h10v10h-10z"/></svg>`,
+			want: square,
+		},
+		{
+			name: "implicit lineto and packed numbers",
+			svg:  `<svg><path d="M10-10 10 0 .5.5z"/></svg>`,
+			want: []primitives.Point{{X: 10, Y: -10}, {X: 10, Y: 0}, {X: 0.5, Y: 0.5}},
+		},
+		{
+			name: "polygon",
+			svg:  `<svg><polygon points="10,10 20,10 20,20 10,20"/></svg>`,
+			want: square,
+		},
+		{
+			name: "rect with nested transforms",
+			svg:  `<svg><g transform="translate(10 10)"><rect x="0" y="0" width="20" height="20" transform="scale(0.5)"/></g></svg>`,
+			want: square,
+		},
+		{
+			name: "rotation around a point",
+			svg:  `<svg><rect x="10" y="10" width="10" height="10" transform="rotate(90 15 15)"/></svg>`,
+			want: []primitives.Point{{X: 20, Y: 10}, {X: 20, Y: 20}, {X: 10, Y: 20}, {X: 10, Y: 10}},
+		},
+		{
+			name: "defs are ignored",
+			svg:  `<svg><defs><rect width="5" height="5"/></defs><polygon points="10,10 20,10 20,20 10,20"/></svg>`,
+			want: square,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shapes, err := Parse(strings.NewReader(tt.svg))
+			if err != nil {
+				t.Fatalf("Parse() returned error %v", err)
+			}
+			if len(shapes) != 1 {
+				t.Fatalf("got %d shapes, want 1", len(shapes))
+			}
+			polygon, err := shapes[0].Polygon(0.1)
+			if err != nil {
+				t.Fatalf("Polygon() returned error %v", err)
+			}
+			if diff := cmp.Diff(tt.want, round(polygon.Points)); diff != "" {
+				t.Errorf("Unexpected diff %v", diff)
+			}
+		})
+	}
+}
+
+func TestParseCurves(t *testing.T) {
+	tests := []struct {
+		name       string
+		svg        string
+		wantChunks []string // type of each chunk
+		wantEnd    primitives.Point
+		wantMinY   float64
+	}{
+		{
+			name:       "circular arc",
+			svg:        `<svg><path d="M0 0 A10 10 0 0 1 20 0"/></svg>`,
+			wantChunks: []string{"arc"},
+			wantEnd:    primitives.Point{X: 20, Y: 0},
+			wantMinY:   -10,
+		},
+		{
+			name:       "arc with packed flags",
+			svg:        `<svg><path d="M0 0a10 10 0 0120 0"/></svg>`,
+			wantChunks: []string{"arc"},
+			wantEnd:    primitives.Point{X: 20, Y: 0},
+			wantMinY:   -10,
+		},
+		{
+			name:       "elliptical arc",
+			svg:        `<svg><path d="M0 0 A10 5 0 0 1 20 0"/></svg>`,
+			wantChunks: []string{"cubic", "cubic"},
+			wantEnd:    primitives.Point{X: 20, Y: 0},
+			wantMinY:   -5,
+		},
+		{
+			name:       "smooth curves",
+			svg:        `<svg><path d="M0 0 C0 -10 10 -10 10 0 S20 10 20 0 Q25 -5 30 0 T40 0"/></svg>`,
+			wantChunks: []string{"cubic", "cubic", "quadratic", "quadratic"},
+			wantEnd:    primitives.Point{X: 40, Y: 0},
+			wantMinY:   -7.5,
+		},
+		{
+			name:       "arc under a skew",
+			svg:        `<svg><path d="M0 0 A10 10 0 0 1 20 0" transform="skewX(10)"/></svg>`,
+			wantChunks: []string{"cubic", "cubic"},
+			wantEnd:    primitives.Point{X: 20, Y: 0},
+			wantMinY:   -10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shapes, err := Parse(strings.NewReader(tt.svg))
+			if err != nil {
+				t.Fatalf("Parse() returned error %v", err)
+			}
+			path := shapes[0].Subpaths[0].Path
+			chunks := []string{}
+			for _, chunk := range path.Chunks() {
+				switch chunk.(type) {
+				case lines.ArcChunk:
+					chunks = append(chunks, "arc")
+				case lines.CubicBezierChunk:
+					chunks = append(chunks, "cubic")
+				case lines.QuadraticBezierChunk:
+					chunks = append(chunks, "quadratic")
+				default:
+					chunks = append(chunks, "line")
+				}
+			}
+			if diff := cmp.Diff(tt.wantChunks, chunks); diff != "" {
+				t.Errorf("Unexpected diff in chunks %v", diff)
+			}
+			if diff := cmp.Diff([]primitives.Point{tt.wantEnd}, round([]primitives.Point{path.End()})); diff != "" {
+				t.Errorf("Unexpected diff in endpoint %v", diff)
+			}
+			minY := math.Inf(1)
+			for _, pt := range lines.Flatten(path, 0.001) {
+				minY = min(minY, pt.Y)
+			}
+			if math.Abs(minY-tt.wantMinY) > 0.01 {
+				t.Errorf("got highest point at y=%.3f, want %.3f", minY, tt.wantMinY)
+			}
+		})
+	}
+}
+
+func TestObject(t *testing.T) {
+	svg := `<svg>
+		<circle id="c" cx="10" cy="10" r="5" transform="translate(100 0) scale(2)"/>
+		<path id="donut" d="M0 0 H100 V100 H0 Z M25 25 V75 H75 V25 Z"/>
+	</svg>`
+	shapes, err := Parse(strings.NewReader(svg))
+	if err != nil {
+		t.Fatalf("Parse() returned error %v", err)
+	}
+	circle, err := shapes[0].Object(0.1)
+	if err != nil {
+		t.Fatalf("Object() returned error %v", err)
+	}
+	if diff := cmp.Diff(objects.Circle{Center: primitives.Point{X: 120, Y: 20}, Radius: 10}, circle); diff != "" {
+		t.Errorf("Unexpected diff %v", diff)
+	}
+	donut, err := shapes[1].Object(0.1)
+	if err != nil {
+		t.Fatalf("Object() returned error %v", err)
+	}
+	for _, tt := range []struct {
+		pt     primitives.Point
+		inside bool
+	}{
+		{primitives.Point{X: 10, Y: 10}, true},
+		{primitives.Point{X: 50, Y: 50}, false},
+		{primitives.Point{X: 150, Y: 50}, false},
+	} {
+		if got := donut.Inside(tt.pt); got != tt.inside {
+			t.Errorf("Inside(%s) = %v, want %v", tt.pt, got, tt.inside)
+		}
+	}
+}
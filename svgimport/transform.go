@@ -0,0 +1,60 @@
+package svgimport
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/libeks/go-plotter-svg/primitives"
+)
+
+// parseTransform interprets a transform attribute, e.g. "translate(10 20) rotate(45)"
+func parseTransform(s string) (primitives.Transform, error) {
+	t := primitives.Identity
+	rest := strings.TrimSpace(s)
+	for rest != "" {
+		open := strings.IndexByte(rest, '(')
+		end := strings.IndexByte(rest, ')')
+		if open < 0 || end < open {
+			return t, fmt.Errorf("malformed transform '%s'", s)
+		}
+		name := strings.TrimSpace(rest[:open])
+		p := &pathScanner{s: rest[open+1 : end]}
+		args := []float64{}
+		for !p.done() {
+			v, err := p.number()
+			if err != nil {
+				return t, fmt.Errorf("malformed transform '%s': %w", s, err)
+			}
+			args = append(args, v)
+		}
+		var u primitives.Transform
+		switch {
+		case name == "matrix" && len(args) == 6:
+			u = primitives.Transform{A: args[0], B: args[1], C: args[2], D: args[3], E: args[4], F: args[5]}
+		case name == "translate" && len(args) == 1:
+			u = primitives.Translation(primitives.Vector{X: args[0]})
+		case name == "translate" && len(args) == 2:
+			u = primitives.Translation(primitives.Vector{X: args[0], Y: args[1]})
+		case name == "scale" && len(args) == 1:
+			u = primitives.Scaling(args[0], args[0])
+		case name == "scale" && len(args) == 2:
+			u = primitives.Scaling(args[0], args[1])
+		case name == "rotate" && len(args) == 1:
+			u = primitives.Rotation(args[0] * math.Pi / 180)
+		case name == "rotate" && len(args) == 3:
+			// rotate around the point (cx, cy)
+			center := primitives.Vector{X: args[1], Y: args[2]}
+			u = primitives.Translation(center).Multiply(primitives.Rotation(args[0] * math.Pi / 180)).Multiply(primitives.Translation(center.Mult(-1)))
+		case name == "skewX" && len(args) == 1:
+			u = primitives.SkewX(args[0] * math.Pi / 180)
+		case name == "skewY" && len(args) == 1:
+			u = primitives.SkewY(args[0] * math.Pi / 180)
+		default:
+			return t, fmt.Errorf("unsupported transform '%s' with %d arguments", name, len(args))
+		}
+		t = t.Multiply(u)
+		rest = strings.TrimLeft(rest[end+1:], " \t\n\r,")
+	}
+	return t, nil
+}
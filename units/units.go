@@ -0,0 +1,91 @@
+package units
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The internal coordinate space of a scene is 10000 units tall, spanning the 9" height of the page,
+// so all lengths below are expressed in those internal units.
+const (
+	Inch       = 10000.0 / 9.0
+	Millimeter = Inch / 25.4
+	Meter      = Millimeter * 1000
+)
+
+// ToMillimeters converts a length in internal units to millimeters
+func ToMillimeters(v float64) float64 {
+	return v / Millimeter
+}
+
+// FromMillimeters converts a length in millimeters to internal units
+func FromMillimeters(mm float64) float64 {
+	return mm * Millimeter
+}
+
+// Unit is a unit of length that users can give sizes in, e.g. for the paper or its margins
+type Unit struct {
+	Name string  // the suffix of lengths in this unit, as used in SVG, e.g. "mm"
+	Size float64 // the length of one of this unit, in internal units
+}
+
+var (
+	Millimeters = Unit{"mm", Millimeter}
+	Inches      = Unit{"in", Inch}
+	Pixels      = Unit{"px", Inch / 96} // CSS pixels, as used by Inkscape
+)
+
+// ParseUnit returns the unit with the given name, one of "mm", "in" or "px"
+func ParseUnit(name string) (Unit, error) {
+	for _, u := range []Unit{Millimeters, Inches, Pixels} {
+		if u.Name == name {
+			return u, nil
+		}
+	}
+	return Unit{}, fmt.Errorf("unknown unit '%s', expected one of mm, in or px", name)
+}
+
+// From converts a length in internal units into this unit
+func (u Unit) From(v float64) float64 {
+	return v / u.Size
+}
+
+// To converts a length in this unit into internal units
+func (u Unit) To(v float64) float64 {
+	return v * u.Size
+}
+
+// Format writes a length in internal units in this unit, with its suffix, e.g. "297mm"
+func (u Unit) Format(v float64) string {
+	return FormatNumber(u.From(v)) + u.Name
+}
+
+// FormatNumber writes v with at most two decimals, dropping trailing zeros
+func FormatNumber(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 2, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// ParseLength parses a length such as "10mm", "0.5in" or "20px" into internal units. A number without a
+// suffix is taken to be in the default unit.
+func ParseLength(s string, defaultUnit Unit) (float64, error) {
+	s = strings.TrimSpace(s)
+	unit := defaultUnit
+	for _, u := range []Unit{Millimeters, Inches, Pixels} {
+		if strings.HasSuffix(s, u.Name) {
+			unit = u
+			s = strings.TrimSpace(strings.TrimSuffix(s, u.Name))
+			break
+		}
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid length '%s': %w", s, err)
+	}
+	return unit.To(v), nil
+}
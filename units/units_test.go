@@ -0,0 +1,28 @@
+package units
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseLength(t *testing.T) {
+	tests := []struct {
+		s    string
+		want float64
+	}{
+		{"10mm", 10 * Millimeter},
+		{"0.5in", 0.5 * Inch},
+		{"96px", Inch},
+		{"2", 2 * Millimeter},
+	}
+	for _, tt := range tests {
+		got, err := ParseLength(tt.s, Millimeters)
+		if err != nil {
+			t.Fatalf("ParseLength(%q) returned error %v", tt.s, err)
+		}
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("ParseLength(%q): Unexpected diff %v", tt.s, diff)
+		}
+	}
+}